@@ -0,0 +1,178 @@
+package common
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// DefaultWaitDelay is the delay between two attempts of a Waiter by default
+const DefaultWaitDelay = 5 * time.Second
+
+const waitTimeout = "Timeout"
+
+// Waiter polls a resource until it reaches an awaited state.
+//
+//	waiter := &common.Waiter{
+//		Describe: func(ctx context.Context) (interface{}, error) {
+//			return client.DescribeInstanceAttribute(instanceId)
+//		},
+//		Success: func(result interface{}) bool {
+//			return result.(*ecs.InstanceAttributesType).Status == ecs.Running
+//		},
+//		Retryable: common.IsErrorCodeFunc("InvalidInstanceId.NotFound"),
+//		MaxDelay:  time.Minute,
+//		Jitter:    0.2,
+//	}
+//	result, err := waiter.Wait(ctx)
+type Waiter struct {
+	// Describe returns the current state of the resource
+	Describe func(ctx context.Context) (interface{}, error)
+
+	// Success reports whether the result is the awaited state
+	Success func(result interface{}) bool
+
+	// Failure returns a non-nil error ending the wait when the result is a
+	// terminal state, which can never reach the awaited one
+	Failure func(result interface{}) error
+
+	// Retryable reports whether an error of Describe is transient, such as the
+	// NotFound of a resource being created. Other errors end the wait.
+	Retryable func(err error) bool
+
+	// OnProgress is called after each attempt with the result or the error of Describe
+	OnProgress func(attempt int, result interface{}, err error)
+
+	Delay        time.Duration // Delay after the first attempt, DefaultWaitDelay by default
+	MaxDelay     time.Duration // Maximum delay, the delays are fixed to Delay when not greater
+	Multiplier   float64       // Growth of the delay after each attempt up to MaxDelay, 2 by default
+	Jitter       float64       // Fraction (0-1) of each delay that is randomized
+	InitialDelay time.Duration // Delay before the first attempt
+
+	// Timeout ends the wait with a "Timeout" client error, the wait only ends
+	// with ctx when zero
+	Timeout time.Duration
+}
+
+// NewWaiter returns a Waiter calling describe every interval seconds until
+// success, or until the timeout in seconds
+func NewWaiter(timeout int, interval int, describe func() (interface{}, error), success func(result interface{}) bool) *Waiter {
+	return &Waiter{
+		Describe: func(context.Context) (interface{}, error) {
+			return describe()
+		},
+		Success: success,
+		Delay:   time.Duration(interval) * time.Second,
+		Timeout: time.Duration(timeout) * time.Second,
+	}
+}
+
+// Wait calls Describe until Success holds and returns the last result. It
+// stops at the first failure, non retryable error, timeout or when ctx is done.
+func (w *Waiter) Wait(ctx context.Context) (interface{}, error) {
+	var deadline time.Time
+	if w.Timeout > 0 {
+		deadline = time.Now().Add(w.Timeout)
+	}
+	delay := w.Delay
+	if delay <= 0 {
+		delay = DefaultWaitDelay
+	}
+	multiplier := w.Multiplier
+	if multiplier <= 1 {
+		multiplier = 2
+	}
+
+	if err := w.sleep(ctx, w.InitialDelay, deadline); err != nil {
+		return nil, err
+	}
+	for attempt := 1; ; attempt++ {
+		result, err := w.Describe(ctx)
+		if w.OnProgress != nil {
+			w.OnProgress(attempt, result, err)
+		}
+		if err != nil {
+			if w.Retryable == nil || !w.Retryable(err) {
+				return result, err
+			}
+		} else {
+			if w.Success(result) {
+				return result, nil
+			}
+			if w.Failure != nil {
+				if err := w.Failure(result); err != nil {
+					return result, err
+				}
+			}
+		}
+
+		if err := w.sleep(ctx, w.jitter(delay), deadline); err != nil {
+			return result, err
+		}
+		if w.MaxDelay > delay {
+			delay = time.Duration(float64(delay) * multiplier)
+			if delay > w.MaxDelay {
+				delay = w.MaxDelay
+			}
+		}
+	}
+}
+
+func (w *Waiter) jitter(delay time.Duration) time.Duration {
+	if w.Jitter <= 0 {
+		return delay
+	}
+	jitter := w.Jitter
+	if jitter > 1 {
+		jitter = 1
+	}
+	return delay - time.Duration(rand.Float64()*jitter*float64(delay))
+}
+
+// sleep waits for d, failing at once when the wait would end after the deadline
+func (w *Waiter) sleep(ctx context.Context, d time.Duration, deadline time.Time) error {
+	if !deadline.IsZero() && time.Now().Add(d).After(deadline) {
+		return GetClientErrorFromString(waitTimeout)
+	}
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// IsWaitTimeout reports whether err is the timeout of a Waiter, or a client
+// error whose message starts with "Timeout " to give its details
+func IsWaitTimeout(err error) bool {
+	e, ok := err.(*Error)
+	return ok && e != nil && e.Code == "AliyunGoClientFailure" &&
+		(e.Message == waitTimeout || strings.HasPrefix(e.Message, waitTimeout+" "))
+}
+
+// IsErrorCode reports whether err is an *Error with one of the codes
+func IsErrorCode(err error, codes ...string) bool {
+	e, ok := err.(*Error)
+	if !ok || e == nil {
+		return false
+	}
+	for _, code := range codes {
+		if e.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// IsErrorCodeFunc returns a Waiter.Retryable func retrying the errors with one of the codes
+func IsErrorCodeFunc(codes ...string) func(err error) bool {
+	return func(err error) bool {
+		return IsErrorCode(err, codes...)
+	}
+}
@@ -0,0 +1,91 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWaiter(t *testing.T) {
+	attempts := 0
+	var progress []int
+	waiter := &Waiter{
+		Describe: func(ctx context.Context) (interface{}, error) {
+			attempts++
+			if attempts == 1 {
+				return nil, GetCustomError("InvalidInstanceId.NotFound", "not found")
+			}
+			return attempts, nil
+		},
+		Success: func(result interface{}) bool {
+			return result.(int) == 3
+		},
+		Retryable: IsErrorCodeFunc("InvalidInstanceId.NotFound"),
+		OnProgress: func(attempt int, result interface{}, err error) {
+			progress = append(progress, attempt)
+		},
+		Delay:    time.Millisecond,
+		MaxDelay: 4 * time.Millisecond,
+		Jitter:   0.5,
+	}
+	result, err := waiter.Wait(context.Background())
+	if err != nil || result.(int) != 3 || len(progress) != 3 {
+		t.Errorf("Unexpected wait result: %v %v %v", result, err, progress)
+	}
+
+	// Failures and non retryable errors end the wait
+	waiter.Failure = func(result interface{}) error {
+		return errors.New("failed")
+	}
+	attempts = 1
+	if _, err := waiter.Wait(context.Background()); err == nil || err.Error() != "failed" {
+		t.Errorf("Expected the failure, got %v", err)
+	}
+	waiter.Retryable = nil
+	attempts = 0
+	if _, err := waiter.Wait(context.Background()); !IsErrorCode(err, "InvalidInstanceId.NotFound") {
+		t.Errorf("Expected the describe error, got %v", err)
+	}
+	if IsErrorCode(errors.New("plain error"), "InvalidInstanceId.NotFound") {
+		t.Errorf("Expected a plain error to have no code")
+	}
+
+	never := &Waiter{
+		Describe: func(ctx context.Context) (interface{}, error) {
+			return nil, nil
+		},
+		Success: func(result interface{}) bool {
+			return false
+		},
+		Delay:   10 * time.Millisecond,
+		Timeout: 50 * time.Millisecond,
+	}
+	if _, err := never.Wait(context.Background()); !IsWaitTimeout(err) {
+		t.Errorf("Expected a timeout, got %v", err)
+	}
+	if !IsWaitTimeout(GetClientErrorFromString("Timeout waiting for instances [i-1] to Running")) ||
+		IsWaitTimeout(GetClientErrorFromString("TimeoutError")) {
+		t.Errorf("Unexpected IsWaitTimeout of detailed timeouts")
+	}
+	never.Timeout = 0
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if _, err := never.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Expected the context error, got %v", err)
+	}
+}
+
+func TestNewWaiter(t *testing.T) {
+	waiter := NewWaiter(30, 5, func() (interface{}, error) {
+		return "Running", nil
+	}, func(result interface{}) bool {
+		return result == "Running"
+	})
+	if waiter.Delay != 5*time.Second || waiter.Timeout != 30*time.Second {
+		t.Errorf("Unexpected delay %v and timeout %v", waiter.Delay, waiter.Timeout)
+	}
+	if result, err := waiter.Wait(context.Background()); err != nil || result != "Running" {
+		t.Errorf("Unexpected wait result: %v %v", result, err)
+	}
+}
@@ -1,6 +1,7 @@
 package cs
 
 import (
+	"context"
 	"net/http"
 	"net/url"
 
@@ -565,41 +566,43 @@ func (client *Client) WaitForClusterAsyn(clusterId string, status ClusterState,
 		timeout = ClusterDefaultTimeout
 	}
 
+	waiter := &common.Waiter{
+		Describe: func(context.Context) (interface{}, error) {
+			return client.DescribeCluster(clusterId)
+		},
+		Success: func(result interface{}) bool {
+			return result.(ClusterType).State == status
+		},
+		Failure: func(result interface{}) error {
+			if result.(ClusterType).State == Failed {
+				return fmt.Errorf("Waitting for cluster %s %s failed. Looking the specified reason in the web console.", clusterId, status)
+			}
+			return nil
+		},
+		Delay:   DefaultWaitForInterval * time.Second,
+		Timeout: time.Duration(timeout) * time.Second,
+	}
+
 	// Sleep 20 second to check cluster creating or failed
 	sleep := math.Min(float64(timeout), float64(DefaultPreCheckSleepTime))
 	time.Sleep(time.Duration(sleep) * time.Second)
 
-	cluster, err := client.DescribeCluster(clusterId)
+	cluster, err := waiter.Describe(context.Background())
 	if err != nil {
 		return err
-	} else if cluster.State == Failed {
-		return fmt.Errorf("Waitting for cluster %s %s failed. Looking the specified reason in the web console.", clusterId, status)
-	} else if cluster.State == status {
+	} else if waiter.Success(cluster) {
 		//TODO
 		return nil
+	} else if err := waiter.Failure(cluster); err != nil {
+		return err
 	}
 
 	// Create or Reset cluster usually cost at least 4 min, so there will sleep a long time before polling
 	sleep = math.Min(float64(timeout), float64(DefaultPreSleepTime))
 	time.Sleep(time.Duration(sleep) * time.Second)
 
-	for {
-		cluster, err := client.DescribeCluster(clusterId)
-		if err != nil {
-			return err
-		} else if cluster.State == Failed {
-			return fmt.Errorf("Waitting for cluster %s %s failed. Looking the specified reason in the web console.", clusterId, status)
-		} else if cluster.State == status {
-			//TODO
-			break
-		}
-		timeout = timeout - DefaultWaitForInterval
-		if timeout <= 0 {
-			return common.GetClientErrorFromString("Timeout")
-		}
-		time.Sleep(DefaultWaitForInterval * time.Second)
-	}
-	return nil
+	_, err = waiter.Wait(context.Background())
+	return err
 }
 
 func (client *Client) GetProjectClient(clusterId string) (projectClient *ProjectClient, err error) {
@@ -0,0 +1,295 @@
+package ecs
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/denverdino/aliyungo/common"
+	"github.com/denverdino/aliyungo/util"
+)
+
+type CreateAutoSnapshotPolicyArgs struct {
+	RegionId                     common.Region `ArgName:"regionId"`
+	AutoSnapshotPolicyName       string        `ArgName:"autoSnapshotPolicyName"`
+	TimePoints                   []string      `ArgName:"timePoints"`     // Hours of the day (0-23) of the snapshots, such as ["0", "12"]
+	RepeatWeekdays               []string      `ArgName:"repeatWeekdays"` // Days of the week (1-7) of the snapshots, such as ["1", "4"]
+	RetentionDays                int           `ArgName:"retentionDays"`  // -1 to keep the snapshots forever
+	EnableCrossRegionCopy        bool
+	TargetCopyRegions            []string
+	CopiedSnapshotsRetentionDays int
+	ResourceGroupId              string
+	Tag                          []TagType
+}
+
+type CreateAutoSnapshotPolicyResponse struct {
+	common.Response
+	AutoSnapshotPolicyId string
+}
+
+// CreateAutoSnapshotPolicy creates an automatic snapshot policy
+//
+// You can read doc at https://help.aliyun.com/document_detail/25527.html
+func (client *Client) CreateAutoSnapshotPolicy(args *CreateAutoSnapshotPolicyArgs) (autoSnapshotPolicyId string, err error) {
+	response := CreateAutoSnapshotPolicyResponse{}
+	err = client.Invoke("CreateAutoSnapshotPolicy", args, &response)
+	if err != nil {
+		return "", err
+	}
+	return response.AutoSnapshotPolicyId, nil
+}
+
+type ModifyAutoSnapshotPolicyExArgs struct {
+	RegionId                     common.Region `ArgName:"regionId"`
+	AutoSnapshotPolicyId         string        `ArgName:"autoSnapshotPolicyId"`
+	AutoSnapshotPolicyName       string        `ArgName:"autoSnapshotPolicyName"`
+	TimePoints                   []string      `ArgName:"timePoints"`
+	RepeatWeekdays               []string      `ArgName:"repeatWeekdays"`
+	RetentionDays                int           `ArgName:"retentionDays"`
+	EnableCrossRegionCopy        *bool
+	TargetCopyRegions            []string
+	CopiedSnapshotsRetentionDays int
+}
+
+type ModifyAutoSnapshotPolicyExResponse struct {
+	common.Response
+}
+
+// ModifyAutoSnapshotPolicyEx modifies an automatic snapshot policy
+//
+// You can read doc at https://help.aliyun.com/document_detail/25529.html
+func (client *Client) ModifyAutoSnapshotPolicyEx(args *ModifyAutoSnapshotPolicyExArgs) error {
+	response := ModifyAutoSnapshotPolicyExResponse{}
+	return client.Invoke("ModifyAutoSnapshotPolicyEx", args, &response)
+}
+
+type ApplyAutoSnapshotPolicyArgs struct {
+	RegionId             common.Region `ArgName:"regionId"`
+	AutoSnapshotPolicyId string        `ArgName:"autoSnapshotPolicyId"`
+	DiskIds              []string      `ArgName:"diskIds"`
+}
+
+type ApplyAutoSnapshotPolicyResponse struct {
+	common.Response
+}
+
+// ApplyAutoSnapshotPolicy applies an automatic snapshot policy to disks,
+// replacing the policy previously applied to them
+//
+// You can read doc at https://help.aliyun.com/document_detail/25531.html
+func (client *Client) ApplyAutoSnapshotPolicy(args *ApplyAutoSnapshotPolicyArgs) error {
+	response := ApplyAutoSnapshotPolicyResponse{}
+	return client.Invoke("ApplyAutoSnapshotPolicy", args, &response)
+}
+
+type CancelAutoSnapshotPolicyArgs struct {
+	RegionId common.Region `ArgName:"regionId"`
+	DiskIds  []string      `ArgName:"diskIds"`
+}
+
+type CancelAutoSnapshotPolicyResponse struct {
+	common.Response
+}
+
+// CancelAutoSnapshotPolicy cancels the automatic snapshot policy of disks
+//
+// You can read doc at https://help.aliyun.com/document_detail/25532.html
+func (client *Client) CancelAutoSnapshotPolicy(args *CancelAutoSnapshotPolicyArgs) error {
+	response := CancelAutoSnapshotPolicyResponse{}
+	return client.Invoke("CancelAutoSnapshotPolicy", args, &response)
+}
+
+type DescribeAutoSnapshotPolicyExArgs struct {
+	RegionId             common.Region
+	AutoSnapshotPolicyId string
+	Tag                  []TagType
+	common.Pagination
+}
+
+type AutoSnapshotPolicyType struct {
+	AutoSnapshotPolicyId         string
+	AutoSnapshotPolicyName       string
+	RegionId                     common.Region
+	TimePoints                   string // JSON array of the hours of the snapshots
+	RepeatWeekdays               string // JSON array of the days of the week of the snapshots
+	RetentionDays                int
+	DiskNums                     int
+	Status                       string
+	EnableCrossRegionCopy        bool
+	TargetCopyRegions            string
+	CopiedSnapshotsRetentionDays int
+	ResourceGroupId              string
+	CreationTime                 util.ISO6801Time
+	Tags                         struct {
+		Tag []TagItemType
+	}
+}
+
+type DescribeAutoSnapshotPolicyExResponse struct {
+	common.Response
+	common.PaginationResult
+	AutoSnapshotPolicies struct {
+		AutoSnapshotPolicy []AutoSnapshotPolicyType
+	}
+}
+
+// DescribeAutoSnapshotPolicyEx describes automatic snapshot policies
+//
+// You can read doc at https://help.aliyun.com/document_detail/25530.html
+func (client *Client) DescribeAutoSnapshotPolicyEx(args *DescribeAutoSnapshotPolicyExArgs) (policies []AutoSnapshotPolicyType, pagination *common.PaginationResult, err error) {
+	args.Validate()
+	response := DescribeAutoSnapshotPolicyExResponse{}
+	err = client.Invoke("DescribeAutoSnapshotPolicyEx", args, &response)
+	if err != nil {
+		return nil, nil, err
+	}
+	return response.AutoSnapshotPolicies.AutoSnapshotPolicy, &response.PaginationResult, nil
+}
+
+type DeleteAutoSnapshotPolicyArgs struct {
+	RegionId             common.Region `ArgName:"regionId"`
+	AutoSnapshotPolicyId string        `ArgName:"autoSnapshotPolicyId"`
+}
+
+type DeleteAutoSnapshotPolicyResponse struct {
+	common.Response
+}
+
+// DeleteAutoSnapshotPolicy deletes an automatic snapshot policy, which is cancelled for its disks
+//
+// You can read doc at https://help.aliyun.com/document_detail/25528.html
+func (client *Client) DeleteAutoSnapshotPolicy(regionId common.Region, autoSnapshotPolicyId string) error {
+	args := DeleteAutoSnapshotPolicyArgs{
+		RegionId:             regionId,
+		AutoSnapshotPolicyId: autoSnapshotPolicyId,
+	}
+	response := DeleteAutoSnapshotPolicyResponse{}
+	return client.Invoke("DeleteAutoSnapshotPolicy", &args, &response)
+}
+
+// SnapshotRetentionPolicy selects the snapshots of a disk to keep. A snapshot
+// is kept when any of the rules keeps it, so that the zero policy keeps all
+// the snapshots.
+type SnapshotRetentionPolicy struct {
+	KeepLast   int // Keep the last snapshots
+	KeepDaily  int // Keep the last snapshot of each of the last days with snapshots
+	KeepWeekly int // Keep the last snapshot of each of the last weeks with snapshots
+}
+
+func (policy SnapshotRetentionPolicy) isZero() bool {
+	return policy.KeepLast <= 0 && policy.KeepDaily <= 0 && policy.KeepWeekly <= 0
+}
+
+// Prune returns the snapshots of a disk which are not kept by the policy. The
+// days and weeks are the ones of the creation times in UTC. Only the
+// accomplished snapshots are counted by the rules, the snapshots which are not
+// accomplished, or are used by images or disks, are never returned.
+func (policy SnapshotRetentionPolicy) Prune(snapshots []SnapshotType) []SnapshotType {
+	if policy.isZero() {
+		return nil
+	}
+	sorted := make([]SnapshotType, len(snapshots))
+	copy(sorted, snapshots)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return time.Time(sorted[i].CreationTime).After(time.Time(sorted[j].CreationTime))
+	})
+
+	keep := make(map[string]bool)
+	days := make(map[string]bool)
+	weeks := make(map[string]bool)
+	last := 0
+	for _, snapshot := range sorted {
+		if snapshot.Status != "accomplished" {
+			continue
+		}
+		created := time.Time(snapshot.CreationTime).UTC()
+		if last < policy.KeepLast {
+			last++
+			keep[snapshot.SnapshotId] = true
+		}
+		day := created.Format("2006-01-02")
+		if !days[day] && len(days) < policy.KeepDaily {
+			days[day] = true
+			keep[snapshot.SnapshotId] = true
+		}
+		year, week := created.ISOWeek()
+		weekKey := fmt.Sprintf("%d-%02d", year, week)
+		if !weeks[weekKey] && len(weeks) < policy.KeepWeekly {
+			weeks[weekKey] = true
+			keep[snapshot.SnapshotId] = true
+		}
+	}
+
+	var pruned []SnapshotType
+	for _, snapshot := range sorted {
+		if keep[snapshot.SnapshotId] || snapshot.Status != "accomplished" || (snapshot.Usage != "" && snapshot.Usage != "none") {
+			continue
+		}
+		pruned = append(pruned, snapshot)
+	}
+	return pruned
+}
+
+type PruneSnapshotsArgs struct {
+	RegionId common.Region
+	Tag      []TagType // Prune the snapshots having all the tags, at least one is required
+	Policy   SnapshotRetentionPolicy
+	DryRun   bool // Only return the snapshots to delete
+}
+
+// PruneSnapshots lists the snapshots having the tags, and deletes the ones of
+// each disk which are not kept by the policy. The snapshots without a source
+// disk are left untouched. It returns the deleted snapshots, or the ones to
+// delete with DryRun.
+func (client *Client) PruneSnapshots(args *PruneSnapshotsArgs) (pruned []SnapshotType, err error) {
+	if len(args.Tag) == 0 {
+		return nil, common.GetClientErrorFromString("No tags to select the snapshots to prune")
+	}
+	if args.Policy.isZero() {
+		return nil, nil
+	}
+
+	disks := make(map[string][]SnapshotType)
+	var diskIds []string
+	describeArgs := &DescribeSnapshotsArgs{
+		RegionId: args.RegionId,
+		Tag:      args.Tag,
+		Pagination: common.Pagination{
+			PageNumber: 1,
+			PageSize:   50,
+		},
+	}
+	for {
+		snapshots, pagination, err := client.DescribeSnapshots(describeArgs)
+		if err != nil {
+			return nil, err
+		}
+		for _, snapshot := range snapshots {
+			if snapshot.SourceDiskId == "" {
+				// The source disk is released, there is no disk to keep snapshots for
+				continue
+			}
+			if _, ok := disks[snapshot.SourceDiskId]; !ok {
+				diskIds = append(diskIds, snapshot.SourceDiskId)
+			}
+			disks[snapshot.SourceDiskId] = append(disks[snapshot.SourceDiskId], snapshot)
+		}
+		next := pagination.NextPage()
+		if next == nil {
+			break
+		}
+		describeArgs.Pagination = *next
+	}
+
+	for _, diskId := range diskIds {
+		for _, snapshot := range args.Policy.Prune(disks[diskId]) {
+			if !args.DryRun {
+				if err := client.DeleteSnapshot(snapshot.SnapshotId); err != nil {
+					return pruned, err
+				}
+			}
+			pruned = append(pruned, snapshot)
+		}
+	}
+	return pruned, nil
+}
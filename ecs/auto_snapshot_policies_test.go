@@ -0,0 +1,117 @@
+package ecs
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/denverdino/aliyungo/util"
+)
+
+func TestSnapshotRetentionPolicy(t *testing.T) {
+	now := time.Date(2021, 3, 17, 12, 0, 0, 0, time.UTC) // Wednesday
+	var snapshots []SnapshotType
+	// Two snapshots a day over 21 days, the newest first
+	for i := 0; i < 42; i++ {
+		snapshots = append(snapshots, SnapshotType{
+			SnapshotId:   string(rune('a' + i)),
+			SourceDiskId: "d-1",
+			Status:       "accomplished",
+			Usage:        "none",
+			CreationTime: util.NewISO6801Time(now.Add(-time.Duration(i) * 12 * time.Hour)),
+		})
+	}
+	snapshots[41].Usage = "image"
+
+	if pruned := (SnapshotRetentionPolicy{}).Prune(snapshots); len(pruned) != 0 {
+		t.Errorf("The zero policy pruned %d snapshots", len(pruned))
+	}
+
+	pruned := SnapshotRetentionPolicy{KeepLast: 3}.Prune(snapshots)
+	if len(pruned) != 38 || pruned[0].SnapshotId != snapshots[3].SnapshotId {
+		t.Errorf("Unexpected pruned snapshots with KeepLast: %d", len(pruned))
+	}
+
+	pruned = SnapshotRetentionPolicy{KeepLast: 1, KeepDaily: 7, KeepWeekly: 3}.Prune(snapshots)
+	kept := make(map[string]bool)
+	for _, snapshot := range snapshots {
+		kept[snapshot.SnapshotId] = true
+	}
+	for _, snapshot := range pruned {
+		delete(kept, snapshot.SnapshotId)
+	}
+	// The last snapshot of the last 7 days (March 11-17), plus the last one of
+	// the week of March 1-7, plus the one used by an image
+	if len(kept) != 9 {
+		t.Errorf("Unexpected kept snapshots: %d", len(kept))
+	}
+	if !kept[snapshots[0].SnapshotId] || !kept[snapshots[41].SnapshotId] {
+		t.Errorf("Unexpected kept snapshots: %v", kept)
+	}
+}
+
+func TestSnapshotRetentionPolicyIgnoresFailedSnapshots(t *testing.T) {
+	now := time.Date(2021, 3, 17, 12, 0, 0, 0, time.UTC)
+	snapshots := []SnapshotType{
+		{SnapshotId: "s-failed", Status: "failed", CreationTime: util.NewISO6801Time(now)},
+		{SnapshotId: "s-progressing", Status: "progressing", CreationTime: util.NewISO6801Time(now.Add(-time.Hour))},
+		{SnapshotId: "s-good", Status: "accomplished", CreationTime: util.NewISO6801Time(now.Add(-2 * time.Hour))},
+		{SnapshotId: "s-old", Status: "accomplished", CreationTime: util.NewISO6801Time(now.Add(-3 * time.Hour))},
+	}
+	for _, policy := range []SnapshotRetentionPolicy{{KeepLast: 1}, {KeepDaily: 1}, {KeepWeekly: 1}} {
+		pruned := policy.Prune(snapshots)
+		if len(pruned) != 1 || pruned[0].SnapshotId != "s-old" {
+			t.Errorf("Unexpected pruned snapshots with %+v: %v", policy, pruned)
+		}
+	}
+}
+
+func TestPruneSnapshotsRequiresTags(t *testing.T) {
+	client, recorder := newOfflineTestClient(func(query url.Values) (int, interface{}) {
+		return http.StatusOK, DescribeSnapshotsResponse{}
+	})
+	_, err := client.PruneSnapshots(&PruneSnapshotsArgs{
+		RegionId: "cn-hangzhou",
+		Policy:   SnapshotRetentionPolicy{KeepLast: 1},
+	})
+	if err == nil || len(recorder.requests) != 0 {
+		t.Errorf("Expected PruneSnapshots to be rejected without tags, got %v", err)
+	}
+}
+
+func TestPruneSnapshotsSkipsReleasedDisks(t *testing.T) {
+	now := time.Now()
+	client, recorder := newOfflineTestClient(func(query url.Values) (int, interface{}) {
+		if query.Get("Action") != "DescribeSnapshots" {
+			return http.StatusOK, DescribeSnapshotsResponse{}
+		}
+		response := DescribeSnapshotsResponse{}
+		response.TotalCount = 4
+		response.PageNumber = 1
+		response.PageSize = 50
+		response.Snapshots.Snapshot = []SnapshotType{
+			{SnapshotId: "s-1", SourceDiskId: "d-1", Status: "accomplished", CreationTime: util.NewISO6801Time(now)},
+			{SnapshotId: "s-2", SourceDiskId: "", Status: "accomplished", CreationTime: util.NewISO6801Time(now.Add(-time.Hour))},
+			{SnapshotId: "s-3", SourceDiskId: "d-1", Status: "accomplished", CreationTime: util.NewISO6801Time(now.Add(-2 * time.Hour))},
+			{SnapshotId: "s-4", SourceDiskId: "", Status: "accomplished", CreationTime: util.NewISO6801Time(now.Add(-3 * time.Hour))},
+		}
+		return http.StatusOK, response
+	})
+
+	pruned, err := client.PruneSnapshots(&PruneSnapshotsArgs{
+		RegionId: "cn-hangzhou",
+		Tag:      []TagType{{Key: "backup", Value: "daily"}},
+		Policy:   SnapshotRetentionPolicy{KeepLast: 1},
+	})
+	if err != nil {
+		t.Fatalf("Failed to prune snapshots: %v", err)
+	}
+	if len(pruned) != 1 || pruned[0].SnapshotId != "s-3" {
+		t.Errorf("Unexpected pruned snapshots %v", pruned)
+	}
+	actions := recorder.actions()
+	if len(actions) != 2 || actions[1] != "DeleteSnapshot" || recorder.requests[1].Get("SnapshotId") != "s-3" {
+		t.Errorf("Unexpected requests %v", actions)
+	}
+}
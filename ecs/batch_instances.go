@@ -0,0 +1,242 @@
+package ecs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/denverdino/aliyungo/common"
+)
+
+// BatchOptimization is the behavior of a batch operation when some of the instances fail
+type BatchOptimization string
+
+const (
+	AllTogether  = BatchOptimization("AllTogether")  // The operation fails for all instances when one fails
+	SuccessFirst = BatchOptimization("SuccessFirst") // The operation goes on with the other instances
+)
+
+// MaxBatchInstances is the maximum number of instances of a batch operation
+const MaxBatchInstances = 100
+
+// checkBatchInstances rejects a batch operation on more than MaxBatchInstances
+// instances before calling the API
+func checkBatchInstances(instanceIds []string) error {
+	if len(instanceIds) > MaxBatchInstances {
+		return common.GetClientErrorFromString(fmt.Sprintf("Too many instances %d, at most %d instances per batch", len(instanceIds), MaxBatchInstances))
+	}
+	return nil
+}
+
+// InstanceResponseType is the result of a batch operation for one instance,
+// Code is "200" when it succeeded
+type InstanceResponseType struct {
+	Code           string
+	Message        string
+	InstanceId     string
+	CurrentStatus  InstanceStatus
+	PreviousStatus InstanceStatus
+}
+
+type BatchInstancesResponse struct {
+	common.Response
+	InstanceResponses struct {
+		InstanceResponse []InstanceResponseType
+	}
+}
+
+type StartInstancesArgs struct {
+	RegionId          common.Region
+	InstanceId        []string `query:"list"`
+	BatchOptimization BatchOptimization
+	DryRun            bool
+}
+
+// StartInstances starts up to MaxBatchInstances instances
+//
+// You can read doc at https://help.aliyun.com/document_detail/155373.html
+func (client *Client) StartInstances(args *StartInstancesArgs) (responses []InstanceResponseType, err error) {
+	if err = checkBatchInstances(args.InstanceId); err != nil {
+		return nil, err
+	}
+	response := BatchInstancesResponse{}
+	err = client.Invoke("StartInstances", args, &response)
+	if err != nil {
+		return nil, err
+	}
+	return response.InstanceResponses.InstanceResponse, nil
+}
+
+type StoppedMode string
+
+const (
+	StopCharging = StoppedMode("StopCharging")
+	KeepCharging = StoppedMode("KeepCharging")
+)
+
+type StopInstancesArgs struct {
+	RegionId          common.Region
+	InstanceId        []string `query:"list"`
+	ForceStop         bool
+	StoppedMode       StoppedMode
+	BatchOptimization BatchOptimization
+	DryRun            bool
+}
+
+// StopInstances stops up to MaxBatchInstances instances
+//
+// You can read doc at https://help.aliyun.com/document_detail/155372.html
+func (client *Client) StopInstances(args *StopInstancesArgs) (responses []InstanceResponseType, err error) {
+	if err = checkBatchInstances(args.InstanceId); err != nil {
+		return nil, err
+	}
+	response := BatchInstancesResponse{}
+	err = client.Invoke("StopInstances", args, &response)
+	if err != nil {
+		return nil, err
+	}
+	return response.InstanceResponses.InstanceResponse, nil
+}
+
+type RebootInstancesArgs struct {
+	RegionId          common.Region
+	InstanceId        []string `query:"list"`
+	ForceReboot       bool
+	BatchOptimization BatchOptimization
+	DryRun            bool
+}
+
+// RebootInstances reboots up to MaxBatchInstances instances
+//
+// You can read doc at https://help.aliyun.com/document_detail/155371.html
+func (client *Client) RebootInstances(args *RebootInstancesArgs) (responses []InstanceResponseType, err error) {
+	if err = checkBatchInstances(args.InstanceId); err != nil {
+		return nil, err
+	}
+	response := BatchInstancesResponse{}
+	err = client.Invoke("RebootInstances", args, &response)
+	if err != nil {
+		return nil, err
+	}
+	return response.InstanceResponses.InstanceResponse, nil
+}
+
+type DeleteInstancesArgs struct {
+	RegionId              common.Region
+	InstanceId            []string `query:"list"`
+	Force                 bool
+	TerminateSubscription bool
+	ClientToken           string
+	DryRun                bool
+}
+
+type DeleteInstancesResponse struct {
+	common.Response
+}
+
+// DeleteInstances releases up to MaxBatchInstances instances. The operation
+// fails for all instances when one of them cannot be released, so that there
+// are no per-instance results.
+//
+// You can read doc at https://help.aliyun.com/document_detail/163589.html
+func (client *Client) DeleteInstances(args *DeleteInstancesArgs) error {
+	if err := checkBatchInstances(args.InstanceId); err != nil {
+		return err
+	}
+	response := DeleteInstancesResponse{}
+	return client.Invoke("DeleteInstances", args, &response)
+}
+
+// IsDryRunOperation reports whether err is the success of a request with DryRun
+func IsDryRunOperation(err error) bool {
+	return common.IsErrorCode(err, "DryRunOperation")
+}
+
+// Default number of concurrent DescribeInstanceStatus calls of WaitForInstances
+const DefaultWaitForInstancesConcurrency = 4
+
+// Maximum page size of DescribeInstanceStatus
+const describeInstanceStatusPageSize = 50
+
+// WaitForInstances waits for all instances to given status. The instances are
+// polled with DescribeInstanceStatus by pages of 50, with at most concurrency
+// pages polled at once. The instances missing from DescribeInstanceStatus are
+// Deleted.
+func (client *Client) WaitForInstances(regionId common.Region, instanceIds []string, status InstanceStatus, timeout int, concurrency int) error {
+	if timeout <= 0 {
+		timeout = InstanceDefaultTimeout
+	}
+	if concurrency <= 0 {
+		concurrency = DefaultWaitForInstancesConcurrency
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	slots := make(chan struct{}, concurrency)
+	errs := make(chan error, 1)
+	var wg sync.WaitGroup
+	for start := 0; start < len(instanceIds); start += describeInstanceStatusPageSize {
+		end := start + describeInstanceStatusPageSize
+		if end > len(instanceIds) {
+			end = len(instanceIds)
+		}
+		ids := instanceIds[start:end]
+
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			if err := client.waitForInstancesPage(ctx, regionId, ids, status, timeout); err != nil {
+				select {
+				case errs <- err:
+					cancel()
+				default:
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	select {
+	case err := <-errs:
+		return err
+	default:
+		return nil
+	}
+}
+
+func (client *Client) waitForInstancesPage(ctx context.Context, regionId common.Region, instanceIds []string, status InstanceStatus, timeout int) error {
+	var pending []string
+	waiter := common.NewWaiter(timeout, DefaultWaitForInterval, func() (interface{}, error) {
+		return client.DescribeInstanceStatusWithRaw(&DescribeInstanceStatusArgs{
+			RegionId:   regionId,
+			InstanceId: instanceIds,
+			Pagination: common.Pagination{PageSize: describeInstanceStatusPageSize},
+		})
+	}, func(result interface{}) bool {
+		statuses := make(map[string]InstanceStatus)
+		for _, item := range result.(*DescribeInstanceStatusResponse).InstanceStatuses.InstanceStatus {
+			statuses[item.InstanceId] = item.Status
+		}
+		pending = pending[:0]
+		for _, id := range instanceIds {
+			current, ok := statuses[id]
+			if !ok {
+				current = Deleted
+			}
+			if current != status {
+				pending = append(pending, id)
+			}
+		}
+		return len(pending) == 0
+	})
+	_, err := waiter.Wait(ctx)
+	if common.IsWaitTimeout(err) {
+		return common.GetClientErrorFromString(fmt.Sprintf("Timeout waiting for instances %v to %s", pending, status))
+	}
+	return err
+}
@@ -0,0 +1,165 @@
+package ecs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/denverdino/aliyungo/common"
+	"github.com/denverdino/aliyungo/util"
+)
+
+// ecsRecorder records the query of each request and answers it with the
+// status and the JSON encoded body returned by respond
+type ecsRecorder struct {
+	mu       sync.Mutex
+	requests []url.Values
+	respond  func(query url.Values) (int, interface{})
+}
+
+func (r *ecsRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	query := req.URL.Query()
+	r.mu.Lock()
+	r.requests = append(r.requests, query)
+	r.mu.Unlock()
+
+	status, body := r.respond(query)
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Body:       ioutil.NopCloser(bytes.NewReader(data)),
+		Request:    req,
+	}, nil
+}
+
+// actions returns the Action of the recorded requests
+func (r *ecsRecorder) actions() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var actions []string
+	for _, query := range r.requests {
+		actions = append(actions, query.Get("Action"))
+	}
+	return actions
+}
+
+func newOfflineTestClient(respond func(query url.Values) (int, interface{})) (*Client, *ecsRecorder) {
+	recorder := &ecsRecorder{respond: respond}
+	client := NewClientWithEndpoint("http://ecs.example.com", "id", "secret")
+	client.SetTransport(recorder)
+	return client, recorder
+}
+
+func TestStopInstancesArgs(t *testing.T) {
+	values := util.ConvertToQueryValues(&StopInstancesArgs{
+		RegionId:          "cn-hangzhou",
+		InstanceId:        []string{"i-1", "i-2"},
+		StoppedMode:       StopCharging,
+		BatchOptimization: SuccessFirst,
+	})
+	expected := map[string]string{
+		"RegionId":          "cn-hangzhou",
+		"InstanceId.1":      "i-1",
+		"InstanceId.2":      "i-2",
+		"StoppedMode":       "StopCharging",
+		"BatchOptimization": "SuccessFirst",
+		"ForceStop":         "false",
+		"DryRun":            "false",
+	}
+	for key, value := range expected {
+		if values.Get(key) != value {
+			t.Errorf("Expected %s=%q, got %q", key, value, values.Get(key))
+		}
+	}
+	if _, ok := values["InstanceId"]; ok {
+		t.Errorf("Unexpected InstanceId %q", values.Get("InstanceId"))
+	}
+}
+
+func TestWaitForInstances(t *testing.T) {
+	var instanceIds []string
+	for i := 0; i < 120; i++ {
+		instanceIds = append(instanceIds, fmt.Sprintf("i-%d", i))
+	}
+	client, recorder := newOfflineTestClient(func(query url.Values) (int, interface{}) {
+		response := DescribeInstanceStatusResponse{}
+		for i := 1; query.Get(fmt.Sprintf("InstanceId.%d", i)) != ""; i++ {
+			id := query.Get(fmt.Sprintf("InstanceId.%d", i))
+			if id == "i-119" {
+				// Missing instances are deleted
+				continue
+			}
+			response.InstanceStatuses.InstanceStatus = append(response.InstanceStatuses.InstanceStatus,
+				InstanceStatusItemType{InstanceId: id, Status: Stopped})
+		}
+		return http.StatusOK, response
+	})
+
+	if err := client.WaitForInstances("cn-hangzhou", instanceIds[:119], Stopped, 10, 2); err != nil {
+		t.Fatalf("Failed to wait for instances: %v", err)
+	}
+	if len(recorder.requests) != 3 {
+		t.Fatalf("Expected 3 pages of DescribeInstanceStatus, got %v", recorder.actions())
+	}
+	for _, query := range recorder.requests {
+		if query.Get("Action") != "DescribeInstanceStatus" || query.Get("InstanceId.51") != "" {
+			t.Errorf("Unexpected request %v", query)
+		}
+	}
+
+	if err := client.WaitForInstances("cn-hangzhou", []string{"i-119"}, Deleted, 10, 0); err != nil {
+		t.Errorf("Expected the missing instance to be deleted, got %v", err)
+	}
+
+	err := client.WaitForInstances("cn-hangzhou", instanceIds[:2], Running, 1, 0)
+	if !common.IsWaitTimeout(err) || !strings.Contains(err.Error(), "[i-0 i-1]") {
+		t.Errorf("Expected the timeout of the pending instances, got %v", err)
+	}
+
+	failing, _ := newOfflineTestClient(func(query url.Values) (int, interface{}) {
+		return http.StatusBadRequest, map[string]string{"Code": "InvalidRegionId.NotFound", "Message": "not found"}
+	})
+	if err := failing.WaitForInstances("cn-hangzhou", instanceIds, Stopped, 10, 0); err == nil {
+		t.Errorf("Expected the DescribeInstanceStatus error")
+	}
+}
+
+func TestBatchInstancesLimit(t *testing.T) {
+	client, recorder := newOfflineTestClient(func(query url.Values) (int, interface{}) {
+		return http.StatusOK, BatchInstancesResponse{}
+	})
+	instanceIds := make([]string, MaxBatchInstances+1)
+	for i := range instanceIds {
+		instanceIds[i] = fmt.Sprintf("i-%d", i)
+	}
+
+	if _, err := client.StartInstances(&StartInstancesArgs{InstanceId: instanceIds}); err == nil {
+		t.Errorf("Expected StartInstances to reject %d instances", len(instanceIds))
+	}
+	if _, err := client.StopInstances(&StopInstancesArgs{InstanceId: instanceIds}); err == nil {
+		t.Errorf("Expected StopInstances to reject %d instances", len(instanceIds))
+	}
+	if _, err := client.RebootInstances(&RebootInstancesArgs{InstanceId: instanceIds}); err == nil {
+		t.Errorf("Expected RebootInstances to reject %d instances", len(instanceIds))
+	}
+	if err := client.DeleteInstances(&DeleteInstancesArgs{InstanceId: instanceIds}); err == nil {
+		t.Errorf("Expected DeleteInstances to reject %d instances", len(instanceIds))
+	}
+	if len(recorder.requests) != 0 {
+		t.Errorf("Unexpected requests %v", recorder.actions())
+	}
+
+	if _, err := client.StopInstances(&StopInstancesArgs{InstanceId: instanceIds[:MaxBatchInstances]}); err != nil {
+		t.Errorf("Failed to stop %d instances: %v", MaxBatchInstances, err)
+	}
+}
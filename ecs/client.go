@@ -169,3 +169,4 @@ func NewVPCClientWithRegion(endpoint string, accessKeyId string, accessKeySecret
 	client.NewInit(endpoint, VPCAPIVersion, accessKeyId, accessKeySecret, VPCServiceCode, regionID)
 	return client
 }
+
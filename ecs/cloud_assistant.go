@@ -0,0 +1,456 @@
+package ecs
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/denverdino/aliyungo/common"
+)
+
+type CommandType string
+
+const (
+	RunShellScript      = CommandType("RunShellScript")
+	RunBatScript        = CommandType("RunBatScript")
+	RunPowerShellScript = CommandType("RunPowerShellScript")
+)
+
+type ContentEncoding string
+
+const (
+	ContentEncodingPlainText = ContentEncoding("PlainText")
+	ContentEncodingBase64    = ContentEncoding("Base64")
+)
+
+type InvocationStatus string
+
+const (
+	InvocationPending   = InvocationStatus("Pending")
+	InvocationScheduled = InvocationStatus("Scheduled")
+	InvocationRunning   = InvocationStatus("Running")
+	InvocationStopping  = InvocationStatus("Stopping")
+	InvocationSuccess   = InvocationStatus("Success")
+	InvocationFailed    = InvocationStatus("Failed")
+	InvocationStopped   = InvocationStatus("Stopped")
+	InvocationInvalid   = InvocationStatus("Invalid")
+	InvocationAborted   = InvocationStatus("Aborted")
+	InvocationError     = InvocationStatus("Error")
+	InvocationTimeout   = InvocationStatus("Timeout")
+	InvocationCancelled = InvocationStatus("Cancelled")
+)
+
+// IsFinished reports whether the invocation of a command on an instance is over
+func (status InvocationStatus) IsFinished() bool {
+	switch status {
+	case InvocationPending, InvocationScheduled, InvocationRunning, InvocationStopping:
+		return false
+	}
+	return true
+}
+
+type CreateCommandArgs struct {
+	RegionId        common.Region
+	Name            string
+	Description     string
+	Type            CommandType
+	CommandContent  string // Plain text content, encoded to Base64 by CreateCommand
+	WorkingDir      string
+	Timeout         int // Timeout in seconds, 60 by default
+	EnableParameter bool
+	ContentEncoding ContentEncoding
+}
+
+type CreateCommandResponse struct {
+	common.Response
+	CommandId string
+}
+
+// CreateCommand creates a Cloud Assistant command
+//
+// You can read doc at https://help.aliyun.com/document_detail/64844.html
+func (client *Client) CreateCommand(args *CreateCommandArgs) (commandId string, err error) {
+	encoded := *args
+	encoded.CommandContent = base64.StdEncoding.EncodeToString([]byte(args.CommandContent))
+	encoded.ContentEncoding = ContentEncodingBase64
+	response := CreateCommandResponse{}
+	err = client.Invoke("CreateCommand", &encoded, &response)
+	if err != nil {
+		return "", err
+	}
+	return response.CommandId, nil
+}
+
+type RunCommandArgs struct {
+	RegionId        common.Region
+	InstanceId      []string `query:"list"`
+	Type            CommandType
+	CommandContent  string // Plain text content, encoded to Base64 by RunCommand
+	Name            string
+	Description     string
+	WorkingDir      string
+	Timeout         int // Timeout in seconds, 60 by default
+	Username        string
+	KeepCommand     bool
+	EnableParameter bool
+	Parameters      string // JSON object of the parameter values
+	RepeatMode      string
+	Frequency       string
+	ContentEncoding ContentEncoding
+}
+
+type RunCommandResponse struct {
+	common.Response
+	CommandId string
+	InvokeId  string
+}
+
+// RunCommand creates a command and invokes it on the instances at once
+//
+// You can read doc at https://help.aliyun.com/document_detail/141751.html
+func (client *Client) RunCommand(args *RunCommandArgs) (response *RunCommandResponse, err error) {
+	encoded := *args
+	encoded.CommandContent = base64.StdEncoding.EncodeToString([]byte(args.CommandContent))
+	encoded.ContentEncoding = ContentEncodingBase64
+	response = &RunCommandResponse{}
+	err = client.Invoke("RunCommand", &encoded, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+type InvokeCommandArgs struct {
+	RegionId   common.Region
+	CommandId  string
+	InstanceId []string `query:"list"`
+	Username   string
+	Parameters string // JSON object of the parameter values
+	RepeatMode string
+	Frequency  string
+}
+
+type InvokeCommandResponse struct {
+	common.Response
+	InvokeId string
+}
+
+// InvokeCommand invokes a command on the instances
+//
+// You can read doc at https://help.aliyun.com/document_detail/64841.html
+func (client *Client) InvokeCommand(args *InvokeCommandArgs) (invokeId string, err error) {
+	response := InvokeCommandResponse{}
+	err = client.Invoke("InvokeCommand", args, &response)
+	if err != nil {
+		return "", err
+	}
+	return response.InvokeId, nil
+}
+
+type DescribeInvocationsArgs struct {
+	RegionId        common.Region
+	InvokeId        string
+	CommandId       string
+	InstanceId      string
+	CommandType     CommandType
+	InvokeStatus    InvocationStatus
+	IncludeOutput   bool
+	ContentEncoding ContentEncoding // Encoding of the output, Base64 by default
+	common.Pagination
+}
+
+type InvokeInstanceType struct {
+	InstanceId           string
+	InvocationStatus     InvocationStatus
+	InstanceInvokeStatus string
+	ExitCode             int
+	Output               string
+	Dropped              int
+	ErrorCode            string
+	ErrorInfo            string
+	Repeats              int
+	StartTime            string
+	FinishTime           string
+	StopTime             string
+}
+
+type InvocationType struct {
+	InvokeId         string
+	CommandId        string
+	CommandName      string
+	CommandType      CommandType
+	CommandContent   string
+	InvocationStatus InvocationStatus
+	Frequency        string
+	RepeatMode       string
+	Parameters       string
+	Username         string
+	CreationTime     string
+	InvokeInstances  struct {
+		InvokeInstance []InvokeInstanceType
+	}
+}
+
+type DescribeInvocationsResponse struct {
+	common.Response
+	common.PaginationResult
+	Invocations struct {
+		Invocation []InvocationType
+	}
+}
+
+// DescribeInvocations describes the invocations of commands. The Base64
+// output of the instances is decoded.
+//
+// You can read doc at https://help.aliyun.com/document_detail/64840.html
+func (client *Client) DescribeInvocations(args *DescribeInvocationsArgs) (invocations []InvocationType, pagination *common.PaginationResult, err error) {
+	args.Validate()
+	response := DescribeInvocationsResponse{}
+	err = client.Invoke("DescribeInvocations", args, &response)
+	if err != nil {
+		return nil, nil, err
+	}
+	invocations = response.Invocations.Invocation
+	if args.IncludeOutput && args.ContentEncoding != ContentEncodingPlainText {
+		for i := range invocations {
+			instances := invocations[i].InvokeInstances.InvokeInstance
+			for j := range instances {
+				instances[j].Output = decodeOutput(instances[j].Output)
+			}
+		}
+	}
+	return invocations, &response.PaginationResult, nil
+}
+
+type DescribeInvocationResultsArgs struct {
+	RegionId           common.Region
+	InvokeId           string
+	InstanceId         string
+	CommandId          string
+	InvokeRecordStatus string
+	IncludeHistory     bool
+	ContentEncoding    ContentEncoding // Encoding of the output, Base64 by default
+	common.Pagination
+}
+
+type InvocationResultType struct {
+	CommandId          string
+	InvokeId           string
+	InstanceId         string
+	InvocationStatus   InvocationStatus
+	InvokeRecordStatus string
+	ExitCode           int
+	Output             string
+	Dropped            int
+	ErrorCode          string
+	ErrorInfo          string
+	Repeats            int
+	Username           string
+	StartTime          string
+	FinishedTime       string
+	StopTime           string
+}
+
+type DescribeInvocationResultsResponse struct {
+	common.Response
+	Invocation struct {
+		common.PaginationResult
+		InvocationResults struct {
+			InvocationResult []InvocationResultType
+		}
+	}
+}
+
+// DescribeInvocationResults describes the results of invocations on each
+// instance. The Base64 output is decoded.
+//
+// You can read doc at https://help.aliyun.com/document_detail/64845.html
+func (client *Client) DescribeInvocationResults(args *DescribeInvocationResultsArgs) (results []InvocationResultType, pagination *common.PaginationResult, err error) {
+	args.Validate()
+	response := DescribeInvocationResultsResponse{}
+	err = client.Invoke("DescribeInvocationResults", args, &response)
+	if err != nil {
+		return nil, nil, err
+	}
+	results = response.Invocation.InvocationResults.InvocationResult
+	if args.ContentEncoding != ContentEncodingPlainText {
+		for i := range results {
+			results[i].Output = decodeOutput(results[i].Output)
+		}
+	}
+	return results, &response.Invocation.PaginationResult, nil
+}
+
+// decodeOutput decodes Base64 output, keeping the output as is when it is not valid Base64
+func decodeOutput(output string) string {
+	data, err := base64.StdEncoding.DecodeString(output)
+	if err != nil {
+		return output
+	}
+	return string(data)
+}
+
+type StopInvocationArgs struct {
+	RegionId   common.Region
+	InvokeId   string
+	InstanceId []string `query:"list"`
+}
+
+type StopInvocationResponse struct {
+	common.Response
+}
+
+// StopInvocation stops an invocation on the instances, or on all its instances when none is given
+//
+// You can read doc at https://help.aliyun.com/document_detail/64838.html
+func (client *Client) StopInvocation(args *StopInvocationArgs) error {
+	response := StopInvocationResponse{}
+	return client.Invoke("StopInvocation", args, &response)
+}
+
+type DescribeCloudAssistantStatusArgs struct {
+	RegionId   common.Region
+	InstanceId []string `query:"list"`
+	OSType     string
+}
+
+type CloudAssistantStatusType struct {
+	InstanceId            string
+	CloudAssistantStatus  string // "true" when the Cloud Assistant client is running
+	CloudAssistantVersion string
+	OSType                string
+	LastInvokedTime       string
+	ActiveTaskCount       int
+	InvocationCount       int
+}
+
+type DescribeCloudAssistantStatusResponse struct {
+	common.Response
+	InstanceCloudAssistantStatusSet struct {
+		InstanceCloudAssistantStatus []CloudAssistantStatusType
+	}
+}
+
+// DescribeCloudAssistantStatus describes whether the Cloud Assistant client is running on the instances
+//
+// You can read doc at https://help.aliyun.com/document_detail/87346.html
+func (client *Client) DescribeCloudAssistantStatus(args *DescribeCloudAssistantStatusArgs) (statuses []CloudAssistantStatusType, err error) {
+	response := DescribeCloudAssistantStatusResponse{}
+	err = client.Invoke("DescribeCloudAssistantStatus", args, &response)
+	if err != nil {
+		return nil, err
+	}
+	return response.InstanceCloudAssistantStatusSet.InstanceCloudAssistantStatus, nil
+}
+
+type RunShellCommandArgs struct {
+	RegionId    common.Region
+	InstanceIds []string
+	Command     string
+	WorkingDir  string
+	Timeout     int // Timeout of the command in seconds, 60 by default
+	Username    string
+}
+
+// RunShellCommand runs a shell command on the instances and waits until it
+// is finished on all of them, or ctx is done. It returns the results with the
+// exit code and the output of each instance, the results are partial when
+// ctx is done first.
+func (client *Client) RunShellCommand(ctx context.Context, args *RunShellCommandArgs) (results []InvocationResultType, err error) {
+	run, err := client.RunCommand(&RunCommandArgs{
+		RegionId:       args.RegionId,
+		InstanceId:     args.InstanceIds,
+		Type:           RunShellScript,
+		CommandContent: args.Command,
+		WorkingDir:     args.WorkingDir,
+		Timeout:        args.Timeout,
+		Username:       args.Username,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	waiter := &common.Waiter{
+		Describe: func(context.Context) (interface{}, error) {
+			return client.describeAllInvocationResults(args.RegionId, run.InvokeId)
+		},
+		Success: func(result interface{}) bool {
+			results := result.([]InvocationResultType)
+			if len(results) < len(args.InstanceIds) {
+				return false
+			}
+			for _, r := range results {
+				if !r.InvocationStatus.IsFinished() {
+					return false
+				}
+			}
+			return true
+		},
+		Retryable: isTransientError,
+		Delay:     time.Second,
+		MaxDelay:  DefaultWaitForInterval * time.Second,
+	}
+	result, err := waiter.Wait(ctx)
+	if result != nil {
+		results = result.([]InvocationResultType)
+	}
+	return results, err
+}
+
+// networkFailures are the messages of the client errors failing to reach the
+// endpoint, the client errors only keep the message of the original error
+var networkFailures = []string{
+	"connection reset",
+	"connection refused",
+	"broken pipe",
+	"i/o timeout",
+	"unexpected EOF",
+	"TLS handshake timeout",
+	"Client.Timeout exceeded",
+}
+
+// isTransientError reports whether err is a throttling, a server error or a
+// failure to reach the endpoint, which are worth retrying
+func isTransientError(err error) bool {
+	e, ok := err.(*common.Error)
+	if !ok {
+		return false
+	}
+	switch e.Code {
+	case "Throttling", "Throttling.User", "Throttling.Api", "ServiceUnavailable":
+		return true
+	case "AliyunGoClientFailure":
+		for _, failure := range networkFailures {
+			if strings.Contains(e.Message, failure) {
+				return true
+			}
+		}
+		return false
+	}
+	return e.StatusCode >= 500
+}
+
+func (client *Client) describeAllInvocationResults(regionId common.Region, invokeId string) (interface{}, error) {
+	var all []InvocationResultType
+	args := &DescribeInvocationResultsArgs{
+		RegionId: regionId,
+		InvokeId: invokeId,
+		Pagination: common.Pagination{
+			PageNumber: 1,
+			PageSize:   50,
+		},
+	}
+	for {
+		results, pagination, err := client.DescribeInvocationResults(args)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, results...)
+		next := pagination.NextPage()
+		if next == nil {
+			return all, nil
+		}
+		args.Pagination = *next
+	}
+}
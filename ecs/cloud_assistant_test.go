@@ -0,0 +1,88 @@
+package ecs
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/denverdino/aliyungo/common"
+)
+
+func TestDecodeOutput(t *testing.T) {
+	if output := decodeOutput("aGVsbG8K"); output != "hello\n" {
+		t.Errorf("Unexpected output %q", output)
+	}
+	if output := decodeOutput("not base64!"); output != "not base64!" {
+		t.Errorf("Unexpected output %q", output)
+	}
+	if InvocationRunning.IsFinished() || !InvocationTimeout.IsFinished() {
+		t.Errorf("Unexpected finished statuses")
+	}
+}
+
+func TestRunCommandKeepsArgs(t *testing.T) {
+	client, recorder := newOfflineTestClient(func(query url.Values) (int, interface{}) {
+		return http.StatusOK, RunCommandResponse{CommandId: "c-1", InvokeId: "t-1"}
+	})
+	args := &RunCommandArgs{
+		RegionId:       "cn-hangzhou",
+		InstanceId:     []string{"i-1"},
+		Type:           RunShellScript,
+		CommandContent: "uname -a",
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := client.RunCommand(args); err != nil {
+			t.Fatalf("Failed to run command: %v", err)
+		}
+	}
+	if args.CommandContent != "uname -a" || args.ContentEncoding != "" {
+		t.Errorf("RunCommand modified the args: %+v", args)
+	}
+	for _, query := range recorder.requests {
+		if query.Get("CommandContent") != "dW5hbWUgLWE=" || query.Get("ContentEncoding") != "Base64" {
+			t.Errorf("Unexpected command content %q", query.Get("CommandContent"))
+		}
+	}
+}
+
+func TestIsTransientError(t *testing.T) {
+	if !isTransientError(common.GetCustomError("Throttling", "throttled")) {
+		t.Errorf("Expected throttling to be transient")
+	}
+	if !isTransientError(common.GetClientErrorFromString(`Get "http://ecs.aliyuncs.com": read tcp: connection reset by peer`)) {
+		t.Errorf("Expected a network failure to be transient")
+	}
+	if isTransientError(common.GetClientErrorFromString("invalid character '<' looking for beginning of value")) {
+		t.Errorf("Expected a decoding failure not to be transient")
+	}
+	if isTransientError(common.GetClientErrorFromString("Timeout")) {
+		t.Errorf("Expected a wait timeout not to be transient")
+	}
+	if !isTransientError(&common.Error{ErrorResponse: common.ErrorResponse{Code: "InternalError"}, StatusCode: 500}) {
+		t.Errorf("Expected a server error to be transient")
+	}
+	if isTransientError(common.GetCustomError("InvalidInvokeId.NotFound", "not found")) {
+		t.Errorf("Expected a client error not to be transient")
+	}
+}
+
+func ExampleClient_RunShellCommand() {
+	client := NewTestClient()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	results, err := client.RunShellCommand(ctx, &RunShellCommandArgs{
+		RegionId:    TestRegionID,
+		InstanceIds: []string{TestInstanceId},
+		Command:     "uname -a",
+	})
+	if err != nil {
+		fmt.Printf("Failed to run command: %v\n", err)
+	}
+	for _, result := range results {
+		fmt.Printf("Instance %s exited with %d: %s\n", result.InstanceId, result.ExitCode, result.Output)
+	}
+}
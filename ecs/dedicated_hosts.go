@@ -0,0 +1,186 @@
+package ecs
+
+import (
+	"github.com/denverdino/aliyungo/common"
+	"github.com/denverdino/aliyungo/util"
+)
+
+type DedicatedHostStatus string
+
+const (
+	DedicatedHostAvailable              = DedicatedHostStatus("Available")
+	DedicatedHostUnderAssessment        = DedicatedHostStatus("UnderAssessment")
+	DedicatedHostPermanentFailure       = DedicatedHostStatus("PermanentFailure")
+	DedicatedHostTempUnavailable        = DedicatedHostStatus("TempUnavailable")
+	DedicatedHostRedeploying            = DedicatedHostStatus("Redeploying")
+	DedicatedHostUnavailable            = DedicatedHostStatus("Unavailable")
+	DedicatedHostReleased               = DedicatedHostStatus("Released")
+	DedicatedHostUnderMaintenanceStatus = DedicatedHostStatus("UnderMaintenance")
+)
+
+type AllocateDedicatedHostsArgs struct {
+	RegionId               common.Region
+	ZoneId                 string
+	DedicatedHostType      string
+	DedicatedHostName      string
+	DedicatedHostClusterId string
+	Description            string
+	ResourceGroupId        string
+	ActionOnMaintenance    string // Migrate (default) | Stop
+	AutoPlacement          string // on (default) | off
+	AutoReleaseTime        string
+	ChargeType             common.InstanceChargeType
+	Period                 int
+	PeriodUnit             string // Month (default) | Year
+	AutoRenew              bool
+	AutoRenewPeriod        int
+	Quantity               int // 1 by default
+	MinQuantity            int
+	CpuOverCommitRatio     *float64 // 1 to 5, the default of the host type when nil
+	ClientToken            string
+	Tag                    []TagType
+}
+
+type AllocateDedicatedHostsResponse struct {
+	common.Response
+	DedicatedHostIdSets struct {
+		DedicatedHostId []string
+	}
+}
+
+// AllocateDedicatedHosts allocates dedicated hosts
+//
+// You can read doc at https://help.aliyun.com/document_detail/94474.html
+func (client *Client) AllocateDedicatedHosts(args *AllocateDedicatedHostsArgs) (dedicatedHostIds []string, err error) {
+	response := AllocateDedicatedHostsResponse{}
+	err = client.Invoke("AllocateDedicatedHosts", args, &response)
+	if err != nil {
+		return nil, err
+	}
+	return response.DedicatedHostIdSets.DedicatedHostId, nil
+}
+
+type DescribeDedicatedHostsArgs struct {
+	RegionId          common.Region
+	ZoneId            string
+	DedicatedHostIds  []string // Encoded to a JSON array
+	DedicatedHostName string
+	DedicatedHostType string
+	Status            DedicatedHostStatus
+	LockReason        LockReason
+	ResourceGroupId   string
+	Tag               []TagType
+	common.Pagination
+}
+
+type DedicatedHostCapacityType struct {
+	TotalVcpus            float64
+	AvailableVcpus        float64
+	TotalMemory           float64
+	AvailableMemory       float64
+	TotalLocalStorage     int
+	AvailableLocalStorage int
+	LocalStorageCategory  string
+}
+
+type DedicatedHostType struct {
+	DedicatedHostId        string
+	DedicatedHostName      string
+	DedicatedHostType      string
+	DedicatedHostClusterId string
+	Description            string
+	Status                 DedicatedHostStatus
+	RegionId               common.Region
+	ZoneId                 string
+	ResourceGroupId        string
+	MachineId              string
+	ChargeType             common.InstanceChargeType
+	ActionOnMaintenance    string
+	AutoPlacement          string
+	AutoReleaseTime        string
+	CreationTime           util.ISO6801Time
+	ExpiredTime            util.ISO6801Time
+	Sockets                int
+	Cores                  int
+	PhysicalGpus           int
+	GPUSpec                string
+	CpuOverCommitRatio     float64
+	Capacity               DedicatedHostCapacityType
+	Instances              struct {
+		Instance []struct {
+			InstanceId   string
+			InstanceType string
+		}
+	}
+	OperationLocks struct {
+		OperationLock []LockReasonType
+	}
+	Tags struct {
+		Tag []TagItemType
+	}
+}
+
+type DescribeDedicatedHostsResponse struct {
+	common.Response
+	common.PaginationResult
+	DedicatedHosts struct {
+		DedicatedHost []DedicatedHostType
+	}
+}
+
+// DescribeDedicatedHosts describes dedicated hosts
+//
+// You can read doc at https://help.aliyun.com/document_detail/94476.html
+func (client *Client) DescribeDedicatedHosts(args *DescribeDedicatedHostsArgs) (hosts []DedicatedHostType, pagination *common.PaginationResult, err error) {
+	args.Validate()
+	response := DescribeDedicatedHostsResponse{}
+	err = client.Invoke("DescribeDedicatedHosts", args, &response)
+	if err != nil {
+		return nil, nil, err
+	}
+	return response.DedicatedHosts.DedicatedHost, &response.PaginationResult, nil
+}
+
+type ModifyDedicatedHostAttributeArgs struct {
+	RegionId               common.Region
+	DedicatedHostId        string
+	DedicatedHostName      string
+	DedicatedHostClusterId string
+	Description            string
+	ActionOnMaintenance    string
+	AutoPlacement          string
+	CpuOverCommitRatio     *float64 // 1 to 5, unchanged when nil
+}
+
+type ModifyDedicatedHostAttributeResponse struct {
+	common.Response
+}
+
+// ModifyDedicatedHostAttribute modifies the attributes of a dedicated host
+//
+// You can read doc at https://help.aliyun.com/document_detail/94477.html
+func (client *Client) ModifyDedicatedHostAttribute(args *ModifyDedicatedHostAttributeArgs) error {
+	response := ModifyDedicatedHostAttributeResponse{}
+	return client.Invoke("ModifyDedicatedHostAttribute", args, &response)
+}
+
+type ReleaseDedicatedHostArgs struct {
+	RegionId        common.Region
+	DedicatedHostId string
+}
+
+type ReleaseDedicatedHostResponse struct {
+	common.Response
+}
+
+// ReleaseDedicatedHost releases a pay-as-you-go dedicated host without instances
+//
+// You can read doc at https://help.aliyun.com/document_detail/94478.html
+func (client *Client) ReleaseDedicatedHost(regionId common.Region, dedicatedHostId string) error {
+	args := ReleaseDedicatedHostArgs{
+		RegionId:        regionId,
+		DedicatedHostId: dedicatedHostId,
+	}
+	response := ReleaseDedicatedHostResponse{}
+	return client.Invoke("ReleaseDedicatedHost", &args, &response)
+}
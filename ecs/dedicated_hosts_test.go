@@ -0,0 +1,59 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/denverdino/aliyungo/util"
+)
+
+func TestAllocateDedicatedHostsArgs(t *testing.T) {
+	args := &AllocateDedicatedHostsArgs{
+		RegionId:          "cn-hangzhou",
+		ZoneId:            "cn-hangzhou-g",
+		DedicatedHostType: "ddh.g5",
+		Quantity:          2,
+		Tag:               []TagType{{Key: "env", Value: "test"}},
+	}
+	values := util.ConvertToQueryValues(args)
+	if _, ok := values["CpuOverCommitRatio"]; ok {
+		t.Errorf("Unexpected CpuOverCommitRatio %q", values.Get("CpuOverCommitRatio"))
+	}
+	if values.Get("Quantity") != "2" || values.Get("Tag.1.Key") != "env" || values.Get("Tag.1.Value") != "test" {
+		t.Errorf("Unexpected query %v", values)
+	}
+
+	ratio := 2.5
+	args.CpuOverCommitRatio = &ratio
+	if ratio := util.ConvertToQueryValues(args).Get("CpuOverCommitRatio"); ratio != "2.5000" {
+		t.Errorf("Unexpected CpuOverCommitRatio %q", ratio)
+	}
+}
+
+func TestModifyDedicatedHostAttributeArgs(t *testing.T) {
+	args := &ModifyDedicatedHostAttributeArgs{
+		RegionId:          "cn-hangzhou",
+		DedicatedHostId:   "dh-1",
+		DedicatedHostName: "host",
+	}
+	values := util.ConvertToQueryValues(args)
+	if _, ok := values["CpuOverCommitRatio"]; ok {
+		t.Errorf("Unexpected CpuOverCommitRatio %q", values.Get("CpuOverCommitRatio"))
+	}
+	if values.Get("DedicatedHostId") != "dh-1" || values.Get("DedicatedHostName") != "host" {
+		t.Errorf("Unexpected query %v", values)
+	}
+}
+
+func TestDescribeDedicatedHostsArgs(t *testing.T) {
+	values := util.ConvertToQueryValues(&DescribeDedicatedHostsArgs{
+		RegionId:         "cn-hangzhou",
+		DedicatedHostIds: []string{"dh-1", "dh-2"},
+		Status:           DedicatedHostAvailable,
+	})
+	if ids := values.Get("DedicatedHostIds"); ids != `["dh-1","dh-2"]` {
+		t.Errorf("Unexpected DedicatedHostIds %q", ids)
+	}
+	if values.Get("Status") != "Available" {
+		t.Errorf("Unexpected Status %q", values.Get("Status"))
+	}
+}
@@ -0,0 +1,108 @@
+package ecs
+
+import (
+	"github.com/denverdino/aliyungo/common"
+	"github.com/denverdino/aliyungo/util"
+)
+
+type DeploymentStrategy string
+
+const (
+	DeploymentStrategyAvailability      = DeploymentStrategy("Availability")      // Instances on different physical servers
+	DeploymentStrategyAvailabilityGroup = DeploymentStrategy("AvailabilityGroup") // Groups of instances on different physical servers
+)
+
+type CreateDeploymentSetArgs struct {
+	RegionId                         common.Region
+	DeploymentSetName                string
+	Description                      string
+	Strategy                         DeploymentStrategy
+	GroupCount                       int    // Number of groups of the AvailabilityGroup strategy
+	OnUnableToRedeployFailedInstance string // CancelMembershipAndStart (default) | KeepStopped
+	ClientToken                      string
+}
+
+type CreateDeploymentSetResponse struct {
+	common.Response
+	DeploymentSetId string
+}
+
+// CreateDeploymentSet creates a deployment set
+//
+// You can read doc at https://help.aliyun.com/document_detail/91269.html
+func (client *Client) CreateDeploymentSet(args *CreateDeploymentSetArgs) (deploymentSetId string, err error) {
+	response := CreateDeploymentSetResponse{}
+	err = client.Invoke("CreateDeploymentSet", args, &response)
+	if err != nil {
+		return "", err
+	}
+	return response.DeploymentSetId, nil
+}
+
+type DescribeDeploymentSetsArgs struct {
+	RegionId          common.Region
+	DeploymentSetIds  []string // Encoded to a JSON array
+	DeploymentSetName string
+	Strategy          DeploymentStrategy
+	common.Pagination
+}
+
+type DeploymentSetType struct {
+	DeploymentSetId          string
+	DeploymentSetName        string
+	DeploymentSetDescription string
+	DeploymentStrategy       DeploymentStrategy
+	GroupCount               int
+	InstanceAmount           int
+	CreationTime             util.ISO6801Time
+	InstanceIds              struct {
+		InstanceId []string
+	}
+	Capacities struct {
+		Capacity []struct {
+			ZoneId          string
+			UsedAmount      int
+			AvailableAmount int
+		}
+	}
+}
+
+type DescribeDeploymentSetsResponse struct {
+	common.Response
+	common.PaginationResult
+	DeploymentSets struct {
+		DeploymentSet []DeploymentSetType
+	}
+}
+
+// DescribeDeploymentSets describes deployment sets
+//
+// You can read doc at https://help.aliyun.com/document_detail/91313.html
+func (client *Client) DescribeDeploymentSets(args *DescribeDeploymentSetsArgs) (deploymentSets []DeploymentSetType, pagination *common.PaginationResult, err error) {
+	args.Validate()
+	response := DescribeDeploymentSetsResponse{}
+	err = client.Invoke("DescribeDeploymentSets", args, &response)
+	if err != nil {
+		return nil, nil, err
+	}
+	return response.DeploymentSets.DeploymentSet, &response.PaginationResult, nil
+}
+
+type DeleteDeploymentSetArgs struct {
+	RegionId        common.Region
+	DeploymentSetId string
+}
+
+type DeleteDeploymentSetResponse struct {
+	common.Response
+}
+
+// DeleteDeploymentSet deletes a deployment set without instances
+func (client *Client) DeleteDeploymentSet(regionId common.Region, deploymentSetId string) error {
+	args := DeleteDeploymentSetArgs{
+		RegionId:        regionId,
+		DeploymentSetId: deploymentSetId,
+	}
+	response := DeleteDeploymentSetResponse{}
+	return client.Invoke("DeleteDeploymentSet", &args, &response)
+}
@@ -0,0 +1,40 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/denverdino/aliyungo/util"
+)
+
+func TestCreateDeploymentSetArgs(t *testing.T) {
+	values := util.ConvertToQueryValues(&CreateDeploymentSetArgs{
+		RegionId:          "cn-hangzhou",
+		DeploymentSetName: "set",
+		Strategy:          DeploymentStrategyAvailabilityGroup,
+		GroupCount:        3,
+	})
+	expected := map[string]string{
+		"RegionId":          "cn-hangzhou",
+		"DeploymentSetName": "set",
+		"Strategy":          "AvailabilityGroup",
+		"GroupCount":        "3",
+	}
+	for key, value := range expected {
+		if values.Get(key) != value {
+			t.Errorf("Expected %s=%q, got %q", key, value, values.Get(key))
+		}
+	}
+	if len(values) != len(expected) {
+		t.Errorf("Unexpected query %v", values)
+	}
+}
+
+func TestDescribeDeploymentSetsArgs(t *testing.T) {
+	values := util.ConvertToQueryValues(&DescribeDeploymentSetsArgs{
+		RegionId:         "cn-hangzhou",
+		DeploymentSetIds: []string{"ds-1", "ds-2"},
+	})
+	if ids := values.Get("DeploymentSetIds"); ids != `["ds-1","ds-2"]` {
+		t.Errorf("Unexpected DeploymentSetIds %q", ids)
+	}
+}
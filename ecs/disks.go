@@ -1,7 +1,7 @@
 package ecs
 
 import (
-	"time"
+	"context"
 
 	"github.com/denverdino/aliyungo/common"
 	"github.com/denverdino/aliyungo/util"
@@ -359,23 +359,18 @@ func (client *Client) WaitForDisk(regionId common.Region, diskId string, status
 		RegionId: regionId,
 		DiskIds:  []string{diskId},
 	}
-
-	for {
+	waiter := common.NewWaiter(timeout, DefaultWaitForInterval, func() (interface{}, error) {
 		disks, _, err := client.DescribeDisks(&args)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		if len(disks) == 0 {
-			return common.GetClientErrorFromString("Not found")
-		}
-		if disks[0].Status == status {
-			break
-		}
-		timeout = timeout - DefaultWaitForInterval
-		if timeout <= 0 {
-			return common.GetClientErrorFromString("Timeout")
+			return nil, common.GetClientErrorFromString("Not found")
 		}
-		time.Sleep(DefaultWaitForInterval * time.Second)
-	}
-	return nil
+		return disks[0], nil
+	}, func(result interface{}) bool {
+		return result.(DiskItemType).Status == status
+	})
+	_, err := waiter.Wait(context.Background())
+	return err
 }
@@ -1,8 +1,8 @@
 package ecs
 
 import (
+	"context"
 	"fmt"
-	"time"
 
 	"github.com/denverdino/aliyungo/common"
 	"github.com/denverdino/aliyungo/util"
@@ -21,6 +21,8 @@ type CreateNetworkInterfaceArgs struct {
 	SecurityGroupIds               []string          `query:"list"` // optional
 	PrivateIpAddress               []string          `query:"list"` // optional
 	SecondaryPrivateIpAddressCount int
+	Ipv6Address                    []string `query:"list"` // optional
+	Ipv6AddressCount               int      // optional
 }
 
 type CreateNetworkInterfaceResponse struct {
@@ -71,6 +73,9 @@ type NetworkInterfaceType struct {
 	InstanceId       string
 	CreationTime     util.ISO6801Time
 	PrivateIpAddress string
+	Ipv6Sets         struct {
+		Ipv6Set []Ipv6SetType
+	}
 }
 
 type PrivateIpType struct {
@@ -140,6 +145,29 @@ type AssignPrivateIpAddressesResponse struct {
 	}
 }
 
+type AssignIpv6AddressesArgs struct {
+	RegionId           common.Region
+	NetworkInterfaceId string
+	Ipv6Address        []string `query:"list"` // optional
+	Ipv6AddressCount   int      // optional
+}
+
+type AssignIpv6AddressesResponse struct {
+	common.Response
+	NetworkInterfaceId string
+	Ipv6Sets           struct {
+		Ipv6Address []string
+	}
+}
+
+type UnassignIpv6AddressesArgs struct {
+	RegionId           common.Region
+	NetworkInterfaceId string
+	Ipv6Address        []string `query:"list"`
+}
+
+type UnassignIpv6AddressesResponse common.Response
+
 func (client *Client) CreateNetworkInterface(args *CreateNetworkInterfaceArgs) (resp *CreateNetworkInterfaceResponse, err error) {
 	resp = &CreateNetworkInterfaceResponse{}
 	err = client.Invoke("CreateNetworkInterface", args, resp)
@@ -188,6 +216,28 @@ func (client *Client) AssignPrivateIpAddresses(args *AssignPrivateIpAddressesArg
 	return resp, err
 }
 
+// AssignIpv6Addresses assigns IPv6 addresses of the IPv6 VSwitch to a network interface
+func (client *Client) AssignIpv6Addresses(args *AssignIpv6AddressesArgs) (resp *AssignIpv6AddressesResponse, err error) {
+	resp = &AssignIpv6AddressesResponse{}
+	err = client.Invoke("AssignIpv6Addresses", args, resp)
+	return resp, err
+}
+
+func (client *Client) UnassignIpv6Addresses(args *UnassignIpv6AddressesArgs) (resp *UnassignIpv6AddressesResponse, err error) {
+	resp = &UnassignIpv6AddressesResponse{}
+	err = client.Invoke("UnassignIpv6Addresses", args, resp)
+	return resp, err
+}
+
+// Ipv6Addresses returns the IPv6 addresses of the network interface
+func (ni *NetworkInterfaceType) Ipv6Addresses() []string {
+	var addresses []string
+	for _, ipv6 := range ni.Ipv6Sets.Ipv6Set {
+		addresses = append(addresses, ipv6.Ipv6Address)
+	}
+	return addresses
+}
+
 // Default timeout value for WaitForInstance method
 const NetworkInterfacesDefaultTimeout = 120
 
@@ -196,30 +246,25 @@ func (client *Client) WaitForNetworkInterface(regionId common.Region, eniID stri
 	if timeout <= 0 {
 		timeout = NetworkInterfacesDefaultTimeout
 	}
-	for {
-
-		eniIds := []string{eniID}
-
-		describeNetworkInterfacesArgs := DescribeNetworkInterfacesArgs{
-			RegionId:           regionId,
-			NetworkInterfaceId: eniIds,
-		}
-
+	describeNetworkInterfacesArgs := DescribeNetworkInterfacesArgs{
+		RegionId:           regionId,
+		NetworkInterfaceId: []string{eniID},
+	}
+	waiter := common.NewWaiter(timeout, DefaultWaitForInterval, func() (interface{}, error) {
 		nisResponse, err := client.DescribeNetworkInterfaces(&describeNetworkInterfacesArgs)
 		if err != nil {
-			return fmt.Errorf("Failed to describe network interface %v: %v", eniID, err)
+			return nil, fmt.Errorf("Failed to describe network interface %v: %v", eniID, err)
 		}
-
-		if len(nisResponse.NetworkInterfaceSets.NetworkInterfaceSet) > 0 && nisResponse.NetworkInterfaceSets.NetworkInterfaceSet[0].Status == status {
-			break
-		}
-
-		timeout = timeout - DefaultWaitForInterval
-		if timeout <= 0 {
+		return nisResponse.NetworkInterfaceSets.NetworkInterfaceSet, nil
+	}, func(result interface{}) bool {
+		nis := result.([]NetworkInterfaceType)
+		return len(nis) > 0 && nis[0].Status == status
+	})
+	if _, err := waiter.Wait(context.Background()); err != nil {
+		if common.IsWaitTimeout(err) {
 			return fmt.Errorf("Timeout for waiting available status for network interfaces")
 		}
-		time.Sleep(DefaultWaitForInterval * time.Second)
-
+		return err
 	}
 	return nil
 }
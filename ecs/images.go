@@ -1,9 +1,9 @@
 package ecs
 
 import (
+	"context"
 	"net/url"
 	"strconv"
-	"time"
 
 	"github.com/denverdino/aliyungo/common"
 	"github.com/denverdino/aliyungo/util"
@@ -302,36 +302,32 @@ func (client *Client) WaitForImageReady(regionId common.Region, imageId string,
 	if timeout <= 0 {
 		timeout = ImageDefaultTimeout
 	}
-	for {
+	// A nil result means the image is no longer creating but available
+	waiter := common.NewWaiter(timeout, DefaultWaitForInterval, func() (interface{}, error) {
 		args := DescribeImagesArgs{
 			RegionId: regionId,
 			ImageId:  imageId,
 			Status:   ImageStatusCreating,
 		}
-
 		images, _, err := client.DescribeImages(&args)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		if len(images) == 0 {
 			args.Status = ImageStatusAvailable
 			images, _, er := client.DescribeImages(&args)
 			if er == nil && len(images) == 1 {
-				break
-			} else {
-				return common.GetClientErrorFromString("Not found")
+				return nil, nil
 			}
+			return nil, common.GetClientErrorFromString("Not found")
 		}
-		if images[0].Progress == "100%" {
-			break
-		}
-		timeout = timeout - DefaultWaitForInterval
-		if timeout <= 0 {
-			return common.GetClientErrorFromString("Timeout")
-		}
-		time.Sleep(DefaultWaitForInterval * time.Second)
-	}
-	return nil
+		return &images[0], nil
+	}, func(result interface{}) bool {
+		image := result.(*ImageType)
+		return image == nil || image.Progress == "100%"
+	})
+	_, err := waiter.Wait(context.Background())
+	return err
 }
 
 type CancelCopyImageRequest struct {
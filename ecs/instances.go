@@ -1,8 +1,10 @@
 package ecs
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"net/url"
 	"strconv"
 	"time"
 
@@ -70,8 +72,9 @@ func (client *Client) DescribeUserdata(args *DescribeUserdataArgs) (userData *De
 }
 
 type DescribeInstanceStatusArgs struct {
-	RegionId common.Region
-	ZoneId   string
+	RegionId   common.Region
+	ZoneId     string
+	InstanceId []string `query:"list"` // optional, up to 100 instances
 	common.Pagination
 }
 
@@ -349,6 +352,17 @@ type Ipv6SetType struct {
 	Ipv6Address string
 }
 
+// Ipv6Addresses returns the IPv6 addresses of the network interfaces of the instance
+func (instance *InstanceAttributesType) Ipv6Addresses() []string {
+	var addresses []string
+	for _, ni := range instance.NetworkInterfaces.NetworkInterface {
+		for _, ipv6 := range ni.Ipv6Sets.Ipv6Set {
+			addresses = append(addresses, ipv6.Ipv6Address)
+		}
+	}
+	return addresses
+}
+
 type PrivateIpSetType struct {
 	Primary          bool
 	PrivateIpAddress string
@@ -407,24 +421,17 @@ func (client *Client) WaitForInstance(instanceId string, status InstanceStatus,
 	if timeout <= 0 {
 		timeout = InstanceDefaultTimeout
 	}
-	for {
-		instance, err := client.DescribeInstanceAttribute(instanceId)
-		if err != nil {
-			return err
-		}
-		if instance.Status == status {
-			//TODO
-			//Sleep one more time for timing issues
-			time.Sleep(DefaultWaitForInterval * time.Second)
-			break
-		}
-		timeout = timeout - DefaultWaitForInterval
-		if timeout <= 0 {
-			return common.GetClientErrorFromString("Timeout")
-		}
-		time.Sleep(DefaultWaitForInterval * time.Second)
-
+	waiter := common.NewWaiter(timeout, DefaultWaitForInterval, func() (interface{}, error) {
+		return client.DescribeInstanceAttribute(instanceId)
+	}, func(result interface{}) bool {
+		return result.(*InstanceAttributesType).Status == status
+	})
+	if _, err := waiter.Wait(context.Background()); err != nil {
+		return err
 	}
+	//TODO
+	//Sleep one more time for timing issues
+	time.Sleep(DefaultWaitForInterval * time.Second)
 	return nil
 }
 
@@ -434,25 +441,15 @@ func (client *Client) WaitForInstanceAsyn(instanceId string, status InstanceStat
 	if timeout <= 0 {
 		timeout = InstanceDefaultTimeout
 	}
-	for {
-		instance, err := client.DescribeInstanceAttribute(instanceId)
-		if err != nil {
-			e, _ := err.(*common.Error)
-			if e.Code != "InvalidInstanceId.NotFound" && e.Code != "Forbidden.InstanceNotFound" {
-				return err
-			}
-		} else if instance != nil && instance.Status == status {
-			//TODO
-			break
-		}
-		timeout = timeout - DefaultWaitForInterval
-		if timeout <= 0 {
-			return common.GetClientErrorFromString("Timeout")
-		}
-		time.Sleep(DefaultWaitForInterval * time.Second)
-
-	}
-	return nil
+	waiter := common.NewWaiter(timeout, DefaultWaitForInterval, func() (interface{}, error) {
+		return client.DescribeInstanceAttribute(instanceId)
+	}, func(result interface{}) bool {
+		instance := result.(*InstanceAttributesType)
+		return instance != nil && instance.Status == status
+	})
+	waiter.Retryable = common.IsErrorCodeFunc("InvalidInstanceId.NotFound", "Forbidden.InstanceNotFound")
+	_, err := waiter.Wait(context.Background())
+	return err
 }
 
 type DescribeInstanceVncUrlArgs struct {
@@ -740,14 +737,21 @@ func (client *Client) CreateInstance(args *CreateInstanceArgs) (instanceId strin
 	return response.InstanceId, err
 }
 
+// RunInstanceArgs creates instances from the launch template given by
+// LaunchTemplateId or LaunchTemplateName when set, the arguments of
+// CreateInstanceArgs then override the ones of the template, except for the
+// bool and float arguments left to false and 0
 type RunInstanceArgs struct {
 	CreateInstanceArgs
-	MinAmount       int
-	MaxAmount       int
-	AutoReleaseTime string
-	NetworkType     string
-	InnerIpAddress  string
-	BusinessInfo    string
+	MinAmount             int
+	MaxAmount             int
+	AutoReleaseTime       string
+	NetworkType           string
+	InnerIpAddress        string
+	BusinessInfo          string
+	LaunchTemplateId      string
+	LaunchTemplateName    string
+	LaunchTemplateVersion int64 // The default version of the template when not set
 }
 
 type RunInstanceResponse struct {
@@ -764,13 +768,39 @@ type BusinessInfo struct {
 	ActivityId string `json:"activityId,omitempty"`
 }
 
+// queryValues encodes the args. The bool and float arguments are always
+// encoded, so that they are left out when unset with a launch template, not
+// to override the values of the template.
+func (args *RunInstanceArgs) queryValues() url.Values {
+	values := util.ConvertToQueryValues(args)
+	if args.LaunchTemplateId == "" && args.LaunchTemplateName == "" {
+		return values
+	}
+	if !args.PasswordInherit {
+		values.Del("PasswordInherit")
+	}
+	if !args.AutoRenew {
+		values.Del("AutoRenew")
+	}
+	if args.SpotPriceLimit == 0 {
+		values.Del("SpotPriceLimit")
+	}
+	if !args.DryRun {
+		values.Del("DryRun")
+	}
+	if !args.DeletionProtection {
+		values.Del("DeletionProtection")
+	}
+	return values
+}
+
 func (client *Client) RunInstances(args *RunInstanceArgs) (instanceIdSet []string, err error) {
 	if args.UserData != "" {
 		// Encode to base64 string
 		args.UserData = base64.StdEncoding.EncodeToString([]byte(args.UserData))
 	}
 	response := RunInstanceResponse{}
-	err = client.Invoke("RunInstances", args, &response)
+	err = client.Invoke("RunInstances", args.queryValues(), &response)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,211 @@
+package ecs
+
+import (
+	"context"
+
+	"github.com/denverdino/aliyungo/common"
+	"github.com/denverdino/aliyungo/util"
+)
+
+type Ipv6GatewayStatus string
+
+const (
+	Ipv6GatewayStatusCreating  = Ipv6GatewayStatus("Creating")
+	Ipv6GatewayStatusAvailable = Ipv6GatewayStatus("Available")
+	Ipv6GatewayStatusModifying = Ipv6GatewayStatus("Modifying")
+	Ipv6GatewayStatusDeleting  = Ipv6GatewayStatus("Deleting")
+)
+
+type CreateIpv6GatewayArgs struct {
+	RegionId    common.Region
+	VpcId       string
+	Name        string
+	Description string
+	Spec        string // Small (default) | Medium | Large
+	ClientToken string
+}
+
+type CreateIpv6GatewayResponse struct {
+	common.Response
+	Ipv6GatewayId string
+}
+
+// CreateIpv6Gateway creates the IPv6 gateway of an IPv6 VPC
+//
+// You can read doc at https://help.aliyun.com/document_detail/102214.html
+func (client *Client) CreateIpv6Gateway(args *CreateIpv6GatewayArgs) (ipv6GatewayId string, err error) {
+	response := CreateIpv6GatewayResponse{}
+	err = client.Invoke("CreateIpv6Gateway", args, &response)
+	if err != nil {
+		return "", err
+	}
+	return response.Ipv6GatewayId, nil
+}
+
+type DescribeIpv6GatewaysArgs struct {
+	RegionId      common.Region
+	Ipv6GatewayId string
+	VpcId         string
+	Name          string
+	common.Pagination
+}
+
+type Ipv6GatewayType struct {
+	Ipv6GatewayId      string
+	Name               string
+	Description        string
+	RegionId           common.Region
+	VpcId              string
+	Status             Ipv6GatewayStatus
+	BusinessStatus     string
+	Spec               string
+	InstanceChargeType string
+	CreationTime       util.ISO6801Time
+	ExpiredTime        string
+}
+
+type DescribeIpv6GatewaysResponse struct {
+	common.Response
+	common.PaginationResult
+	Ipv6Gateways struct {
+		Ipv6Gateway []Ipv6GatewayType
+	}
+}
+
+// DescribeIpv6Gateways describes IPv6 gateways
+//
+// You can read doc at https://help.aliyun.com/document_detail/102226.html
+func (client *Client) DescribeIpv6Gateways(args *DescribeIpv6GatewaysArgs) (gateways []Ipv6GatewayType, pagination *common.PaginationResult, err error) {
+	args.Validate()
+	response := DescribeIpv6GatewaysResponse{}
+	err = client.Invoke("DescribeIpv6Gateways", args, &response)
+	if err != nil {
+		return nil, nil, err
+	}
+	return response.Ipv6Gateways.Ipv6Gateway, &response.PaginationResult, nil
+}
+
+type DeleteIpv6GatewayArgs struct {
+	RegionId      common.Region
+	Ipv6GatewayId string
+}
+
+type DeleteIpv6GatewayResponse struct {
+	common.Response
+}
+
+// DeleteIpv6Gateway deletes an IPv6 gateway without IPv6 internet bandwidth
+//
+// You can read doc at https://help.aliyun.com/document_detail/102219.html
+func (client *Client) DeleteIpv6Gateway(regionId common.Region, ipv6GatewayId string) error {
+	args := DeleteIpv6GatewayArgs{
+		RegionId:      regionId,
+		Ipv6GatewayId: ipv6GatewayId,
+	}
+	response := DeleteIpv6GatewayResponse{}
+	return client.Invoke("DeleteIpv6Gateway", &args, &response)
+}
+
+// WaitForIpv6Gateway waits for the IPv6 gateway to given status
+func (client *Client) WaitForIpv6Gateway(regionId common.Region, ipv6GatewayId string, status Ipv6GatewayStatus, timeout int) error {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	args := DescribeIpv6GatewaysArgs{
+		RegionId:      regionId,
+		Ipv6GatewayId: ipv6GatewayId,
+	}
+	waiter := common.NewWaiter(timeout, DefaultWaitForInterval, func() (interface{}, error) {
+		gateways, _, err := client.DescribeIpv6Gateways(&args)
+		return gateways, err
+	}, func(result interface{}) bool {
+		gateways := result.([]Ipv6GatewayType)
+		return len(gateways) > 0 && gateways[0].Status == status
+	})
+	_, err := waiter.Wait(context.Background())
+	return err
+}
+
+type DescribeIpv6AddressesArgs struct {
+	RegionId               common.Region
+	Ipv6AddressId          string
+	Ipv6Address            string
+	VpcId                  string
+	VSwitchId              string
+	AssociatedInstanceId   string
+	AssociatedInstanceType string // EcsInstance | NetworkInterface
+	NetworkType            string // Public | Private
+	common.Pagination
+}
+
+type Ipv6InternetBandwidthType struct {
+	Ipv6InternetBandwidthId string
+	Bandwidth               int
+	InternetChargeType      common.InternetChargeType
+	BusinessStatus          string
+}
+
+type Ipv6AddressType struct {
+	Ipv6AddressId          string
+	Ipv6Address            string
+	Ipv6AddressName        string
+	Ipv6GatewayId          string
+	VpcId                  string
+	VSwitchId              string
+	AssociatedInstanceId   string
+	AssociatedInstanceType string
+	NetworkType            string
+	Status                 string
+	AllocationTime         util.ISO6801Time
+	Ipv6InternetBandwidth  Ipv6InternetBandwidthType
+}
+
+type DescribeIpv6AddressesResponse struct {
+	common.Response
+	common.PaginationResult
+	Ipv6Addresses struct {
+		Ipv6Address []Ipv6AddressType
+	}
+}
+
+// DescribeIpv6Addresses describes the IPv6 addresses of a VPC, which give the
+// Ipv6AddressId of the IPv6 addresses of the instances and network interfaces
+//
+// You can read doc at https://help.aliyun.com/document_detail/102232.html
+func (client *Client) DescribeIpv6Addresses(args *DescribeIpv6AddressesArgs) (addresses []Ipv6AddressType, pagination *common.PaginationResult, err error) {
+	args.Validate()
+	response := DescribeIpv6AddressesResponse{}
+	err = client.Invoke("DescribeIpv6Addresses", args, &response)
+	if err != nil {
+		return nil, nil, err
+	}
+	return response.Ipv6Addresses.Ipv6Address, &response.PaginationResult, nil
+}
+
+type AllocateIpv6InternetBandwidthArgs struct {
+	RegionId           common.Region
+	Ipv6GatewayId      string
+	Ipv6AddressId      string
+	Bandwidth          int                       // Mbps, 1-5000
+	InternetChargeType common.InternetChargeType // PayByTraffic (default) | PayByBandwidth
+	ClientToken        string
+}
+
+type AllocateIpv6InternetBandwidthResponse struct {
+	common.Response
+	InternetBandwidthId string
+	Ipv6AddressId       string
+}
+
+// AllocateIpv6InternetBandwidth allocates internet bandwidth to an IPv6
+// address, which is then reachable from the internet
+//
+// You can read doc at https://help.aliyun.com/document_detail/102213.html
+func (client *Client) AllocateIpv6InternetBandwidth(args *AllocateIpv6InternetBandwidthArgs) (internetBandwidthId string, err error) {
+	response := AllocateIpv6InternetBandwidthResponse{}
+	err = client.Invoke("AllocateIpv6InternetBandwidth", args, &response)
+	if err != nil {
+		return "", err
+	}
+	return response.InternetBandwidthId, nil
+}
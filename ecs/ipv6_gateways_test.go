@@ -0,0 +1,133 @@
+package ecs
+
+import (
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/denverdino/aliyungo/common"
+	"github.com/denverdino/aliyungo/util"
+)
+
+func TestCreateVSwitchIpv6CidrBlock(t *testing.T) {
+	args := CreateVSwitchArgs{VpcId: "vpc-1", CidrBlock: "172.16.0.0/24"}
+	if values := util.ConvertToQueryValues(&args); values.Get("Ipv6CidrBlock") != "" {
+		t.Errorf("Unexpected Ipv6CidrBlock %q", values.Get("Ipv6CidrBlock"))
+	}
+	block := 0
+	args.Ipv6CidrBlock = &block
+	if values := util.ConvertToQueryValues(&args); values.Get("Ipv6CidrBlock") != "0" {
+		t.Errorf("Unexpected Ipv6CidrBlock %q", values.Get("Ipv6CidrBlock"))
+	}
+
+	values := util.ConvertToQueryValues(&AssignIpv6AddressesArgs{
+		NetworkInterfaceId: "eni-1",
+		Ipv6Address:        []string{"2408:4000::1", "2408:4000::2"},
+	})
+	if values.Get("Ipv6Address.1") != "2408:4000::1" || values.Get("Ipv6Address.2") != "2408:4000::2" {
+		t.Errorf("Unexpected query %v", values)
+	}
+}
+
+func TestInstanceIpv6Addresses(t *testing.T) {
+	var instance InstanceAttributesType
+	if addresses := instance.Ipv6Addresses(); len(addresses) != 0 {
+		t.Errorf("Unexpected addresses %v", addresses)
+	}
+	for _, address := range []string{"2408:4000::1", "2408:4000::2"} {
+		var ni InstanceNetworkInterfaceType
+		ni.Ipv6Sets.Ipv6Set = []Ipv6SetType{{Ipv6Address: address}}
+		instance.NetworkInterfaces.NetworkInterface = append(instance.NetworkInterfaces.NetworkInterface, ni)
+	}
+	expected := []string{"2408:4000::1", "2408:4000::2"}
+	if addresses := instance.Ipv6Addresses(); !reflect.DeepEqual(addresses, expected) {
+		t.Errorf("Unexpected addresses %v", addresses)
+	}
+}
+
+func TestIpv6Gateways(t *testing.T) {
+	client, recorder := newOfflineTestClient(func(query url.Values) (int, interface{}) {
+		switch query.Get("Action") {
+		case "CreateIpv6Gateway":
+			return http.StatusOK, map[string]interface{}{"RequestId": "r-1", "Ipv6GatewayId": "ipv6gw-1"}
+		case "DescribeIpv6Gateways":
+			return http.StatusOK, map[string]interface{}{
+				"RequestId":  "r-2",
+				"TotalCount": 1,
+				"PageNumber": 1,
+				"PageSize":   10,
+				"Ipv6Gateways": map[string]interface{}{
+					"Ipv6Gateway": []map[string]interface{}{{
+						"Ipv6GatewayId": "ipv6gw-1",
+						"VpcId":         "vpc-1",
+						"Name":          "gw",
+						"Status":        "Available",
+						"Spec":          "Small",
+						"CreationTime":  "2021-03-17T12:00:00Z",
+					}},
+				},
+			}
+		case "AllocateIpv6InternetBandwidth":
+			return http.StatusOK, map[string]interface{}{"RequestId": "r-3", "InternetBandwidthId": "ipv6bw-1", "Ipv6AddressId": "ipv6-1"}
+		}
+		return http.StatusOK, map[string]interface{}{"RequestId": "r-4"}
+	})
+	lastQuery := func(expected map[string]string) {
+		t.Helper()
+		query := recorder.requests[len(recorder.requests)-1]
+		for key, value := range expected {
+			if query.Get(key) != value {
+				t.Errorf("Expected %s=%q, got %q", key, value, query.Get(key))
+			}
+		}
+	}
+
+	id, err := client.CreateIpv6Gateway(&CreateIpv6GatewayArgs{RegionId: "cn-hangzhou", VpcId: "vpc-1", Name: "gw", Spec: "Small"})
+	if err != nil || id != "ipv6gw-1" {
+		t.Errorf("Unexpected CreateIpv6Gateway %q: %v", id, err)
+	}
+	lastQuery(map[string]string{"Action": "CreateIpv6Gateway", "RegionId": "cn-hangzhou", "VpcId": "vpc-1", "Name": "gw", "Spec": "Small"})
+
+	gateways, pagination, err := client.DescribeIpv6Gateways(&DescribeIpv6GatewaysArgs{RegionId: "cn-hangzhou", VpcId: "vpc-1"})
+	if err != nil || len(gateways) != 1 || pagination.TotalCount != 1 {
+		t.Fatalf("Unexpected DescribeIpv6Gateways %v %v: %v", gateways, pagination, err)
+	}
+	gateway := gateways[0]
+	if gateway.Ipv6GatewayId != "ipv6gw-1" || gateway.Status != Ipv6GatewayStatusAvailable || time.Time(gateway.CreationTime).Year() != 2021 {
+		t.Errorf("Unexpected gateway %+v", gateway)
+	}
+	lastQuery(map[string]string{"Action": "DescribeIpv6Gateways", "RegionId": "cn-hangzhou", "VpcId": "vpc-1"})
+
+	if err := client.WaitForIpv6Gateway("cn-hangzhou", "ipv6gw-1", Ipv6GatewayStatusAvailable, 10); err != nil {
+		t.Errorf("Failed to wait for the IPv6 gateway: %v", err)
+	}
+	lastQuery(map[string]string{"Action": "DescribeIpv6Gateways", "Ipv6GatewayId": "ipv6gw-1"})
+	if err := client.WaitForIpv6Gateway("cn-hangzhou", "ipv6gw-1", Ipv6GatewayStatusDeleting, 1); !common.IsWaitTimeout(err) {
+		t.Errorf("Expected a timeout, got %v", err)
+	}
+
+	bandwidthId, err := client.AllocateIpv6InternetBandwidth(&AllocateIpv6InternetBandwidthArgs{
+		RegionId:           "cn-hangzhou",
+		Ipv6GatewayId:      "ipv6gw-1",
+		Ipv6AddressId:      "ipv6-1",
+		Bandwidth:          10,
+		InternetChargeType: common.PayByBandwidth,
+	})
+	if err != nil || bandwidthId != "ipv6bw-1" {
+		t.Errorf("Unexpected AllocateIpv6InternetBandwidth %q: %v", bandwidthId, err)
+	}
+	lastQuery(map[string]string{
+		"Action":             "AllocateIpv6InternetBandwidth",
+		"Ipv6GatewayId":      "ipv6gw-1",
+		"Ipv6AddressId":      "ipv6-1",
+		"Bandwidth":          "10",
+		"InternetChargeType": "PayByBandwidth",
+	})
+
+	if err := client.DeleteIpv6Gateway("cn-hangzhou", "ipv6gw-1"); err != nil {
+		t.Errorf("Failed to delete the IPv6 gateway: %v", err)
+	}
+	lastQuery(map[string]string{"Action": "DeleteIpv6Gateway", "RegionId": "cn-hangzhou", "Ipv6GatewayId": "ipv6gw-1"})
+}
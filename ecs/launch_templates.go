@@ -0,0 +1,345 @@
+package ecs
+
+import (
+	"encoding/base64"
+
+	"github.com/denverdino/aliyungo/common"
+	"github.com/denverdino/aliyungo/util"
+)
+
+// LaunchTemplateData is the configuration of the instances stored in a launch template version
+type LaunchTemplateData struct {
+	ImageId                     string
+	ImageOwnerAlias             string
+	InstanceType                string
+	SecurityGroupId             string
+	SecurityGroupIds            []string `query:"list"`
+	VpcId                       string
+	VSwitchId                   string
+	ZoneId                      string
+	InstanceName                string
+	Description                 string
+	HostName                    string
+	InternetChargeType          common.InternetChargeType
+	InternetMaxBandwidthIn      int
+	InternetMaxBandwidthOut     int
+	IoOptimized                 IoOptimized
+	NetworkType                 string
+	InstanceChargeType          common.InstanceChargeType
+	Period                      int
+	SystemDisk                  SystemDiskType
+	DataDisk                    []DataDiskType
+	UserData                    string // Plain text user data, encoded to Base64 when the template is created
+	KeyPairName                 string
+	RamRoleName                 string
+	AutoReleaseTime             string
+	SpotStrategy                SpotStrategyType
+	SpotPriceLimit              float64
+	SpotDuration                *int
+	SecurityEnhancementStrategy SecurityEnhancementStrategy
+	PrivateIpAddress            string
+	ResourceGroupId             string
+	DeploymentSetId             string
+	CreditSpecification         string
+	PasswordInherit             bool
+	Tag                         []TagType
+}
+
+func (data *LaunchTemplateData) encodeUserData() {
+	if data.UserData != "" {
+		// Encode to base64 string
+		data.UserData = base64.StdEncoding.EncodeToString([]byte(data.UserData))
+	}
+}
+
+// LaunchTemplateData converts the arguments of CreateInstance into the data
+// of a launch template. The arguments without a launch template counterpart,
+// such as Password or ClientToken, are dropped.
+func (args *CreateInstanceArgs) LaunchTemplateData() LaunchTemplateData {
+	return LaunchTemplateData{
+		ImageId:                     args.ImageId,
+		InstanceType:                args.InstanceType,
+		SecurityGroupId:             args.SecurityGroupId,
+		VSwitchId:                   args.VSwitchId,
+		ZoneId:                      args.ZoneId,
+		InstanceName:                args.InstanceName,
+		Description:                 args.Description,
+		HostName:                    args.HostName,
+		InternetChargeType:          args.InternetChargeType,
+		InternetMaxBandwidthIn:      args.InternetMaxBandwidthIn,
+		InternetMaxBandwidthOut:     args.InternetMaxBandwidthOut,
+		IoOptimized:                 args.IoOptimized,
+		InstanceChargeType:          args.InstanceChargeType,
+		Period:                      args.Period,
+		SystemDisk:                  args.SystemDisk,
+		DataDisk:                    args.DataDisk,
+		UserData:                    args.UserData,
+		KeyPairName:                 args.KeyPairName,
+		RamRoleName:                 args.RamRoleName,
+		SpotStrategy:                args.SpotStrategy,
+		SpotPriceLimit:              args.SpotPriceLimit,
+		SpotDuration:                args.SpotDuration,
+		SecurityEnhancementStrategy: args.SecurityEnhancementStrategy,
+		PrivateIpAddress:            args.PrivateIpAddress,
+		ResourceGroupId:             args.ResourceGroupId,
+		DeploymentSetId:             args.DeploymentSetId,
+		CreditSpecification:         args.CreditSpecification,
+		PasswordInherit:             args.PasswordInherit,
+		Tag:                         args.Tag,
+	}
+}
+
+type TemplateTagType struct {
+	Key   string
+	Value string
+}
+
+type CreateLaunchTemplateArgs struct {
+	RegionId                common.Region
+	LaunchTemplateName      string
+	VersionDescription      string
+	TemplateResourceGroupId string
+	TemplateTag             []TemplateTagType
+	LaunchTemplateData
+}
+
+type CreateLaunchTemplateResponse struct {
+	common.Response
+	LaunchTemplateId            string
+	LaunchTemplateVersionNumber int64
+}
+
+// CreateLaunchTemplate creates a launch template with its first version
+//
+// You can read doc at https://help.aliyun.com/document_detail/74686.html
+func (client *Client) CreateLaunchTemplate(args *CreateLaunchTemplateArgs) (response *CreateLaunchTemplateResponse, err error) {
+	encoded := *args
+	encoded.encodeUserData()
+	response = &CreateLaunchTemplateResponse{}
+	err = client.Invoke("CreateLaunchTemplate", &encoded, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+type CreateLaunchTemplateVersionArgs struct {
+	RegionId           common.Region
+	LaunchTemplateId   string
+	LaunchTemplateName string
+	VersionDescription string
+	LaunchTemplateData
+}
+
+type CreateLaunchTemplateVersionResponse struct {
+	common.Response
+	LaunchTemplateVersionNumber int64
+}
+
+// CreateLaunchTemplateVersion creates a version of the launch template given by id or name
+//
+// You can read doc at https://help.aliyun.com/document_detail/74687.html
+func (client *Client) CreateLaunchTemplateVersion(args *CreateLaunchTemplateVersionArgs) (versionNumber int64, err error) {
+	encoded := *args
+	encoded.encodeUserData()
+	response := CreateLaunchTemplateVersionResponse{}
+	err = client.Invoke("CreateLaunchTemplateVersion", &encoded, &response)
+	if err != nil {
+		return 0, err
+	}
+	return response.LaunchTemplateVersionNumber, nil
+}
+
+type DescribeLaunchTemplatesArgs struct {
+	RegionId                common.Region
+	LaunchTemplateId        []string `query:"list"`
+	LaunchTemplateName      []string `query:"list"`
+	TemplateResourceGroupId string
+	TemplateTag             []TemplateTagType
+	common.Pagination
+}
+
+type LaunchTemplateSetType struct {
+	LaunchTemplateId     string
+	LaunchTemplateName   string
+	DefaultVersionNumber int64
+	LatestVersionNumber  int64
+	CreatedBy            string
+	CreateTime           util.ISO6801Time
+	ModifiedTime         util.ISO6801Time
+	ResourceGroupId      string
+	Tags                 struct {
+		Tag []TagItemType
+	}
+}
+
+type DescribeLaunchTemplatesResponse struct {
+	common.Response
+	common.PaginationResult
+	LaunchTemplateSets struct {
+		LaunchTemplateSet []LaunchTemplateSetType
+	}
+}
+
+// DescribeLaunchTemplates describes launch templates
+//
+// You can read doc at https://help.aliyun.com/document_detail/73759.html
+func (client *Client) DescribeLaunchTemplates(args *DescribeLaunchTemplatesArgs) (templates []LaunchTemplateSetType, pagination *common.PaginationResult, err error) {
+	args.Validate()
+	response := DescribeLaunchTemplatesResponse{}
+	err = client.Invoke("DescribeLaunchTemplates", args, &response)
+	if err != nil {
+		return nil, nil, err
+	}
+	return response.LaunchTemplateSets.LaunchTemplateSet, &response.PaginationResult, nil
+}
+
+type DescribeLaunchTemplateVersionsArgs struct {
+	RegionId              common.Region
+	LaunchTemplateId      string
+	LaunchTemplateName    string
+	LaunchTemplateVersion []string `query:"list"`
+	MinVersion            int64
+	MaxVersion            int64
+	DefaultVersion        *bool
+	DetailFlag            *bool // Whether to return the LaunchTemplateData, true by default
+	common.Pagination
+}
+
+// LaunchTemplateDataType is the LaunchTemplateData of a launch template
+// version returned by DescribeLaunchTemplateVersions
+type LaunchTemplateDataType struct {
+	ImageId          string
+	ImageOwnerAlias  string
+	InstanceType     string
+	SecurityGroupId  string
+	SecurityGroupIds struct {
+		SecurityGroupId []string
+	}
+	VpcId                       string
+	VSwitchId                   string
+	ZoneId                      string
+	InstanceName                string
+	Description                 string
+	HostName                    string
+	InternetChargeType          common.InternetChargeType
+	InternetMaxBandwidthIn      int
+	InternetMaxBandwidthOut     int
+	IoOptimized                 IoOptimized
+	NetworkType                 string
+	InstanceChargeType          common.InstanceChargeType
+	Period                      int
+	SystemDiskCategory          DiskCategory         `json:"SystemDisk.Category"`
+	SystemDiskSize              int                  `json:"SystemDisk.Size"`
+	SystemDiskName              string               `json:"SystemDisk.DiskName"`
+	SystemDiskDescription       string               `json:"SystemDisk.Description"`
+	SystemDiskPerformanceLevel  DiskPerformanceLevel `json:"SystemDisk.PerformanceLevel"`
+	UserData                    string               // Base64 encoded user data
+	KeyPairName                 string
+	RamRoleName                 string
+	AutoReleaseTime             string
+	SpotStrategy                SpotStrategyType
+	SpotPriceLimit              float64
+	SpotDuration                int
+	SecurityEnhancementStrategy SecurityEnhancementStrategy
+	PrivateIpAddress            string
+	ResourceGroupId             string
+	DeploymentSetId             string
+	CreditSpecification         string
+	PasswordInherit             bool
+	DataDisks                   struct {
+		DataDisk []DataDiskType
+	}
+	Tags struct {
+		InstanceTag []TagType
+	}
+}
+
+type LaunchTemplateVersionSetType struct {
+	LaunchTemplateId   string
+	LaunchTemplateName string
+	VersionNumber      int64
+	VersionDescription string
+	DefaultVersion     bool
+	CreatedBy          string
+	CreateTime         util.ISO6801Time
+	ModifiedTime       util.ISO6801Time
+	LaunchTemplateData LaunchTemplateDataType
+}
+
+type DescribeLaunchTemplateVersionsResponse struct {
+	common.Response
+	common.PaginationResult
+	LaunchTemplateVersionSets struct {
+		LaunchTemplateVersionSet []LaunchTemplateVersionSetType
+	}
+}
+
+// DescribeLaunchTemplateVersions describes the versions of a launch template
+//
+// You can read doc at https://help.aliyun.com/document_detail/73761.html
+func (client *Client) DescribeLaunchTemplateVersions(args *DescribeLaunchTemplateVersionsArgs) (versions []LaunchTemplateVersionSetType, pagination *common.PaginationResult, err error) {
+	args.Validate()
+	response := DescribeLaunchTemplateVersionsResponse{}
+	err = client.Invoke("DescribeLaunchTemplateVersions", args, &response)
+	if err != nil {
+		return nil, nil, err
+	}
+	return response.LaunchTemplateVersionSets.LaunchTemplateVersionSet, &response.PaginationResult, nil
+}
+
+type ModifyLaunchTemplateDefaultVersionArgs struct {
+	RegionId             common.Region
+	LaunchTemplateId     string
+	LaunchTemplateName   string
+	DefaultVersionNumber int64
+}
+
+type ModifyLaunchTemplateDefaultVersionResponse struct {
+	common.Response
+}
+
+// ModifyLaunchTemplateDefaultVersion sets the default version of a launch template
+//
+// You can read doc at https://help.aliyun.com/document_detail/74688.html
+func (client *Client) ModifyLaunchTemplateDefaultVersion(args *ModifyLaunchTemplateDefaultVersionArgs) error {
+	response := ModifyLaunchTemplateDefaultVersionResponse{}
+	return client.Invoke("ModifyLaunchTemplateDefaultVersion", args, &response)
+}
+
+type DeleteLaunchTemplateArgs struct {
+	RegionId           common.Region
+	LaunchTemplateId   string
+	LaunchTemplateName string
+}
+
+type DeleteLaunchTemplateResponse struct {
+	common.Response
+}
+
+// DeleteLaunchTemplate deletes a launch template with all its versions
+//
+// You can read doc at https://help.aliyun.com/document_detail/73764.html
+func (client *Client) DeleteLaunchTemplate(args *DeleteLaunchTemplateArgs) error {
+	response := DeleteLaunchTemplateResponse{}
+	return client.Invoke("DeleteLaunchTemplate", args, &response)
+}
+
+type DeleteLaunchTemplateVersionArgs struct {
+	RegionId           common.Region
+	LaunchTemplateId   string
+	LaunchTemplateName string
+	DeleteVersion      []string `query:"list"`
+}
+
+type DeleteLaunchTemplateVersionResponse struct {
+	common.Response
+}
+
+// DeleteLaunchTemplateVersion deletes versions of a launch template, the default version cannot be deleted
+//
+// You can read doc at https://help.aliyun.com/document_detail/73765.html
+func (client *Client) DeleteLaunchTemplateVersion(args *DeleteLaunchTemplateVersionArgs) error {
+	response := DeleteLaunchTemplateVersionResponse{}
+	return client.Invoke("DeleteLaunchTemplateVersion", args, &response)
+}
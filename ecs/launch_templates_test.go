@@ -0,0 +1,107 @@
+package ecs
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/denverdino/aliyungo/util"
+)
+
+func TestLaunchTemplateData(t *testing.T) {
+	instanceArgs := CreateInstanceArgs{
+		RegionId:     "cn-hangzhou",
+		ImageId:      "ubuntu_18_04_64_20G_alibase_20190624.vhd",
+		InstanceType: "ecs.t5-lc1m1.small",
+		Password:     "secret",
+		SystemDisk: SystemDiskType{
+			Category: DiskCategoryCloudEfficiency,
+			Size:     40,
+		},
+		DataDisk: []DataDiskType{{Size: 100}},
+		Tag:      []TagType{{Key: "env", Value: "test"}},
+	}
+	args := CreateLaunchTemplateArgs{
+		RegionId:           "cn-hangzhou",
+		LaunchTemplateName: "test",
+		LaunchTemplateData: instanceArgs.LaunchTemplateData(),
+	}
+
+	values := util.ConvertToQueryValues(&args)
+	expected := map[string]string{
+		"LaunchTemplateName":  "test",
+		"ImageId":             instanceArgs.ImageId,
+		"InstanceType":        instanceArgs.InstanceType,
+		"SystemDisk.Category": string(DiskCategoryCloudEfficiency),
+		"SystemDisk.Size":     "40",
+		"DataDisk.1.Size":     "100",
+		"Tag.1.Key":           "env",
+		"Tag.1.Value":         "test",
+		"Password":            "",
+	}
+	for key, value := range expected {
+		if values.Get(key) != value {
+			t.Errorf("Unexpected %s: %q", key, values.Get(key))
+		}
+	}
+}
+
+func TestRunInstanceArgsWithLaunchTemplate(t *testing.T) {
+	args := &RunInstanceArgs{
+		CreateInstanceArgs: CreateInstanceArgs{
+			RegionId:   "cn-hangzhou",
+			AutoRenew:  true,
+			SystemDisk: SystemDiskType{Size: 40},
+		},
+		LaunchTemplateId: "lt-1",
+		MaxAmount:        2,
+	}
+	values := args.queryValues()
+	for _, name := range []string{"PasswordInherit", "SpotPriceLimit", "DryRun", "DeletionProtection"} {
+		if _, ok := values[name]; ok {
+			t.Errorf("Unexpected %s=%q overriding the template", name, values.Get(name))
+		}
+	}
+	if values.Get("AutoRenew") != "true" || values.Get("LaunchTemplateId") != "lt-1" || values.Get("MaxAmount") != "2" {
+		t.Errorf("Unexpected query %v", values)
+	}
+
+	args.LaunchTemplateId = ""
+	values = args.queryValues()
+	if values.Get("DryRun") != "false" || values.Get("SpotPriceLimit") != "0.0000" {
+		t.Errorf("Expected the arguments to be sent without a template, got %v", values)
+	}
+}
+
+func TestCreateLaunchTemplateUserData(t *testing.T) {
+	client, recorder := newOfflineTestClient(func(query url.Values) (int, interface{}) {
+		return http.StatusOK, CreateLaunchTemplateResponse{LaunchTemplateId: "lt-1", LaunchTemplateVersionNumber: 1}
+	})
+	args := &CreateLaunchTemplateArgs{
+		RegionId:           "cn-hangzhou",
+		LaunchTemplateName: "test",
+		LaunchTemplateData: LaunchTemplateData{UserData: "#!/bin/sh"},
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := client.CreateLaunchTemplate(args); err != nil {
+			t.Fatalf("Failed to create the launch template: %v", err)
+		}
+	}
+	versionArgs := &CreateLaunchTemplateVersionArgs{
+		RegionId:           "cn-hangzhou",
+		LaunchTemplateId:   "lt-1",
+		LaunchTemplateData: LaunchTemplateData{UserData: "#!/bin/sh"},
+	}
+	if _, err := client.CreateLaunchTemplateVersion(versionArgs); err != nil {
+		t.Fatalf("Failed to create the launch template version: %v", err)
+	}
+
+	if args.UserData != "#!/bin/sh" || versionArgs.UserData != "#!/bin/sh" {
+		t.Errorf("Unexpected user data %q and %q changed in the args", args.UserData, versionArgs.UserData)
+	}
+	for _, query := range recorder.requests {
+		if query.Get("UserData") != "IyEvYmluL3No" {
+			t.Errorf("Unexpected %s UserData %q", query.Get("Action"), query.Get("UserData"))
+		}
+	}
+}
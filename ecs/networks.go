@@ -3,7 +3,7 @@
 package ecs
 
 import (
-	"time"
+	"context"
 
 	"github.com/denverdino/aliyungo/common"
 	"github.com/denverdino/aliyungo/util"
@@ -287,22 +287,18 @@ func (client *Client) WaitForEip(regionId common.Region, allocationId string, st
 		RegionId:     regionId,
 		AllocationId: allocationId,
 	}
-	for {
+	waiter := common.NewWaiter(timeout, DefaultWaitForInterval, func() (interface{}, error) {
 		eips, _, err := client.DescribeEipAddresses(&args)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		if len(eips) == 0 {
-			return common.GetClientErrorFromString("Not found")
+			return nil, common.GetClientErrorFromString("Not found")
 		}
-		if eips[0].Status == status {
-			break
-		}
-		timeout = timeout - DefaultWaitForInterval
-		if timeout <= 0 {
-			return common.GetClientErrorFromString("Timeout")
-		}
-		time.Sleep(DefaultWaitForInterval * time.Second)
-	}
-	return nil
+		return eips[0], nil
+	}, func(result interface{}) bool {
+		return result.(EipAddressSetType).Status == status
+	})
+	_, err := waiter.Wait(context.Background())
+	return err
 }
@@ -0,0 +1,123 @@
+package ecs
+
+import (
+	"github.com/denverdino/aliyungo/common"
+	"github.com/denverdino/aliyungo/util"
+)
+
+// Match criteria of PrivatePoolOptionsType
+const (
+	PrivatePoolMatchOpen   = "Open"   // Use the matching open private pools first
+	PrivatePoolMatchTarget = "Target" // Use the private pool given by Id
+	PrivatePoolMatchNone   = "None"   // Do not use private pools
+)
+
+type PrivatePoolStatus string
+
+const (
+	PrivatePoolPreparing = PrivatePoolStatus("Preparing")
+	PrivatePoolPrepared  = PrivatePoolStatus("Prepared")
+	PrivatePoolActive    = PrivatePoolStatus("Active")
+	PrivatePoolReleased  = PrivatePoolStatus("Released")
+)
+
+type DescribePrivatePoolsArgs struct {
+	RegionId        common.Region
+	PrivatePoolIds  []string `ArgName:"PrivatePoolOptions.Ids"` // Encoded to a JSON array
+	Status          PrivatePoolStatus
+	ZoneId          string
+	InstanceType    string
+	ResourceGroupId string
+	NextToken       string
+	MaxResults      int
+}
+
+type AllocatedResourceType struct {
+	ZoneId       string
+	InstanceType string
+	TotalAmount  int
+	UsedAmount   int
+}
+
+// PrivatePoolType is the private pool of an elasticity assurance or a capacity reservation
+type PrivatePoolType struct {
+	PrivatePoolOptionsId            string
+	PrivatePoolOptionsName          string
+	PrivatePoolOptionsMatchCriteria string
+	Description                     string
+	Status                          PrivatePoolStatus
+	RegionId                        common.Region
+	ResourceGroupId                 string
+	StartTime                       util.ISO6801Time
+	EndTime                         util.ISO6801Time
+	AllocatedResources              struct {
+		AllocatedResource []AllocatedResourceType
+	}
+}
+
+// PrivatePoolOptions returns the options of CreateInstanceArgs targeting the private pool
+func (pool *PrivatePoolType) PrivatePoolOptions() PrivatePoolOptionsType {
+	return PrivatePoolOptionsType{
+		MatchCriteria: PrivatePoolMatchTarget,
+		Id:            pool.PrivatePoolOptionsId,
+	}
+}
+
+type ElasticityAssuranceType struct {
+	PrivatePoolType
+	TotalAssuranceTimes string // "Unlimited" or a number of times
+	UsedAssuranceTimes  int
+}
+
+type DescribeElasticityAssurancesResponse struct {
+	common.Response
+	NextToken              string
+	MaxResults             int
+	TotalCount             int
+	ElasticityAssuranceSet struct {
+		ElasticityAssuranceItem []ElasticityAssuranceType
+	}
+}
+
+// DescribeElasticityAssurances describes the elasticity assurances, nextToken
+// is the NextToken of the next page or empty for the last page
+//
+// You can read doc at https://help.aliyun.com/document_detail/193628.html
+func (client *Client) DescribeElasticityAssurances(args *DescribePrivatePoolsArgs) (assurances []ElasticityAssuranceType, nextToken string, err error) {
+	response := DescribeElasticityAssurancesResponse{}
+	err = client.Invoke("DescribeElasticityAssurances", args, &response)
+	if err != nil {
+		return nil, "", err
+	}
+	return response.ElasticityAssuranceSet.ElasticityAssuranceItem, response.NextToken, nil
+}
+
+type CapacityReservationType struct {
+	PrivatePoolType
+	EndTimeType        string // Limited | Unlimited
+	InstanceChargeType common.InstanceChargeType
+	Platform           string
+}
+
+type DescribeCapacityReservationsResponse struct {
+	common.Response
+	NextToken              string
+	MaxResults             int
+	TotalCount             int
+	CapacityReservationSet struct {
+		CapacityReservationItem []CapacityReservationType
+	}
+}
+
+// DescribeCapacityReservations describes the capacity reservations, nextToken
+// is the NextToken of the next page or empty for the last page
+//
+// You can read doc at https://help.aliyun.com/document_detail/193629.html
+func (client *Client) DescribeCapacityReservations(args *DescribePrivatePoolsArgs) (reservations []CapacityReservationType, nextToken string, err error) {
+	response := DescribeCapacityReservationsResponse{}
+	err = client.Invoke("DescribeCapacityReservations", args, &response)
+	if err != nil {
+		return nil, "", err
+	}
+	return response.CapacityReservationSet.CapacityReservationItem, response.NextToken, nil
+}
@@ -0,0 +1,17 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/denverdino/aliyungo/util"
+)
+
+func TestDescribePrivatePoolsArgs(t *testing.T) {
+	values := util.ConvertToQueryValues(&DescribePrivatePoolsArgs{
+		RegionId:       "cn-hangzhou",
+		PrivatePoolIds: []string{"eap-1", "eap-2"},
+	})
+	if ids := values.Get("PrivatePoolOptions.Ids"); ids != `["eap-1","eap-2"]` {
+		t.Errorf("Unexpected PrivatePoolOptions.Ids %q", ids)
+	}
+}
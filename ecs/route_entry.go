@@ -29,6 +29,7 @@ type RouteEntry struct {
 	IpVersion            string
 	RouteEntryId         string
 	RouteEntryName       string
+	Description          string
 	RouteTableId         string
 	Status               string
 	Type                 string
@@ -0,0 +1,257 @@
+package ecs
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/denverdino/aliyungo/common"
+)
+
+// RouteEntrySpec is a desired custom route entry of a route table
+type RouteEntrySpec struct {
+	DestinationCidrBlock string
+	NextHopType          NextHopType // Instance by default
+	NextHopId            string
+	RouteEntryName       string
+	Description          string
+}
+
+// RouteEntryPlan is the changes bringing the custom route entries of a route
+// table to a desired set. The entries with a single next hop whose next hop,
+// name or description differs are modified in place, the others are replaced:
+// they are both in Delete and in Create.
+type RouteEntryPlan struct {
+	Create []RouteEntrySpec
+	Modify []ModifyRouteEntryArgs
+	Delete []RouteEntry
+}
+
+func (plan *RouteEntryPlan) IsEmpty() bool {
+	return len(plan.Create) == 0 && len(plan.Modify) == 0 && len(plan.Delete) == 0
+}
+
+// String returns the plan with one line per entry, "+" for the entries to
+// create, "~" for the entries to modify and "-" for the entries to delete
+func (plan *RouteEntryPlan) String() string {
+	var buf bytes.Buffer
+	for _, e := range plan.Create {
+		fmt.Fprintf(&buf, "+ %s via %s %s\n", e.DestinationCidrBlock, routeEntryNextHopType(e), e.NextHopId)
+	}
+	for _, e := range plan.Modify {
+		fmt.Fprintf(&buf, "~ %s", e.RouteEntryId)
+		if e.NewNextHopId != "" {
+			fmt.Fprintf(&buf, " via %s %s", e.NewNextHopType, e.NewNextHopId)
+		}
+		if e.RouteEntryName != "" {
+			fmt.Fprintf(&buf, " name %q", e.RouteEntryName)
+		}
+		if e.Description != "" {
+			fmt.Fprintf(&buf, " description %q", e.Description)
+		}
+		buf.WriteString("\n")
+	}
+	for _, e := range plan.Delete {
+		var hops []string
+		for _, hop := range e.NextHops.NextHop {
+			hops = append(hops, hop.NextHopType+" "+hop.NextHopId)
+		}
+		fmt.Fprintf(&buf, "- %s via %s\n", e.DestinationCidrBlock, strings.Join(hops, ", "))
+	}
+	return buf.String()
+}
+
+func routeEntryNextHopType(spec RouteEntrySpec) NextHopType {
+	if spec.NextHopType == "" {
+		return NextHopInstance
+	}
+	return spec.NextHopType
+}
+
+// diffRouteEntries returns the plan bringing the current custom entries to
+// the desired ones, which are identified by their destination CIDR blocks.
+// The names and descriptions are only compared when desired, as they cannot
+// be cleared. It fails when different entries are desired for the same CIDR
+// block.
+func diffRouteEntries(current []RouteEntry, desired []RouteEntrySpec) (*RouteEntryPlan, error) {
+	plan := &RouteEntryPlan{}
+	existing := make(map[string]RouteEntry)
+	for _, e := range current {
+		existing[e.DestinationCidrBlock] = e
+	}
+	wanted := make(map[string]RouteEntrySpec)
+	for _, spec := range desired {
+		spec.NextHopType = routeEntryNextHopType(spec)
+		if other, ok := wanted[spec.DestinationCidrBlock]; ok {
+			if other != spec {
+				return nil, fmt.Errorf("conflicting route entries for %s: via %s %s and via %s %s",
+					spec.DestinationCidrBlock, other.NextHopType, other.NextHopId, spec.NextHopType, spec.NextHopId)
+			}
+			continue
+		}
+		wanted[spec.DestinationCidrBlock] = spec
+		nextHopType := spec.NextHopType
+
+		e, ok := existing[spec.DestinationCidrBlock]
+		if !ok {
+			plan.Create = append(plan.Create, spec)
+			continue
+		}
+		hops := e.NextHops.NextHop
+		if len(hops) == 1 {
+			modify := ModifyRouteEntryArgs{RouteEntryId: e.RouteEntryId}
+			if hops[0].NextHopType != string(nextHopType) || hops[0].NextHopId != spec.NextHopId {
+				modify.NewNextHopType = nextHopType
+				modify.NewNextHopId = spec.NextHopId
+			}
+			if spec.RouteEntryName != "" && spec.RouteEntryName != e.RouteEntryName {
+				modify.RouteEntryName = spec.RouteEntryName
+			}
+			if spec.Description != "" && spec.Description != e.Description {
+				modify.Description = spec.Description
+			}
+			if modify != (ModifyRouteEntryArgs{RouteEntryId: e.RouteEntryId}) {
+				plan.Modify = append(plan.Modify, modify)
+			}
+			continue
+		}
+		plan.Delete = append(plan.Delete, e)
+		plan.Create = append(plan.Create, spec)
+	}
+	for _, e := range current {
+		if _, ok := wanted[e.DestinationCidrBlock]; !ok {
+			plan.Delete = append(plan.Delete, e)
+		}
+	}
+	return plan, nil
+}
+
+type ReconcileRouteEntriesArgs struct {
+	RegionId     common.Region
+	VRouterId    string // VRouter of the route table, used to wait for the entries
+	RouteTableId string
+	Entries      []RouteEntrySpec
+	DryRun       bool // Only return the plan
+	Timeout      int  // Timeout in seconds of each change, DefaultTimeout by default
+}
+
+// ReconcileRouteEntries brings the custom route entries of a route table to
+// the desired ones and returns the plan. The system entries are left
+// untouched. A route table accepts one change at a time, so that the changes
+// are applied one by one, waiting for all the entries to be available after
+// each change. The changes which keep the routes up come first: the new
+// entries are created and the entries are modified, then each replaced entry
+// is deleted and created again at once, and the entries which are no longer
+// desired are deleted last. ReconcileRouteEntries stops at the first failure,
+// so that no entry is deleted when a new entry cannot be created.
+func (client *Client) ReconcileRouteEntries(args *ReconcileRouteEntriesArgs) (*RouteEntryPlan, error) {
+	current, err := client.describeCustomRouteEntries(args.RegionId, args.RouteTableId)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := diffRouteEntries(current, args.Entries)
+	if err != nil {
+		return nil, err
+	}
+	if args.DryRun || plan.IsEmpty() {
+		return plan, nil
+	}
+
+	wait := func() error {
+		return client.WaitForAllRouteEntriesAvailable(args.VRouterId, args.RouteTableId, args.Timeout)
+	}
+	create := func(spec RouteEntrySpec) error {
+		err := client.CreateRouteEntry(&CreateRouteEntryArgs{
+			RouteTableId:         args.RouteTableId,
+			DestinationCidrBlock: spec.DestinationCidrBlock,
+			NextHopType:          routeEntryNextHopType(spec),
+			NextHopId:            spec.NextHopId,
+			RouteEntryName:       spec.RouteEntryName,
+			Description:          spec.Description,
+		})
+		if err != nil {
+			return err
+		}
+		return wait()
+	}
+	remove := func(e RouteEntry) error {
+		err := client.DeleteRouteEntry(&DeleteRouteEntryArgs{
+			RouteTableId:         args.RouteTableId,
+			DestinationCidrBlock: e.DestinationCidrBlock,
+			RouteEntryId:         e.RouteEntryId,
+		})
+		if err != nil {
+			return err
+		}
+		return wait()
+	}
+
+	if err := wait(); err != nil {
+		return plan, err
+	}
+	deleted := make(map[string]bool)
+	for _, e := range plan.Delete {
+		deleted[e.DestinationCidrBlock] = true
+	}
+	replacements := make(map[string]RouteEntrySpec)
+	for _, spec := range plan.Create {
+		if deleted[spec.DestinationCidrBlock] {
+			replacements[spec.DestinationCidrBlock] = spec
+			continue
+		}
+		if err := create(spec); err != nil {
+			return plan, fmt.Errorf("failed to create route entry %s: %v", spec.DestinationCidrBlock, err)
+		}
+	}
+	for _, modify := range plan.Modify {
+		modify.RegionId = args.RegionId
+		if err := client.ModifyRouteEntry(&modify); err != nil {
+			return plan, fmt.Errorf("failed to modify route entry %s: %v", modify.RouteEntryId, err)
+		}
+		if err := wait(); err != nil {
+			return plan, err
+		}
+	}
+	for _, e := range plan.Delete {
+		spec, ok := replacements[e.DestinationCidrBlock]
+		if !ok {
+			continue
+		}
+		if err := remove(e); err != nil {
+			return plan, fmt.Errorf("failed to delete route entry %s: %v", e.DestinationCidrBlock, err)
+		}
+		if err := create(spec); err != nil {
+			return plan, fmt.Errorf("route entry %s %s was deleted but its replacement failed: %v", e.DestinationCidrBlock, e.RouteEntryId, err)
+		}
+	}
+	for _, e := range plan.Delete {
+		if _, ok := replacements[e.DestinationCidrBlock]; ok {
+			continue
+		}
+		if err := remove(e); err != nil {
+			return plan, fmt.Errorf("failed to delete route entry %s: %v", e.DestinationCidrBlock, err)
+		}
+	}
+	return plan, nil
+}
+
+func (client *Client) describeCustomRouteEntries(regionId common.Region, routeTableId string) ([]RouteEntry, error) {
+	var entries []RouteEntry
+	args := &DescribeRouteEntryListArgs{
+		RegionId:       string(regionId),
+		RouteTableId:   routeTableId,
+		RouteEntryType: string(RouteTableCustom),
+		MaxResult:      100,
+	}
+	for {
+		response, err := client.DescribeRouteEntryList(args)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, response.RouteEntrys.RouteEntry...)
+		if response.NextToken == "" {
+			return entries, nil
+		}
+		args.NextToken = response.NextToken
+	}
+}
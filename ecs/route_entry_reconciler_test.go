@@ -0,0 +1,158 @@
+package ecs
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func routeEntry(id string, cidr string, hops ...string) RouteEntry {
+	e := RouteEntry{RouteEntryId: id, DestinationCidrBlock: cidr}
+	for i := 0; i+1 < len(hops); i += 2 {
+		e.NextHops.NextHop = append(e.NextHops.NextHop, NextHop{NextHopType: hops[i], NextHopId: hops[i+1]})
+	}
+	return e
+}
+
+func TestDiffRouteEntries(t *testing.T) {
+	current := []RouteEntry{
+		routeEntry("rte-1", "10.1.0.0/16", "Instance", "i-1"),
+		routeEntry("rte-2", "10.2.0.0/16", "Instance", "i-1"),
+		routeEntry("rte-3", "10.3.0.0/16", "Instance", "i-1", "Instance", "i-2"),
+		routeEntry("rte-4", "10.4.0.0/16", "HaVip", "havip-1"),
+	}
+	desired := []RouteEntrySpec{
+		{DestinationCidrBlock: "10.1.0.0/16", NextHopId: "i-1"},
+		{DestinationCidrBlock: "10.2.0.0/16", NextHopType: NextHopHaVip, NextHopId: "havip-1"},
+		{DestinationCidrBlock: "10.3.0.0/16", NextHopId: "i-3"},
+		{DestinationCidrBlock: "10.5.0.0/16", NextHopType: NextHopVpnGateway, NextHopId: "vpn-1"},
+		{DestinationCidrBlock: "10.1.0.0/16", NextHopType: NextHopInstance, NextHopId: "i-1"},
+	}
+
+	plan, err := diffRouteEntries(current, desired)
+	if err != nil {
+		t.Fatalf("Failed to diff route entries: %v", err)
+	}
+	expected := "+ 10.3.0.0/16 via Instance i-3\n" +
+		"+ 10.5.0.0/16 via VpnGateway vpn-1\n" +
+		"~ rte-2 via HaVip havip-1\n" +
+		"- 10.3.0.0/16 via Instance i-1, Instance i-2\n" +
+		"- 10.4.0.0/16 via HaVip havip-1\n"
+	if plan.String() != expected {
+		t.Errorf("Unexpected plan %q", plan.String())
+	}
+
+	current = []RouteEntry{
+		routeEntry("rte-1", "10.1.0.0/16", "Instance", "i-1"),
+		routeEntry("rte-2", "10.2.0.0/16", "HaVip", "havip-1"),
+		routeEntry("rte-3", "10.3.0.0/16", "Instance", "i-3"),
+		routeEntry("rte-5", "10.5.0.0/16", "VpnGateway", "vpn-1"),
+	}
+	if plan, err := diffRouteEntries(current, desired); err != nil || !plan.IsEmpty() {
+		t.Errorf("Unexpected plan %q: %v", plan.String(), err)
+	}
+
+	current[0].RouteEntryName = "old"
+	current[0].Description = "kept"
+	current[1].RouteEntryName = "havip"
+	desired[0].RouteEntryName = "new"
+	desired[0].Description = "kept"
+	desired[1].RouteEntryName = "havip"
+	desired[1].Description = "failover"
+	desired[4] = desired[0]
+	plan, err = diffRouteEntries(current, desired)
+	if err != nil {
+		t.Fatalf("Failed to diff route entries: %v", err)
+	}
+	expected = "~ rte-1 name \"new\"\n" +
+		"~ rte-2 description \"failover\"\n"
+	if plan.String() != expected {
+		t.Errorf("Unexpected plan %q", plan.String())
+	}
+
+	desired = append(desired, RouteEntrySpec{DestinationCidrBlock: "10.5.0.0/16", NextHopId: "i-1"})
+	if _, err := diffRouteEntries(current, desired); err == nil {
+		t.Errorf("Expected an error for the conflicting entries of 10.5.0.0/16")
+	}
+}
+
+func TestReconcileRouteEntries(t *testing.T) {
+	current := []RouteEntry{
+		routeEntry("rte-2", "10.2.0.0/16", "Instance", "i-1"),
+		routeEntry("rte-3", "10.3.0.0/16", "Instance", "i-1", "Instance", "i-2"),
+		routeEntry("rte-4", "10.4.0.0/16", "HaVip", "havip-1"),
+	}
+	failedCreate := ""
+	newClient := func() (*Client, *ecsRecorder) {
+		return newOfflineTestClient(func(query url.Values) (int, interface{}) {
+			switch query.Get("Action") {
+			case "DescribeRouteEntryList":
+				response := DescribeRouteEntryListResponse{}
+				response.RouteEntrys.RouteEntry = current
+				return http.StatusOK, response
+			case "DescribeRouteTables":
+				response := DescribeRouteTablesResponse{}
+				response.RouteTables.RouteTable = []RouteTableSetType{{RouteTableId: "vtb-1"}}
+				return http.StatusOK, response
+			case "CreateRouteEntry":
+				if query.Get("DestinationCidrBlock") == failedCreate {
+					return http.StatusBadRequest, map[string]string{"Code": "InvalidNextHop.NotFound", "Message": "not found"}
+				}
+			}
+			return http.StatusOK, map[string]string{}
+		})
+	}
+	changes := func(recorder *ecsRecorder) []string {
+		var changes []string
+		for _, query := range recorder.requests {
+			switch action := query.Get("Action"); action {
+			case "CreateRouteEntry", "DeleteRouteEntry":
+				changes = append(changes, action+" "+query.Get("DestinationCidrBlock"))
+			case "ModifyRouteEntry":
+				changes = append(changes, action+" "+query.Get("RouteEntryId"))
+			}
+		}
+		return changes
+	}
+	args := &ReconcileRouteEntriesArgs{
+		RegionId:     "cn-hangzhou",
+		VRouterId:    "vrt-1",
+		RouteTableId: "vtb-1",
+		Entries: []RouteEntrySpec{
+			{DestinationCidrBlock: "10.2.0.0/16", NextHopId: "i-2"},
+			{DestinationCidrBlock: "10.3.0.0/16", NextHopId: "i-3"},
+			{DestinationCidrBlock: "10.5.0.0/16", NextHopId: "i-1"},
+		},
+	}
+
+	client, recorder := newClient()
+	if _, err := client.ReconcileRouteEntries(args); err != nil {
+		t.Fatalf("Failed to reconcile route entries: %v", err)
+	}
+	expected := "CreateRouteEntry 10.5.0.0/16, ModifyRouteEntry rte-2, " +
+		"DeleteRouteEntry 10.3.0.0/16, CreateRouteEntry 10.3.0.0/16, DeleteRouteEntry 10.4.0.0/16"
+	if got := strings.Join(changes(recorder), ", "); got != expected {
+		t.Errorf("Unexpected changes %s", got)
+	}
+
+	// No entry is deleted when a new entry cannot be created
+	failedCreate = "10.5.0.0/16"
+	client, recorder = newClient()
+	if _, err := client.ReconcileRouteEntries(args); err == nil {
+		t.Errorf("Expected the create error")
+	}
+	if got := strings.Join(changes(recorder), ", "); got != "CreateRouteEntry 10.5.0.0/16" {
+		t.Errorf("Unexpected changes %s", got)
+	}
+
+	// The entries are not deleted after a failed replacement
+	failedCreate = "10.3.0.0/16"
+	client, recorder = newClient()
+	if _, err := client.ReconcileRouteEntries(args); err == nil || !strings.Contains(err.Error(), "rte-3 was deleted") {
+		t.Errorf("Expected the replacement error, got %v", err)
+	}
+	if got := changes(recorder); got[len(got)-1] != "CreateRouteEntry 10.3.0.0/16" {
+		t.Errorf("Unexpected changes %v", got)
+	}
+}
@@ -1,7 +1,7 @@
 package ecs
 
 import (
-	"time"
+	"context"
 
 	"github.com/denverdino/aliyungo/common"
 	"github.com/denverdino/aliyungo/util"
@@ -114,6 +114,8 @@ type CreateRouteEntryArgs struct {
 	DestinationCidrBlock string
 	NextHopType          NextHopType
 	NextHopId            string
+	RouteEntryName       string
+	Description          string
 	ClientToken          string
 }
 
@@ -133,6 +135,7 @@ type DeleteRouteEntryArgs struct {
 	RouteTableId         string
 	DestinationCidrBlock string
 	NextHopId            string
+	RouteEntryId         string
 }
 
 type DeleteRouteEntryResponse struct {
@@ -156,35 +159,205 @@ func (client *Client) WaitForAllRouteEntriesAvailable(vrouterId string, routeTab
 		VRouterId:    vrouterId,
 		RouteTableId: routeTableId,
 	}
-	for {
-
+	waiter := common.NewWaiter(timeout, DefaultWaitForInterval, func() (interface{}, error) {
 		routeTables, _, err := client.DescribeRouteTables(&args)
-
 		if err != nil {
-			return err
+			return nil, err
 		}
 		if len(routeTables) == 0 {
-			return common.GetClientErrorFromString("Not found")
+			return nil, common.GetClientErrorFromString("Not found")
 		}
-		success := true
-
-	loop:
-		for _, routeTable := range routeTables {
+		return routeTables, nil
+	}, func(result interface{}) bool {
+		for _, routeTable := range result.([]RouteTableSetType) {
 			for _, routeEntry := range routeTable.RouteEntrys.RouteEntry {
 				if routeEntry.Status != RouteEntryStatusAvailable {
-					success = false
-					break loop
+					return false
 				}
 			}
 		}
-		if success {
-			break
-		}
-		timeout = timeout - DefaultWaitForInterval
-		if timeout <= 0 {
-			return common.GetClientErrorFromString("Timeout")
-		}
-		time.Sleep(DefaultWaitForInterval * time.Second)
+		return true
+	})
+	_, err := waiter.Wait(context.Background())
+	return err
+}
+
+type RouteTableAssociateType string
+
+const (
+	RouteTableAssociateVSwitch = RouteTableAssociateType("VSwitch")
+	RouteTableAssociateGateway = RouteTableAssociateType("Gateway")
+)
+
+type CreateRouteTableArgs struct {
+	RegionId       common.Region
+	VpcId          string
+	RouteTableName string
+	Description    string
+	AssociateType  RouteTableAssociateType // VSwitch by default
+	ClientToken    string
+}
+
+type CreateRouteTableResponse struct {
+	common.Response
+	RouteTableId string
+	VRouterId    string
+}
+
+// CreateRouteTable creates a custom route table in a VPC
+//
+// You can read doc at https://help.aliyun.com/document_detail/87057.html
+func (client *Client) CreateRouteTable(args *CreateRouteTableArgs) (response *CreateRouteTableResponse, err error) {
+	response = &CreateRouteTableResponse{}
+	err = client.Invoke("CreateRouteTable", args, response)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return response, nil
+}
+
+type DeleteRouteTableArgs struct {
+	RegionId     common.Region
+	RouteTableId string
+}
+
+type DeleteRouteTableResponse struct {
+	common.Response
+}
+
+// DeleteRouteTable deletes a custom route table without VSwitch and custom route entry
+//
+// You can read doc at https://help.aliyun.com/document_detail/87601.html
+func (client *Client) DeleteRouteTable(regionId common.Region, routeTableId string) error {
+	args := DeleteRouteTableArgs{
+		RegionId:     regionId,
+		RouteTableId: routeTableId,
+	}
+	response := DeleteRouteTableResponse{}
+	return client.Invoke("DeleteRouteTable", &args, &response)
+}
+
+type ModifyRouteTableAttributesArgs struct {
+	RegionId       common.Region
+	RouteTableId   string
+	RouteTableName string
+	Description    string
+}
+
+type ModifyRouteTableAttributesResponse struct {
+	common.Response
+}
+
+// ModifyRouteTableAttributes modifies the name and description of a route table
+//
+// You can read doc at https://help.aliyun.com/document_detail/87063.html
+func (client *Client) ModifyRouteTableAttributes(args *ModifyRouteTableAttributesArgs) error {
+	response := ModifyRouteTableAttributesResponse{}
+	return client.Invoke("ModifyRouteTableAttributes", args, &response)
+}
+
+type AssociateRouteTableArgs struct {
+	RegionId     common.Region
+	RouteTableId string
+	VSwitchId    string
+	ClientToken  string
+}
+
+type AssociateRouteTableResponse struct {
+	common.Response
+}
+
+// AssociateRouteTable associates a custom route table with a VSwitch of the same VPC
+//
+// You can read doc at https://help.aliyun.com/document_detail/87058.html
+func (client *Client) AssociateRouteTable(args *AssociateRouteTableArgs) error {
+	response := AssociateRouteTableResponse{}
+	return client.Invoke("AssociateRouteTable", args, &response)
+}
+
+type UnassociateRouteTableArgs struct {
+	RegionId     common.Region
+	RouteTableId string
+	VSwitchId    string
+	ClientToken  string
+}
+
+type UnassociateRouteTableResponse struct {
+	common.Response
+}
+
+// UnassociateRouteTable unassociates a custom route table from a VSwitch,
+// which then uses the system route table
+//
+// You can read doc at https://help.aliyun.com/document_detail/87059.html
+func (client *Client) UnassociateRouteTable(args *UnassociateRouteTableArgs) error {
+	response := UnassociateRouteTableResponse{}
+	return client.Invoke("UnassociateRouteTable", args, &response)
+}
+
+type DescribeRouteTableListArgs struct {
+	RegionId       common.Region
+	VpcId          string
+	RouterId       string
+	RouterType     string // VRouter (default) | VBR
+	RouteTableId   string
+	RouteTableName string
+	common.Pagination
+}
+
+type RouterTableListType struct {
+	RouteTableId   string
+	RouteTableName string
+	RouteTableType RouteTableType
+	VpcId          string
+	RouterId       string
+	RouterType     string
+	Description    string
+	Status         string
+	CreationTime   string
+	VSwitchIds     struct {
+		VSwitchId []string
+	}
+}
+
+type DescribeRouteTableListResponse struct {
+	common.Response
+	common.PaginationResult
+	RouterTableList struct {
+		RouterTableListType []RouterTableListType
+	}
+}
+
+// DescribeRouteTableList describes the system and custom route tables with their VSwitches
+//
+// You can read doc at https://help.aliyun.com/document_detail/87602.html
+func (client *Client) DescribeRouteTableList(args *DescribeRouteTableListArgs) (routeTables []RouterTableListType, pagination *common.PaginationResult, err error) {
+	args.Validate()
+	response := DescribeRouteTableListResponse{}
+	err = client.Invoke("DescribeRouteTableList", args, &response)
+	if err != nil {
+		return nil, nil, err
+	}
+	return response.RouterTableList.RouterTableListType, &response.PaginationResult, nil
+}
+
+type ModifyRouteEntryArgs struct {
+	RegionId       common.Region
+	RouteEntryId   string
+	RouteEntryName string
+	Description    string
+	NewNextHopType NextHopType // Switch the next hop of a custom route entry
+	NewNextHopId   string
+}
+
+type ModifyRouteEntryResponse struct {
+	common.Response
+}
+
+// ModifyRouteEntry modifies the name, description or next hop of a route entry
+//
+// You can read doc at https://help.aliyun.com/document_detail/138148.html
+func (client *Client) ModifyRouteEntry(args *ModifyRouteEntryArgs) error {
+	response := ModifyRouteEntryResponse{}
+	return client.Invoke("ModifyRouteEntry", args, &response)
 }
@@ -1,7 +1,7 @@
 package ecs
 
 import (
-	"time"
+	"context"
 
 	"github.com/denverdino/aliyungo/common"
 )
@@ -235,23 +235,16 @@ func (client *Client) WaitForRouterInterfaceAsyn(regionId common.Region, interfa
 	if timeout <= 0 {
 		timeout = InstanceDefaultTimeout
 	}
-	for {
-		interfaces, err := client.DescribeRouterInterfaces(&DescribeRouterInterfacesArgs{
+	waiter := common.NewWaiter(timeout, DefaultWaitForInterval, func() (interface{}, error) {
+		return client.DescribeRouterInterfaces(&DescribeRouterInterfacesArgs{
 			RegionId: regionId,
 			Filter:   []Filter{{Key: "RouterInterfaceId", Value: []string{interfaceId}}},
 		})
-		if err != nil {
-			return err
-		} else if interfaces != nil && InterfaceStatus(interfaces.RouterInterfaceSet.RouterInterfaceType[0].Status) == status {
-			//TODO
-			break
-		}
-		timeout = timeout - DefaultWaitForInterval
-		if timeout <= 0 {
-			return common.GetClientErrorFromString("Timeout")
-		}
-		time.Sleep(DefaultWaitForInterval * time.Second)
-
-	}
-	return nil
+	}, func(result interface{}) bool {
+		interfaces := result.(*DescribeRouterInterfacesResponse)
+		return interfaces != nil && len(interfaces.RouterInterfaceSet.RouterInterfaceType) > 0 &&
+			InterfaceStatus(interfaces.RouterInterfaceSet.RouterInterfaceType[0].Status) == status
+	})
+	_, err := waiter.Wait(context.Background())
+	return err
 }
@@ -0,0 +1,330 @@
+package ecs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/denverdino/aliyungo/common"
+)
+
+// SecurityGroupReconciler keeps the rules of a security group in sync with a
+// desired rule set, authorizing the missing rules and revoking the others
+//
+//	reconciler := ecs.NewSecurityGroupReconciler(client, regionId, securityGroupId)
+//	reconciler.DryRun = true // only print the plan
+//	plan, err := reconciler.Reconcile([]ecs.PermissionType{
+//		{IpProtocol: ecs.IpProtocolTCP, PortRange: "22", SourceCidrIp: "10.0.0.0/8"},
+//		{Direction: "egress", IpProtocol: ecs.IpProtocolAll},
+//	})
+type SecurityGroupReconciler struct {
+	client          *Client
+	RegionId        common.Region
+	SecurityGroupId string
+	NicType         NicType   // NicType of the described and desired rules, intranet by default
+	DryRun          bool      // Reconcile prints the plan to Output instead of applying it
+	Output          io.Writer // os.Stdout by default
+}
+
+func NewSecurityGroupReconciler(client *Client, regionId common.Region, securityGroupId string) *SecurityGroupReconciler {
+	return &SecurityGroupReconciler{
+		client:          client,
+		RegionId:        regionId,
+		SecurityGroupId: securityGroupId,
+	}
+}
+
+// SecurityGroupPlan is the changes bringing a security group to a desired
+// rule set. The rules to revoke are the ones returned by
+// DescribeSecurityGroupAttribute, as they are sent back to RevokeSecurityGroup.
+type SecurityGroupPlan struct {
+	Authorize []PermissionType
+	Revoke    []PermissionType
+}
+
+func (plan *SecurityGroupPlan) IsEmpty() bool {
+	return len(plan.Authorize) == 0 && len(plan.Revoke) == 0
+}
+
+// String returns the plan with one line per rule, "+" for the rules to
+// authorize and "-" for the rules to revoke
+func (plan *SecurityGroupPlan) String() string {
+	var buf bytes.Buffer
+	for _, p := range plan.Authorize {
+		fmt.Fprintf(&buf, "+ %s\n", formatPermission(p))
+	}
+	for _, p := range plan.Revoke {
+		fmt.Fprintf(&buf, "- %s\n", formatPermission(p))
+	}
+	return buf.String()
+}
+
+func formatPermission(p PermissionType) string {
+	return fmt.Sprintf("%s %s %s %s %s priority %d (%s)", p.Direction, p.IpProtocol, p.PortRange, permissionPeer(p), p.Policy, p.Priority, p.NicType)
+}
+
+// permissionPeer returns the source of an ingress permission or the
+// destination of an egress one, or "" when there is none
+func permissionPeer(p PermissionType) string {
+	peers := []string{p.SourceCidrIp, p.Ipv6SourceCidrIp, p.SourceGroupId, p.SourcePrefixListId}
+	if Direction(strings.ToLower(p.Direction)) == DirectionEgress {
+		peers = []string{p.DestCidrIp, p.Ipv6DestCidrIp, p.DestGroupId, p.DestPrefixListId}
+	}
+	var set []string
+	for _, peer := range peers {
+		if strings.TrimSpace(peer) != "" {
+			set = append(set, peer)
+		}
+	}
+	return strings.Join(set, ",")
+}
+
+// NormalizePermission returns the permission with the defaults applied by ECS,
+// so that equivalent permissions are equal once normalized:
+//   - Direction is ingress, Policy is accept and Priority is 1 when not set
+//   - NicType is intranet when not set
+//   - IpProtocol and Policy are lower case
+//   - PortRange is "-1/-1" for the protocols without ports and a single port "22" is "22/22"
+//   - the CIDR is 0.0.0.0/0 when neither a CIDR, a group nor a prefix list is
+//     set, and an IP is a /32 or a /128 CIDR
+func NormalizePermission(p PermissionType) PermissionType {
+	p.Direction = strings.ToLower(strings.TrimSpace(p.Direction))
+	if p.Direction == "" {
+		p.Direction = string(DirectionIngress)
+	}
+	p.IpProtocol = IpProtocol(strings.ToLower(strings.TrimSpace(string(p.IpProtocol))))
+	if p.IpProtocol == "" {
+		p.IpProtocol = IpProtocolAll
+	}
+	p.Policy = PermissionPolicy(strings.ToLower(strings.TrimSpace(string(p.Policy))))
+	if p.Policy == "" {
+		p.Policy = PermissionPolicyAccept
+	}
+	if p.Priority <= 0 {
+		p.Priority = 1
+	}
+	if p.NicType == "" {
+		p.NicType = NicTypeIntranet
+	}
+
+	switch p.IpProtocol {
+	case IpProtocolTCP, IpProtocolUDP:
+		p.PortRange = strings.Replace(p.PortRange, " ", "", -1)
+		if p.PortRange != "" && !strings.Contains(p.PortRange, "/") {
+			p.PortRange = p.PortRange + "/" + p.PortRange
+		}
+	default:
+		p.PortRange = "-1/-1"
+	}
+
+	noPeer := permissionPeer(p) == ""
+	if Direction(p.Direction) == DirectionEgress {
+		p.DestCidrIp = normalizeCidr(p.DestCidrIp)
+		p.Ipv6DestCidrIp = normalizeCidr(p.Ipv6DestCidrIp)
+		if noPeer {
+			p.DestCidrIp = "0.0.0.0/0"
+		}
+	} else {
+		p.SourceCidrIp = normalizeCidr(p.SourceCidrIp)
+		p.Ipv6SourceCidrIp = normalizeCidr(p.Ipv6SourceCidrIp)
+		if noPeer {
+			p.SourceCidrIp = "0.0.0.0/0"
+		}
+	}
+	return p
+}
+
+func normalizeCidr(cidr string) string {
+	cidr = strings.TrimSpace(cidr)
+	if cidr == "" {
+		return ""
+	}
+	if !strings.Contains(cidr, "/") {
+		if ip := net.ParseIP(cidr); ip != nil {
+			if ip.To4() != nil {
+				return cidr + "/32"
+			}
+			return ip.String() + "/128"
+		}
+		return cidr
+	}
+	if _, ipNet, err := net.ParseCIDR(cidr); err == nil {
+		return ipNet.String()
+	}
+	return cidr
+}
+
+// permissionKey identifies a normalized permission, the description is ignored
+func permissionKey(p PermissionType) string {
+	return strings.Join([]string{
+		p.Direction, string(p.IpProtocol), p.PortRange,
+		p.SourceCidrIp, p.Ipv6SourceCidrIp, p.SourceGroupId, p.SourceGroupOwnerAccount, p.SourcePrefixListId,
+		p.DestCidrIp, p.Ipv6DestCidrIp, p.DestGroupId, p.DestGroupOwnerAccount, p.DestPrefixListId,
+		string(p.Policy), string(p.NicType), fmt.Sprint(p.Priority),
+	}, "|")
+}
+
+// diffPermissions returns the plan bringing the current permissions to the
+// desired ones. The current permissions without a known peer are left
+// untouched, as they cannot be compared nor revoked safely.
+func diffPermissions(current, desired []PermissionType) *SecurityGroupPlan {
+	plan := &SecurityGroupPlan{}
+	var known []PermissionType
+	for _, p := range current {
+		if permissionPeer(p) != "" {
+			known = append(known, p)
+		}
+	}
+	existing := make(map[string]bool)
+	for _, p := range known {
+		existing[permissionKey(NormalizePermission(p))] = true
+	}
+	wanted := make(map[string]bool)
+	for _, p := range desired {
+		p = NormalizePermission(p)
+		key := permissionKey(p)
+		if wanted[key] {
+			continue
+		}
+		wanted[key] = true
+		if !existing[key] {
+			plan.Authorize = append(plan.Authorize, p)
+		}
+	}
+	for _, p := range known {
+		key := permissionKey(NormalizePermission(p))
+		if !wanted[key] {
+			wanted[key] = true
+			plan.Revoke = append(plan.Revoke, p)
+		}
+	}
+	return plan
+}
+
+func (r *SecurityGroupReconciler) nicType() NicType {
+	if r.NicType == "" {
+		return NicTypeIntranet
+	}
+	return r.NicType
+}
+
+// Plan describes the rules of the security group and returns the changes
+// bringing them to the desired ones. Only the rules of the NicType of the
+// reconciler are described, so that the desired rules of another NicType are
+// rejected.
+func (r *SecurityGroupReconciler) Plan(desired []PermissionType) (*SecurityGroupPlan, error) {
+	rules := make([]PermissionType, len(desired))
+	for i, p := range desired {
+		if p.NicType == "" {
+			p.NicType = r.nicType()
+		} else if p.NicType != r.nicType() {
+			return nil, fmt.Errorf("rule %s does not match the NicType %s of the reconciler", formatPermission(p), r.nicType())
+		}
+		rules[i] = p
+	}
+	response, err := r.client.DescribeSecurityGroupAttribute(&DescribeSecurityGroupAttributeArgs{
+		RegionId:        r.RegionId,
+		SecurityGroupId: r.SecurityGroupId,
+		NicType:         r.nicType(),
+		Direction:       DirectionAll,
+	})
+	if err != nil {
+		return nil, err
+	}
+	current := make([]PermissionType, len(response.Permissions.Permission))
+	for i, p := range response.Permissions.Permission {
+		if p.NicType == "" {
+			p.NicType = r.nicType()
+		}
+		current[i] = p
+	}
+	return diffPermissions(current, rules), nil
+}
+
+// Apply authorizes then revokes the rules of the plan, so that the traffic
+// allowed by both the current and the desired rules is never interrupted.
+// Authorizing an existing rule is not an error.
+func (r *SecurityGroupReconciler) Apply(plan *SecurityGroupPlan) error {
+	for _, p := range plan.Authorize {
+		if err := r.authorize(p); err != nil && !common.IsErrorCode(err, "InvalidPermission.Duplicate") {
+			return fmt.Errorf("failed to authorize %s: %v", formatPermission(p), err)
+		}
+	}
+	for _, p := range plan.Revoke {
+		if err := r.revoke(p); err != nil {
+			return fmt.Errorf("failed to revoke %s: %v", formatPermission(p), err)
+		}
+	}
+	return nil
+}
+
+// Reconcile brings the rules of the security group to the desired ones, or
+// only prints the plan to Output with DryRun
+func (r *SecurityGroupReconciler) Reconcile(desired []PermissionType) (*SecurityGroupPlan, error) {
+	plan, err := r.Plan(desired)
+	if err != nil {
+		return nil, err
+	}
+	if r.DryRun {
+		output := r.Output
+		if output == nil {
+			output = os.Stdout
+		}
+		_, err = io.WriteString(output, plan.String())
+		return plan, err
+	}
+	return plan, r.Apply(plan)
+}
+
+func (r *SecurityGroupReconciler) authorize(p PermissionType) error {
+	if Direction(p.Direction) == DirectionEgress {
+		return r.client.AuthorizeSecurityGroupEgress(r.egressArgs(p))
+	}
+	return r.client.AuthorizeSecurityGroup(r.ingressArgs(p))
+}
+
+func (r *SecurityGroupReconciler) revoke(p PermissionType) error {
+	if Direction(strings.ToLower(p.Direction)) == DirectionEgress {
+		return r.client.RevokeSecurityGroupEgress(&RevokeSecurityGroupEgressArgs{*r.egressArgs(p)})
+	}
+	return r.client.RevokeSecurityGroup(&RevokeSecurityGroupArgs{*r.ingressArgs(p)})
+}
+
+func (r *SecurityGroupReconciler) ingressArgs(p PermissionType) *AuthorizeSecurityGroupArgs {
+	return &AuthorizeSecurityGroupArgs{
+		SecurityGroupId:         r.SecurityGroupId,
+		RegionId:                r.RegionId,
+		IpProtocol:              p.IpProtocol,
+		PortRange:               p.PortRange,
+		SourceGroupId:           p.SourceGroupId,
+		SourceGroupOwnerAccount: p.SourceGroupOwnerAccount,
+		SourceCidrIp:            p.SourceCidrIp,
+		Ipv6SourceCidrIp:        p.Ipv6SourceCidrIp,
+		SourcePrefixListId:      p.SourcePrefixListId,
+		Policy:                  p.Policy,
+		Priority:                p.Priority,
+		NicType:                 p.NicType,
+		Description:             p.Description,
+	}
+}
+
+func (r *SecurityGroupReconciler) egressArgs(p PermissionType) *AuthorizeSecurityGroupEgressArgs {
+	return &AuthorizeSecurityGroupEgressArgs{
+		SecurityGroupId:       r.SecurityGroupId,
+		RegionId:              r.RegionId,
+		IpProtocol:            p.IpProtocol,
+		PortRange:             p.PortRange,
+		DestGroupId:           p.DestGroupId,
+		DestGroupOwnerAccount: p.DestGroupOwnerAccount,
+		DestCidrIp:            p.DestCidrIp,
+		Ipv6DestCidrIp:        p.Ipv6DestCidrIp,
+		DestPrefixListId:      p.DestPrefixListId,
+		Policy:                p.Policy,
+		Priority:              p.Priority,
+		NicType:               p.NicType,
+		Description:           p.Description,
+	}
+}
@@ -0,0 +1,180 @@
+package ecs
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestNormalizePermission(t *testing.T) {
+	p := NormalizePermission(PermissionType{IpProtocol: "TCP", PortRange: "22", SourceCidrIp: "10.1.2.3/8"})
+	expected := PermissionType{
+		Direction:    "ingress",
+		IpProtocol:   IpProtocolTCP,
+		PortRange:    "22/22",
+		SourceCidrIp: "10.0.0.0/8",
+		Policy:       PermissionPolicyAccept,
+		Priority:     1,
+		NicType:      NicTypeIntranet,
+	}
+	if p != expected {
+		t.Errorf("Unexpected permission %+v", p)
+	}
+
+	p = NormalizePermission(PermissionType{Direction: "Egress", IpProtocol: IpProtocolICMP, PortRange: "1/200"})
+	if p.PortRange != "-1/-1" || p.DestCidrIp != "0.0.0.0/0" || p.SourceCidrIp != "" {
+		t.Errorf("Unexpected permission %+v", p)
+	}
+
+	p = NormalizePermission(PermissionType{IpProtocol: IpProtocolUDP, PortRange: "53/53", SourceGroupId: "sg-1"})
+	if p.SourceCidrIp != "" {
+		t.Errorf("Unexpected permission %+v", p)
+	}
+}
+
+func TestDiffPermissions(t *testing.T) {
+	current := []PermissionType{
+		{Direction: "ingress", IpProtocol: "TCP", PortRange: "22/22", SourceCidrIp: "0.0.0.0/0", Policy: "Accept", Priority: 1, NicType: NicTypeIntranet},
+		{Direction: "ingress", IpProtocol: "TCP", PortRange: "80/80", SourceCidrIp: "0.0.0.0/0", Policy: "Accept", Priority: 1, NicType: NicTypeIntranet},
+		{Direction: "egress", IpProtocol: "ALL", PortRange: "-1/-1", DestCidrIp: "0.0.0.0/0", Policy: "Accept", Priority: 1, NicType: NicTypeIntranet},
+	}
+	desired := []PermissionType{
+		{IpProtocol: IpProtocolTCP, PortRange: "22", Description: "ssh"},
+		{IpProtocol: IpProtocolTCP, PortRange: "443"},
+		{IpProtocol: IpProtocolTCP, PortRange: "443/443"},
+		{Direction: "egress", IpProtocol: IpProtocolAll},
+	}
+
+	plan := diffPermissions(current, desired)
+	if len(plan.Authorize) != 1 || plan.Authorize[0].PortRange != "443/443" {
+		t.Errorf("Unexpected rules to authorize %+v", plan.Authorize)
+	}
+	if len(plan.Revoke) != 1 || plan.Revoke[0] != current[1] {
+		t.Errorf("Unexpected rules to revoke %+v", plan.Revoke)
+	}
+	expected := "+ ingress tcp 443/443 0.0.0.0/0 accept priority 1 (intranet)\n" +
+		"- ingress TCP 80/80 0.0.0.0/0 Accept priority 1 (intranet)\n"
+	if plan.String() != expected {
+		t.Errorf("Unexpected plan %q", plan.String())
+	}
+
+	current = append(current[:1], current[2], plan.Authorize[0])
+	if plan := diffPermissions(current, desired); !plan.IsEmpty() {
+		t.Errorf("Unexpected plan %q", plan.String())
+	}
+}
+
+func TestDiffPermissionsPeers(t *testing.T) {
+	current := []PermissionType{
+		{Direction: "ingress", IpProtocol: "TCP", PortRange: "22/22", Ipv6SourceCidrIp: "2001:db8::/32", Policy: "Accept", Priority: 1, NicType: NicTypeIntranet},
+		{Direction: "ingress", IpProtocol: "TCP", PortRange: "22/22", SourcePrefixListId: "pl-1", Policy: "Accept", Priority: 1, NicType: NicTypeIntranet},
+		{Direction: "egress", IpProtocol: "ALL", PortRange: "-1/-1", Ipv6DestCidrIp: "::/0", Policy: "Accept", Priority: 1, NicType: NicTypeIntranet},
+		// A kind of peer the reconciler does not know
+		{Direction: "ingress", IpProtocol: "TCP", PortRange: "80/80", Policy: "Accept", Priority: 1, NicType: NicTypeIntranet},
+	}
+	desired := []PermissionType{
+		{IpProtocol: IpProtocolTCP, PortRange: "22", Ipv6SourceCidrIp: "2001:db8:0::/32"},
+		{IpProtocol: IpProtocolTCP, PortRange: "22", SourceCidrIp: "10.0.0.0/8"},
+		{Direction: "egress", IpProtocol: IpProtocolAll, Ipv6DestCidrIp: "::/0"},
+	}
+
+	plan := diffPermissions(current, desired)
+	if len(plan.Authorize) != 1 || plan.Authorize[0].SourceCidrIp != "10.0.0.0/8" || plan.Authorize[0].Ipv6SourceCidrIp != "" {
+		t.Errorf("Unexpected rules to authorize %+v", plan.Authorize)
+	}
+	if len(plan.Revoke) != 1 || plan.Revoke[0] != current[1] {
+		t.Errorf("Unexpected rules to revoke %+v", plan.Revoke)
+	}
+
+	p := NormalizePermission(PermissionType{Ipv6SourceCidrIp: "2001:DB8::1"})
+	if p.Ipv6SourceCidrIp != "2001:db8::1/128" || p.SourceCidrIp != "" {
+		t.Errorf("Unexpected permission %+v", p)
+	}
+}
+
+func TestSecurityGroupReconcilerRevokesDescribedRule(t *testing.T) {
+	described := PermissionType{
+		Direction:    "ingress",
+		IpProtocol:   "TCP",
+		PortRange:    "80/80",
+		SourceCidrIp: "10.0.0.0/8",
+		Policy:       "Accept",
+		Priority:     1,
+		NicType:      NicTypeIntranet,
+		Description:  "web",
+	}
+	client, recorder := newOfflineTestClient(func(query url.Values) (int, interface{}) {
+		response := DescribeSecurityGroupAttributeResponse{}
+		if query.Get("Action") == "DescribeSecurityGroupAttribute" {
+			response.Permissions.Permission = []PermissionType{described}
+		}
+		return http.StatusOK, response
+	})
+
+	reconciler := NewSecurityGroupReconciler(client, "cn-hangzhou", "sg-1")
+	if _, err := reconciler.Reconcile(nil); err != nil {
+		t.Fatalf("Failed to reconcile: %v", err)
+	}
+	if len(recorder.requests) != 2 {
+		t.Fatalf("Unexpected requests %v", recorder.actions())
+	}
+	revoke := recorder.requests[1]
+	expected := map[string]string{
+		"Action":       "RevokeSecurityGroup",
+		"IpProtocol":   "TCP",
+		"PortRange":    "80/80",
+		"SourceCidrIp": "10.0.0.0/8",
+		"Policy":       "Accept",
+		"Description":  "web",
+	}
+	for key, value := range expected {
+		if revoke.Get(key) != value {
+			t.Errorf("Expected %s=%q, got %q", key, value, revoke.Get(key))
+		}
+	}
+}
+
+func TestSecurityGroupReconcilerNicType(t *testing.T) {
+	described := PermissionType{
+		Direction:    "ingress",
+		IpProtocol:   "TCP",
+		PortRange:    "22/22",
+		SourceCidrIp: "0.0.0.0/0",
+		Policy:       "Accept",
+		Priority:     1,
+	}
+	client, recorder := newOfflineTestClient(func(query url.Values) (int, interface{}) {
+		response := DescribeSecurityGroupAttributeResponse{}
+		if query.Get("Action") == "DescribeSecurityGroupAttribute" {
+			response.Permissions.Permission = []PermissionType{described}
+		}
+		return http.StatusOK, response
+	})
+
+	reconciler := NewSecurityGroupReconciler(client, "cn-hangzhou", "sg-1")
+	reconciler.NicType = NicTypeInternet
+	plan, err := reconciler.Plan([]PermissionType{
+		{IpProtocol: IpProtocolTCP, PortRange: "22", NicType: NicTypeInternet},
+	})
+	if err != nil {
+		t.Fatalf("Failed to plan: %v", err)
+	}
+	if !plan.IsEmpty() {
+		t.Errorf("Expected an empty plan, got\n%s", plan)
+	}
+	if query := recorder.requests[0]; query.Get("NicType") != string(NicTypeInternet) {
+		t.Errorf("Unexpected NicType %q described", query.Get("NicType"))
+	}
+
+	recorder.requests = nil
+	_, err = reconciler.Plan([]PermissionType{
+		{IpProtocol: IpProtocolTCP, PortRange: "22"},
+		{IpProtocol: IpProtocolTCP, PortRange: "80", NicType: NicTypeIntranet},
+	})
+	if err == nil {
+		t.Errorf("Expected the intranet rule to be rejected")
+	}
+	if len(recorder.requests) != 0 {
+		t.Errorf("Unexpected requests %v", recorder.actions())
+	}
+}
@@ -58,11 +58,15 @@ type PermissionType struct {
 	IpProtocol              IpProtocol
 	PortRange               string
 	SourceCidrIp            string
+	Ipv6SourceCidrIp        string
 	SourceGroupId           string
 	SourceGroupOwnerAccount string
+	SourcePrefixListId      string
 	DestCidrIp              string
+	Ipv6DestCidrIp          string
 	DestGroupId             string
 	DestGroupOwnerAccount   string
+	DestPrefixListId        string
 	Policy                  PermissionPolicy
 	NicType                 NicType
 	Priority                int
@@ -241,9 +245,12 @@ type AuthorizeSecurityGroupArgs struct {
 	SourceGroupOwnerAccount string
 	SourceGroupOwnerID      string
 	SourceCidrIp            string           // IPv4 only, default 0.0.0.0/0
+	Ipv6SourceCidrIp        string           // IPv6 only
+	SourcePrefixListId      string           // Prefix list instead of a CIDR
 	Policy                  PermissionPolicy // enum of accept (default) | drop
 	Priority                int              // 1 - 100, default 1
 	NicType                 NicType          // enum of internet | intranet (default)
+	Description             string
 }
 
 type AuthorizeSecurityGroupResponse struct {
@@ -283,9 +290,12 @@ type AuthorizeSecurityGroupEgressArgs struct {
 	DestGroupOwnerAccount string
 	DestGroupOwnerId      string
 	DestCidrIp            string           // IPv4 only, default 0.0.0.0/0
+	Ipv6DestCidrIp        string           // IPv6 only
+	DestPrefixListId      string           // Prefix list instead of a CIDR
 	Policy                PermissionPolicy // enum of accept (default) | drop
 	Priority              int              // 1 - 100, default 1
 	NicType               NicType          // enum of internet | intranet (default)
+	Description           string
 }
 
 type AuthorizeSecurityGroupEgressResponse struct {
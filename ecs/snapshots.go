@@ -1,7 +1,7 @@
 package ecs
 
 import (
-	"time"
+	"context"
 
 	"github.com/denverdino/aliyungo/common"
 	"github.com/denverdino/aliyungo/util"
@@ -12,6 +12,8 @@ type DescribeSnapshotsArgs struct {
 	InstanceId  string
 	DiskId      string
 	SnapshotIds []string //["s-xxxxxxxxx", "s-yyyyyyyyy", ..."s-zzzzzzzzz"]
+	Status      string   //enum for progressing | accomplished | failed | all (default)
+	Tag         []TagType
 	common.Pagination
 }
 
@@ -29,6 +31,9 @@ type SnapshotType struct {
 	Status         string
 	Usage          string
 	CreationTime   util.ISO6801Time
+	Tags           struct {
+		Tag []TagItemType
+	}
 }
 
 type DescribeSnapshotsResponse struct {
@@ -116,27 +121,22 @@ func (client *Client) WaitForSnapShotReady(regionId common.Region, snapshotId st
 	if timeout <= 0 {
 		timeout = SnapshotDefaultTimeout
 	}
-	for {
-		args := DescribeSnapshotsArgs{
-			RegionId:    regionId,
-			SnapshotIds: []string{snapshotId},
-		}
-
+	args := DescribeSnapshotsArgs{
+		RegionId:    regionId,
+		SnapshotIds: []string{snapshotId},
+	}
+	waiter := common.NewWaiter(timeout, DefaultWaitForInterval, func() (interface{}, error) {
 		snapshots, _, err := client.DescribeSnapshots(&args)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		if len(snapshots) == 0 {
-			return common.GetClientErrorFromString("Not found")
+			return nil, common.GetClientErrorFromString("Not found")
 		}
-		if snapshots[0].Progress == "100%" {
-			break
-		}
-		timeout = timeout - DefaultWaitForInterval
-		if timeout <= 0 {
-			return common.GetClientErrorFromString("Timeout")
-		}
-		time.Sleep(DefaultWaitForInterval * time.Second)
-	}
-	return nil
+		return snapshots[0], nil
+	}, func(result interface{}) bool {
+		return result.(SnapshotType).Progress == "100%"
+	})
+	_, err := waiter.Wait(context.Background())
+	return err
 }
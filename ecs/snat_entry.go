@@ -1,7 +1,7 @@
 package ecs
 
 import (
-	"time"
+	"context"
 
 	"github.com/denverdino/aliyungo/common"
 )
@@ -132,24 +132,18 @@ func (client *Client) WaitForSnatEntryAvailable(regionId common.Region, snatTabl
 		SnatEntryId: snatEntryId,
 	}
 
-	for {
+	waiter := common.NewWaiter(timeout, DefaultWaitForInterval, func() (interface{}, error) {
 		snatEntries, _, err := client.DescribeSnatTableEntries(args)
 		if err != nil {
-			return err
+			return nil, err
 		}
-
 		if len(snatEntries) == 0 {
-			return common.GetClientErrorFromString("Not found")
-		}
-		if snatEntries[0].Status == SnatEntryStatusAvailable {
-			break
-		}
-
-		timeout = timeout - DefaultWaitForInterval
-		if timeout <= 0 {
-			return common.GetClientErrorFromString("Timeout")
+			return nil, common.GetClientErrorFromString("Not found")
 		}
-		time.Sleep(DefaultWaitForInterval * time.Second)
-	}
-	return nil
+		return snatEntries[0], nil
+	}, func(result interface{}) bool {
+		return result.(SnatEntrySetType).Status == SnatEntryStatusAvailable
+	})
+	_, err := waiter.Wait(context.Background())
+	return err
 }
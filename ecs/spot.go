@@ -0,0 +1,117 @@
+package ecs
+
+import (
+	"github.com/denverdino/aliyungo/common"
+	"github.com/denverdino/aliyungo/util"
+)
+
+type DescribeSpotPriceHistoryArgs struct {
+	RegionId     common.Region
+	ZoneId       string
+	NetworkType  string // classic | vpc
+	InstanceType string
+	IoOptimized  IoOptimized
+	OSType       string            // linux (default) | windows
+	StartTime    *util.ISO6801Time // Within the last 30 days, 3 hours before EndTime by default
+	EndTime      *util.ISO6801Time // Now by default
+	SpotDuration *int
+	Offset       int
+}
+
+type SpotPriceType struct {
+	ZoneId       string
+	InstanceType string
+	IoOptimized  IoOptimized
+	NetworkType  string
+	Timestamp    util.ISO6801Time
+	SpotPrice    float64
+	OriginPrice  float64
+}
+
+type DescribeSpotPriceHistoryResponse struct {
+	common.Response
+	Currency   string
+	NextOffset int
+	SpotPrices struct {
+		SpotPriceType []SpotPriceType
+	}
+}
+
+// DescribeSpotPriceHistory describes a page of the spot price history,
+// nextOffset is the Offset of the next page or 0 for the last page
+//
+// You can read doc at https://help.aliyun.com/document_detail/60400.html
+func (client *Client) DescribeSpotPriceHistory(args *DescribeSpotPriceHistoryArgs) (prices []SpotPriceType, nextOffset int, err error) {
+	response := DescribeSpotPriceHistoryResponse{}
+	err = client.Invoke("DescribeSpotPriceHistory", args, &response)
+	if err != nil {
+		return nil, 0, err
+	}
+	return response.SpotPrices.SpotPriceType, response.NextOffset, nil
+}
+
+// DescribeAllSpotPriceHistory describes the spot price history of the time
+// window between StartTime and EndTime, following the pages from Offset
+func (client *Client) DescribeAllSpotPriceHistory(args *DescribeSpotPriceHistoryArgs) (prices []SpotPriceType, err error) {
+	pageArgs := *args
+	for {
+		page, nextOffset, err := client.DescribeSpotPriceHistory(&pageArgs)
+		if err != nil {
+			return nil, err
+		}
+		prices = append(prices, page...)
+		if nextOffset <= pageArgs.Offset || len(page) == 0 {
+			return prices, nil
+		}
+		pageArgs.Offset = nextOffset
+	}
+}
+
+type DescribeSpotAdviceArgs struct {
+	RegionId            common.Region
+	ZoneId              string
+	InstanceTypes       []string `query:"list"`
+	InstanceTypeFamily  string
+	InstanceFamilyLevel string
+	Cores               int
+	Memory              *float64 // Memory in GiB
+	MinCores            int
+	MinMemory           *float64 // Minimum memory in GiB
+	GpuSpec             string
+	GpuAmount           int
+}
+
+type AvailableSpotResourceType struct {
+	InstanceType        string
+	InterruptionRate    float64
+	InterruptRateDesc   string
+	AverageSpotDiscount int
+}
+
+type AvailableSpotZoneType struct {
+	ZoneId                 string
+	AvailableSpotResources struct {
+		AvailableSpotResource []AvailableSpotResourceType
+	}
+}
+
+type DescribeSpotAdviceResponse struct {
+	common.Response
+	RegionId           common.Region
+	AvailableSpotZones struct {
+		AvailableSpotZone []AvailableSpotZoneType
+	}
+}
+
+// DescribeSpotAdvice describes the interruption rates and discounts of the
+// spot instance types over the last 30 days
+//
+// You can read doc at https://help.aliyun.com/document_detail/190317.html
+func (client *Client) DescribeSpotAdvice(args *DescribeSpotAdviceArgs) (zones []AvailableSpotZoneType, err error) {
+	response := DescribeSpotAdviceResponse{}
+	err = client.Invoke("DescribeSpotAdvice", args, &response)
+	if err != nil {
+		return nil, err
+	}
+	return response.AvailableSpotZones.AvailableSpotZone, nil
+}
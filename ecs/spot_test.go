@@ -0,0 +1,30 @@
+package ecs
+
+import (
+	"testing"
+
+	"github.com/denverdino/aliyungo/util"
+)
+
+func TestDescribeSpotAdviceArgs(t *testing.T) {
+	args := &DescribeSpotAdviceArgs{
+		RegionId:      "cn-hangzhou",
+		InstanceTypes: []string{"ecs.g6.large", "ecs.c6.large"},
+		MinCores:      2,
+	}
+	values := util.ConvertToQueryValues(args)
+	for _, name := range []string{"Memory", "MinMemory", "Cores"} {
+		if _, ok := values[name]; ok {
+			t.Errorf("Unexpected %s=%q", name, values.Get(name))
+		}
+	}
+	if values.Get("InstanceTypes.2") != "ecs.c6.large" || values.Get("MinCores") != "2" {
+		t.Errorf("Unexpected query %v", values)
+	}
+
+	memory := 0.5
+	args.MinMemory = &memory
+	if memory := util.ConvertToQueryValues(args).Get("MinMemory"); memory != "0.5000" {
+		t.Errorf("Unexpected MinMemory %q", memory)
+	}
+}
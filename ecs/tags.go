@@ -1,6 +1,10 @@
 package ecs
 
-import "github.com/denverdino/aliyungo/common"
+import (
+	"sort"
+
+	"github.com/denverdino/aliyungo/common"
+)
 
 type TagResourceType string
 
@@ -126,3 +130,145 @@ func (client *Client) DescribeTags(args *DescribeTagsArgs) (tags []TagItemType,
 	}
 	return response.Tags.Tag, &response.PaginationResult, nil
 }
+
+// Resource types of TagResources, UntagResources and ListTagResources on an ECS client
+const (
+	TagResourceSecurityGroup       = TagResourceType("securitygroup")
+	TagResourceEni                 = TagResourceType("eni")
+	TagResourceKeyPair             = TagResourceType("keypair")
+	TagResourceLaunchTemplate      = TagResourceType("launchtemplate")
+	TagResourceDedicatedHost       = TagResourceType("ddh")
+	TagResourceDeploymentSet       = TagResourceType("deploymentset")
+	TagResourceSnapshotPolicy      = TagResourceType("snapshotpolicy")
+	TagResourceElasticityAssurance = TagResourceType("elasticityassurance")
+	TagResourceCommand             = TagResourceType("command")
+)
+
+// Resource types of TagResources, UntagResources and ListTagResources on a VPC client
+const (
+	TagResourceVpc        = TagResourceType("VPC")
+	TagResourceVSwitch    = TagResourceType("VSWITCH")
+	TagResourceRouteTable = TagResourceType("ROUTETABLE")
+	TagResourceEip        = TagResourceType("EIP")
+	TagResourceNatGateway = TagResourceType("NATGATEWAY")
+)
+
+type TagResourcesArgs struct {
+	RegionId     common.Region
+	ResourceType TagResourceType
+	ResourceId   []string  `query:"list"` // Up to 50 resources
+	Tag          []TagType // Up to 20 tags
+}
+
+type TagResourcesResponse struct {
+	common.Response
+}
+
+// TagResources adds tags to resources of a type, use a VPC client for the VPC resource types
+//
+// You can read doc at https://help.aliyun.com/document_detail/110424.html
+func (client *Client) TagResources(args *TagResourcesArgs) error {
+	response := TagResourcesResponse{}
+	return client.Invoke("TagResources", args, &response)
+}
+
+type UntagResourcesArgs struct {
+	RegionId     common.Region
+	ResourceType TagResourceType
+	ResourceId   []string `query:"list"`
+	TagKey       []string `query:"list"`
+	All          bool     // Remove all the tags when TagKey is empty, ECS resource types only
+}
+
+type UntagResourcesResponse struct {
+	common.Response
+}
+
+// UntagResources removes tags from resources of a type, use a VPC client for the VPC resource types
+//
+// You can read doc at https://help.aliyun.com/document_detail/110425.html
+func (client *Client) UntagResources(args *UntagResourcesArgs) error {
+	response := UntagResourcesResponse{}
+	return client.Invoke("UntagResources", args, &response)
+}
+
+type ListTagResourcesArgs struct {
+	RegionId     common.Region
+	ResourceType TagResourceType
+	ResourceId   []string  `query:"list"`
+	Tag          []TagType // Filter of the resources having all the tags
+	NextToken    string
+}
+
+type ListTagResourcesResponse struct {
+	common.Response
+	NextToken    string
+	TagResources struct {
+		TagResource []TagResourceItemType
+	}
+}
+
+type TagResourceItemType struct {
+	ResourceId   string
+	ResourceType TagResourceType
+	TagKey       string
+	TagValue     string
+}
+
+// ListTagResources lists the tags of resources, one item per resource and
+// tag. nextToken is the NextToken of the next page or empty for the last page.
+//
+// You can read doc at https://help.aliyun.com/document_detail/110426.html
+func (client *Client) ListTagResources(args *ListTagResourcesArgs) (items []TagResourceItemType, nextToken string, err error) {
+	response := ListTagResourcesResponse{}
+	err = client.Invoke("ListTagResources", args, &response)
+	if err != nil {
+		return nil, "", err
+	}
+	return response.TagResources.TagResource, response.NextToken, nil
+}
+
+// ListResourcesByTags returns the ids of the resources having all the tags,
+// grouped by resource type. The resource types must be the ones of the client,
+// ECS or VPC. At least one tag is required.
+func (client *Client) ListResourcesByTags(regionId common.Region, resourceTypes []TagResourceType, tags map[string]string) (map[TagResourceType][]string, error) {
+	if len(tags) == 0 {
+		return nil, common.GetClientErrorFromString("No tags to list the resources by")
+	}
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var tagFilter []TagType
+	for _, k := range keys {
+		tagFilter = append(tagFilter, TagType{Key: k, Value: tags[k]})
+	}
+
+	resources := make(map[TagResourceType][]string)
+	for _, resourceType := range resourceTypes {
+		seen := make(map[string]bool)
+		args := &ListTagResourcesArgs{
+			RegionId:     regionId,
+			ResourceType: resourceType,
+			Tag:          tagFilter,
+		}
+		for {
+			items, nextToken, err := client.ListTagResources(args)
+			if err != nil {
+				return nil, err
+			}
+			for _, item := range items {
+				if !seen[item.ResourceId] {
+					seen[item.ResourceId] = true
+					resources[resourceType] = append(resources[resourceType], item.ResourceId)
+				}
+			}
+			if nextToken == "" {
+				break
+			}
+			args.NextToken = nextToken
+		}
+	}
+	return resources, nil
+}
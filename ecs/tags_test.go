@@ -1,7 +1,12 @@
 package ecs
 
 import (
+	"fmt"
+	"net/http"
+	"net/url"
 	"testing"
+
+	"github.com/denverdino/aliyungo/util"
 )
 
 var TestTags = map[string]string{
@@ -96,3 +101,95 @@ func TestRemoveTags(t *testing.T) {
 	}
 
 }
+
+func TestTagResourcesArgs(t *testing.T) {
+	values := util.ConvertToQueryValues(&TagResourcesArgs{
+		RegionId:     "cn-hangzhou",
+		ResourceType: TagResourceInstance,
+		ResourceId:   []string{"i-1", "i-2"},
+		Tag:          []TagType{{Key: "env", Value: "test"}, {Key: "team", Value: "sdk"}},
+	})
+	expected := map[string]string{
+		"RegionId":     "cn-hangzhou",
+		"ResourceType": "instance",
+		"ResourceId.1": "i-1",
+		"ResourceId.2": "i-2",
+		"Tag.1.Key":    "env",
+		"Tag.1.Value":  "test",
+		"Tag.2.Key":    "team",
+		"Tag.2.Value":  "sdk",
+	}
+	for key, value := range expected {
+		if values.Get(key) != value {
+			t.Errorf("Expected %s=%q, got %q", key, value, values.Get(key))
+		}
+	}
+	if len(values) != len(expected) {
+		t.Errorf("Unexpected query %v", values)
+	}
+
+	values = util.ConvertToQueryValues(&UntagResourcesArgs{
+		RegionId:     "cn-hangzhou",
+		ResourceType: TagResourceVpc,
+		ResourceId:   []string{"vpc-1"},
+		TagKey:       []string{"env", "team"},
+	})
+	expected = map[string]string{
+		"RegionId":     "cn-hangzhou",
+		"ResourceType": "VPC",
+		"ResourceId.1": "vpc-1",
+		"TagKey.1":     "env",
+		"TagKey.2":     "team",
+		"All":          "false",
+	}
+	for key, value := range expected {
+		if values.Get(key) != value {
+			t.Errorf("Expected %s=%q, got %q", key, value, values.Get(key))
+		}
+	}
+	if len(values) != len(expected) {
+		t.Errorf("Unexpected query %v", values)
+	}
+}
+
+func TestListResourcesByTags(t *testing.T) {
+	client, recorder := newOfflineTestClient(func(query url.Values) (int, interface{}) {
+		if query.Get("Tag.1.Key") != "env" || query.Get("Tag.2.Key") != "team" {
+			t.Errorf("Unexpected tag filter %v", query)
+		}
+		response := ListTagResourcesResponse{}
+		item := func(id string, key string) TagResourceItemType {
+			return TagResourceItemType{ResourceId: id, ResourceType: TagResourceType(query.Get("ResourceType")), TagKey: key}
+		}
+		switch query.Get("ResourceType") + "/" + query.Get("NextToken") {
+		case "instance/":
+			response.TagResources.TagResource = []TagResourceItemType{item("i-1", "env"), item("i-1", "team"), item("i-2", "env")}
+			response.NextToken = "page-2"
+		case "instance/page-2":
+			response.TagResources.TagResource = []TagResourceItemType{item("i-2", "team"), item("i-3", "env")}
+		}
+		return http.StatusOK, response
+	})
+
+	resources, err := client.ListResourcesByTags("cn-hangzhou",
+		[]TagResourceType{TagResourceInstance, TagResourceDisk}, map[string]string{"team": "sdk", "env": "test"})
+	if err != nil {
+		t.Fatalf("Failed to list resources: %v", err)
+	}
+	if ids := fmt.Sprint(resources[TagResourceInstance]); ids != "[i-1 i-2 i-3]" {
+		t.Errorf("Unexpected instances %s", ids)
+	}
+	if len(resources[TagResourceDisk]) != 0 {
+		t.Errorf("Unexpected disks %v", resources[TagResourceDisk])
+	}
+	if len(recorder.requests) != 3 {
+		t.Errorf("Unexpected requests %v", recorder.actions())
+	}
+
+	if _, err := client.ListResourcesByTags("cn-hangzhou", []TagResourceType{TagResourceInstance}, nil); err == nil {
+		t.Errorf("Expected an error without tags")
+	}
+	if len(recorder.requests) != 3 {
+		t.Errorf("Unexpected request without tags")
+	}
+}
@@ -0,0 +1,223 @@
+package ecs
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"strings"
+)
+
+// Content types of the parts of cloud-init user data
+const (
+	UserDataCloudConfig   = "text/cloud-config"
+	UserDataShellScript   = "text/x-shellscript"
+	UserDataIncludeURL    = "text/x-include-url"
+	UserDataCloudBoothook = "text/cloud-boothook"
+)
+
+// MaxUserDataSize is the maximum size of the user data of an instance before Base64 encoding
+const MaxUserDataSize = 16 * 1024
+
+// UserDataPart is a part of a multi-part MIME cloud-init document
+type UserDataPart struct {
+	ContentType string
+	Filename    string
+	Content     string
+}
+
+// UserDataBuilder composes multi-part MIME cloud-init user data
+//
+//	userData, err := ecs.NewUserDataBuilder().
+//		AddCloudConfig("#cloud-config\npackages:\n  - nginx\n").
+//		AddShellScript("setup.sh", "#!/bin/sh\nsystemctl start nginx\n").
+//		Build()
+//	args := &ecs.CreateInstanceArgs{UserData: userData, ...}
+type UserDataBuilder struct {
+	parts []UserDataPart
+
+	// Gzip compresses the document when it exceeds MaxUserDataSize, cloud-init
+	// decompresses gzip user data
+	Gzip bool
+}
+
+func NewUserDataBuilder() *UserDataBuilder {
+	return &UserDataBuilder{}
+}
+
+func (b *UserDataBuilder) AddPart(part UserDataPart) *UserDataBuilder {
+	b.parts = append(b.parts, part)
+	return b
+}
+
+// AddCloudConfig adds a cloud-config YAML document, with the "#cloud-config" header added when missing
+func (b *UserDataBuilder) AddCloudConfig(config string) *UserDataBuilder {
+	if !strings.HasPrefix(config, "#cloud-config") {
+		config = "#cloud-config\n" + config
+	}
+	return b.AddPart(UserDataPart{ContentType: UserDataCloudConfig, Filename: "cloud-config.yaml", Content: config})
+}
+
+// AddShellScript adds a script run once at the first boot
+func (b *UserDataBuilder) AddShellScript(filename, script string) *UserDataBuilder {
+	return b.AddPart(UserDataPart{ContentType: UserDataShellScript, Filename: filename, Content: script})
+}
+
+// AddIncludeURLs adds URLs of documents downloaded and processed by cloud-init
+func (b *UserDataBuilder) AddIncludeURLs(urls ...string) *UserDataBuilder {
+	return b.AddPart(UserDataPart{ContentType: UserDataIncludeURL, Content: strings.Join(urls, "\n") + "\n"})
+}
+
+// Build returns the multi-part MIME document, to be set as the UserData of
+// CreateInstanceArgs which encodes it to Base64. It fails when the document
+// exceeds MaxUserDataSize, after compression with Gzip.
+func (b *UserDataBuilder) Build() (string, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for _, part := range b.parts {
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", mime.FormatMediaType(part.ContentType, map[string]string{"charset": "us-ascii"}))
+		header.Set("MIME-Version", "1.0")
+		header.Set("Content-Transfer-Encoding", "7bit")
+		if part.Filename != "" {
+			header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": part.Filename}))
+		}
+		pw, err := w.CreatePart(header)
+		if err != nil {
+			return "", err
+		}
+		if _, err := pw.Write([]byte(part.Content)); err != nil {
+			return "", err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+
+	var doc bytes.Buffer
+	fmt.Fprintf(&doc, "Content-Type: %s\r\nMIME-Version: 1.0\r\n\r\n",
+		mime.FormatMediaType("multipart/mixed", map[string]string{"boundary": w.Boundary()}))
+	doc.Write(body.Bytes())
+
+	data := doc.Bytes()
+	if len(data) > MaxUserDataSize && b.Gzip {
+		var compressed bytes.Buffer
+		gw := gzip.NewWriter(&compressed)
+		if _, err := gw.Write(data); err != nil {
+			return "", err
+		}
+		if err := gw.Close(); err != nil {
+			return "", err
+		}
+		data = compressed.Bytes()
+	}
+	if len(data) > MaxUserDataSize {
+		return "", fmt.Errorf("user data of %d bytes exceeds the limit of %d bytes", len(data), MaxUserDataSize)
+	}
+	return string(data), nil
+}
+
+// Parts decodes the Base64 user data returned by DescribeUserdata into its parts
+func (item *DescribeUserdataItemType) Parts() ([]UserDataPart, error) {
+	return DecodeUserData(item.UserData)
+}
+
+// DecodeUserData decodes Base64 user data, which may be compressed with gzip,
+// into its parts. User data which is not a multi-part MIME document is a
+// single part with the content type of its header line, such as "#!" for a
+// shell script.
+func DecodeUserData(userData string) ([]UserDataPart, error) {
+	data, err := base64.StdEncoding.DecodeString(userData)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		data, err = ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	if !bytes.HasPrefix(data, []byte("Content-Type:")) && !bytes.HasPrefix(data, []byte("MIME-Version:")) {
+		return []UserDataPart{{ContentType: detectUserDataType(string(data)), Content: string(data)}}, nil
+	}
+
+	r := bufio.NewReader(bytes.NewReader(data))
+	header, err := textproto.NewReader(r).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		content, err := decodeUserDataPart(r, header.Get("Content-Transfer-Encoding"))
+		if err != nil {
+			return nil, err
+		}
+		return []UserDataPart{{ContentType: mediaType, Content: content}}, nil
+	}
+
+	var parts []UserDataPart
+	mr := multipart.NewReader(r, params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err != nil {
+			if err == io.EOF {
+				return parts, nil
+			}
+			return nil, err
+		}
+		contentType, _, _ := mime.ParseMediaType(p.Header.Get("Content-Type"))
+		content, err := decodeUserDataPart(p, p.Header.Get("Content-Transfer-Encoding"))
+		if err != nil {
+			return nil, err
+		}
+		if contentType == "" {
+			contentType = detectUserDataType(content)
+		}
+		parts = append(parts, UserDataPart{ContentType: contentType, Filename: p.FileName(), Content: content})
+	}
+}
+
+func decodeUserDataPart(r io.Reader, encoding string) (string, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	if strings.EqualFold(encoding, "base64") {
+		data, err = base64.StdEncoding.DecodeString(string(bytes.Join(bytes.Fields(data), nil)))
+		if err != nil {
+			return "", err
+		}
+	}
+	return string(data), nil
+}
+
+func detectUserDataType(content string) string {
+	switch {
+	case strings.HasPrefix(content, "#cloud-config"):
+		return UserDataCloudConfig
+	case strings.HasPrefix(content, "#include"):
+		return UserDataIncludeURL
+	case strings.HasPrefix(content, "#cloud-boothook"):
+		return UserDataCloudBoothook
+	case strings.HasPrefix(content, "#!"):
+		return UserDataShellScript
+	}
+	return "text/plain"
+}
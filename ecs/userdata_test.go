@@ -0,0 +1,63 @@
+package ecs
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestUserDataBuilder(t *testing.T) {
+	userData, err := NewUserDataBuilder().
+		AddCloudConfig("packages:\n  - nginx\n").
+		AddShellScript("setup.sh", "#!/bin/sh\necho hello\n").
+		AddIncludeURLs("https://example.com/a.yaml").
+		Build()
+	if err != nil {
+		t.Fatalf("Failed to build user data: %v", err)
+	}
+
+	parts, err := DecodeUserData(base64.StdEncoding.EncodeToString([]byte(userData)))
+	if err != nil {
+		t.Fatalf("Failed to decode user data: %v", err)
+	}
+	expected := []UserDataPart{
+		{ContentType: UserDataCloudConfig, Filename: "cloud-config.yaml", Content: "#cloud-config\npackages:\n  - nginx\n"},
+		{ContentType: UserDataShellScript, Filename: "setup.sh", Content: "#!/bin/sh\necho hello\n"},
+		{ContentType: UserDataIncludeURL, Content: "https://example.com/a.yaml\n"},
+	}
+	if len(parts) != len(expected) {
+		t.Fatalf("Unexpected parts %+v", parts)
+	}
+	for i := range expected {
+		if parts[i] != expected[i] {
+			t.Errorf("Unexpected part %d: %+v", i, parts[i])
+		}
+	}
+}
+
+func TestUserDataBuilderLimit(t *testing.T) {
+	script := "#!/bin/sh\n" + strings.Repeat("echo hello\n", 2000)
+
+	if _, err := NewUserDataBuilder().AddShellScript("big.sh", script).Build(); err == nil {
+		t.Errorf("Expected an error for user data over %d bytes", MaxUserDataSize)
+	}
+
+	builder := NewUserDataBuilder().AddShellScript("big.sh", script)
+	builder.Gzip = true
+	userData, err := builder.Build()
+	if err != nil {
+		t.Fatalf("Failed to build compressed user data: %v", err)
+	}
+	item := DescribeUserdataItemType{UserData: base64.StdEncoding.EncodeToString([]byte(userData))}
+	parts, err := item.Parts()
+	if err != nil || len(parts) != 1 || parts[0].Content != script {
+		t.Errorf("Unexpected parts %v %v", parts, err)
+	}
+}
+
+func TestDecodeUserDataScript(t *testing.T) {
+	parts, err := DecodeUserData(base64.StdEncoding.EncodeToString([]byte("#!/bin/bash\nuptime\n")))
+	if err != nil || len(parts) != 1 || parts[0].ContentType != UserDataShellScript {
+		t.Errorf("Unexpected parts %v %v", parts, err)
+	}
+}
@@ -1,7 +1,7 @@
 package ecs
 
 import (
-	"time"
+	"context"
 
 	"github.com/denverdino/aliyungo/common"
 	"github.com/denverdino/aliyungo/util"
@@ -12,6 +12,8 @@ type CreateVpcArgs struct {
 	CidrBlock   string //192.168.0.0/16 or 172.16.0.0/16 (default)
 	VpcName     string
 	Description string
+	EnableIpv6  bool   // Allocate an IPv6 /56 CIDR block to the VPC
+	Ipv6Isp     string // BGP (default) or a single-line ISP of the IPv6 CIDR block
 	ClientToken string
 }
 
@@ -77,6 +79,7 @@ type VpcSetType struct {
 		VSwitchId []string
 	}
 	CidrBlock           string
+	Ipv6CidrBlock       string
 	VRouterId           string
 	Description         string
 	IsDefault           bool
@@ -148,19 +151,13 @@ func (client *Client) WaitForVpcAvailable(regionId common.Region, vpcId string,
 		RegionId: regionId,
 		VpcId:    vpcId,
 	}
-	for {
+	waiter := common.NewWaiter(timeout, DefaultWaitForInterval, func() (interface{}, error) {
 		vpcs, _, err := client.DescribeVpcs(&args)
-		if err != nil {
-			return err
-		}
-		if len(vpcs) > 0 && vpcs[0].Status == VpcStatusAvailable {
-			break
-		}
-		timeout = timeout - DefaultWaitForInterval
-		if timeout <= 0 {
-			return common.GetClientErrorFromString("Timeout")
-		}
-		time.Sleep(DefaultWaitForInterval * time.Second)
-	}
-	return nil
+		return vpcs, err
+	}, func(result interface{}) bool {
+		vpcs := result.([]VpcSetType)
+		return len(vpcs) > 0 && vpcs[0].Status == VpcStatusAvailable
+	})
+	_, err := waiter.Wait(context.Background())
+	return err
 }
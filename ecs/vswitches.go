@@ -1,7 +1,7 @@
 package ecs
 
 import (
-	"time"
+	"context"
 
 	"github.com/denverdino/aliyungo/common"
 	"github.com/denverdino/aliyungo/util"
@@ -14,6 +14,10 @@ type CreateVSwitchArgs struct {
 	VSwitchName string
 	Description string
 	ClientToken string
+
+	// Enables IPv6 on a VSwitch of an IPv6 VPC with the last 8 bits (0-255)
+	// of its /64 CIDR block in the /56 CIDR block of the VPC
+	Ipv6CidrBlock *int
 }
 
 type CreateVSwitchResponse struct {
@@ -74,6 +78,7 @@ type VSwitchSetType struct {
 	VpcId                   string
 	Status                  VSwitchStatus // enum Pending | Available
 	CidrBlock               string
+	Ipv6CidrBlock           string
 	ZoneId                  string
 	AvailableIpAddressCount int
 	Description             string
@@ -143,22 +148,18 @@ func (client *Client) WaitForVSwitchAvailable(vpcId string, vswitchId string, ti
 		VpcId:     vpcId,
 		VSwitchId: vswitchId,
 	}
-	for {
+	waiter := common.NewWaiter(timeout, DefaultWaitForInterval, func() (interface{}, error) {
 		vswitches, _, err := client.DescribeVSwitches(&args)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		if len(vswitches) == 0 {
-			return common.GetClientErrorFromString("Not found")
-		}
-		if vswitches[0].Status == VSwitchStatusAvailable {
-			break
+			return nil, common.GetClientErrorFromString("Not found")
 		}
-		timeout = timeout - DefaultWaitForInterval
-		if timeout <= 0 {
-			return common.GetClientErrorFromString("Timeout")
-		}
-		time.Sleep(DefaultWaitForInterval * time.Second)
-	}
-	return nil
+		return vswitches[0], nil
+	}, func(result interface{}) bool {
+		return result.(VSwitchSetType).Status == VSwitchStatusAvailable
+	})
+	_, err := waiter.Wait(context.Background())
+	return err
 }
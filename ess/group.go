@@ -1,7 +1,7 @@
 package ess
 
 import (
-	"time"
+	"context"
 
 	"github.com/denverdino/aliyungo/common"
 )
@@ -321,31 +321,23 @@ func (client *Client) WaitForScalingGroup(regionId common.Region, groupId string
 	if timeout <= 0 {
 		timeout = DefaultWaitTimeout
 	}
-	for {
+	waiter := common.NewWaiter(timeout, DefaultWaitForInterval, func() (interface{}, error) {
 		sgs, _, err := client.DescribeScalingGroups(&DescribeScalingGroupsArgs{
 			RegionId:       regionId,
 			ScalingGroupId: []string{groupId},
 		})
 		if err != nil {
-			return err
+			return nil, err
 		}
-
-		if timeout <= 0 {
-			return common.GetClientErrorFromString("Timeout")
-		}
-
-		timeout = timeout - DefaultWaitForInterval
-		time.Sleep(DefaultWaitForInterval * time.Second)
-
 		if len(sgs) < 1 {
-			return common.GetClientErrorFromString("Not found")
+			return nil, common.GetClientErrorFromString("Not found")
 		}
-		if sgs[0].LifecycleState == status {
-			break
-		}
-
-	}
-	return nil
+		return sgs[0], nil
+	}, func(result interface{}) bool {
+		return result.(ScalingGroupItemType).LifecycleState == status
+	})
+	_, err := waiter.Wait(context.Background())
+	return err
 }
 
 type DescribeScalingActivitiesRequest struct {
@@ -3,13 +3,13 @@ module github.com/denverdino/aliyungo
 go 1.15
 
 require (
-	github.com/golang/protobuf v1.5.2 // indirect
-	github.com/magiconair/properties v1.8.6 // indirect
-	github.com/opentracing/opentracing-go v1.2.0 // indirect
+	github.com/golang/protobuf v1.5.2
+	github.com/magiconair/properties v1.8.6
+	github.com/opentracing/opentracing-go v1.2.0
 	github.com/pkg/errors v0.9.1 // indirect
-	github.com/stretchr/testify v1.7.1 // indirect
-	github.com/uber/jaeger-client-go v2.30.0+incompatible // indirect
+	github.com/stretchr/testify v1.7.1
+	github.com/uber/jaeger-client-go v2.30.0+incompatible
 	github.com/uber/jaeger-lib v2.4.1+incompatible // indirect
 	go.uber.org/atomic v1.9.0 // indirect
-	golang.org/x/text v0.3.7 // indirect
+	golang.org/x/text v0.3.7
 )
@@ -46,6 +46,8 @@ const (
 	VSWITCH_ID         = "vswitch-id"
 	ZONE               = "zone-id"
 	RAM_SECURITY       = "ram/security-credentials"
+
+	SPOT_TERMINATION_TIME = "instance/spot/termination-time"
 )
 
 type IMetaDataRequest interface {
@@ -379,10 +381,10 @@ func (vpc *MetaDataRequest) send() (string, error) {
 	if err != nil {
 		return "", err
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("Aliyun Metadata API Error: Status Code: %d", resp.StatusCode)
+		return "", &StatusError{StatusCode: resp.StatusCode}
 	}
-	defer resp.Body.Close()
 
 	data, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
@@ -391,6 +393,22 @@ func (vpc *MetaDataRequest) send() (string, error) {
 	return string(data), nil
 }
 
+// StatusError is returned when the metadata server replies with a status other than 200
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("Aliyun Metadata API Error: Status Code: %d", e.StatusCode)
+}
+
+// IsNotFound reports whether err is a 404 of the metadata server, returned for the
+// resources which are not set on the instance
+func IsNotFound(err error) bool {
+	e, ok := err.(*StatusError)
+	return ok && e.StatusCode == http.StatusNotFound
+}
+
 type TimeoutError interface {
 	error
 	Timeout() bool // Is the error a timeout?
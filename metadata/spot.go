@@ -0,0 +1,115 @@
+package metadata
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SpotTerminationTime returns the time at which the spot instance will be
+// reclaimed. ok is false when the instance is not scheduled for reclamation.
+func (m *MetaData) SpotTerminationTime() (terminationTime time.Time, ok bool, err error) {
+	var data ResultList
+	err = m.New().Resource(SPOT_TERMINATION_TIME).Do(&data)
+	if err != nil {
+		if IsNotFound(err) {
+			return time.Time{}, false, nil
+		}
+		return time.Time{}, false, err
+	}
+	terminationTime, err = time.Parse(time.RFC3339, strings.TrimSpace(data.result[0]))
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return terminationTime, true, nil
+}
+
+// Default polling interval of a SpotTerminationWatcher. The reclamation
+// notice is given about 5 minutes before the instance is released.
+const DefaultSpotTerminationInterval = 5 * time.Second
+
+// SpotTerminationWatcher polls the metadata of a spot instance for the notice
+// of its reclamation
+//
+//	watcher := metadata.NewSpotTerminationWatcher(metadata.NewMetaData(nil))
+//	ctx, cancel := watcher.Watch(ctx) // ctx is cancelled on the notice
+//	defer cancel()
+//	<-ctx.Done()
+//	if terminationTime, ok := watcher.TerminationTime(); ok {
+//		// the instance is reclaimed at terminationTime
+//	}
+type SpotTerminationWatcher struct {
+	metadata *MetaData
+
+	mu              sync.Mutex
+	terminationTime time.Time
+
+	Interval time.Duration // DefaultSpotTerminationInterval by default
+
+	// OnTermination is called once with the termination time of the instance
+	OnTermination func(terminationTime time.Time)
+
+	// OnError is called with the errors of the metadata requests, which do not stop the watcher
+	OnError func(err error)
+}
+
+func NewSpotTerminationWatcher(metadata *MetaData) *SpotTerminationWatcher {
+	return &SpotTerminationWatcher{metadata: metadata}
+}
+
+// Run polls the metadata until the instance is scheduled for reclamation,
+// calls OnTermination and returns the termination time. It returns ctx.Err()
+// when ctx is done first.
+func (w *SpotTerminationWatcher) Run(ctx context.Context) (time.Time, error) {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = DefaultSpotTerminationInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		terminationTime, ok, err := w.metadata.SpotTerminationTime()
+		if err != nil {
+			if w.OnError != nil {
+				w.OnError(err)
+			}
+		} else if ok {
+			w.mu.Lock()
+			w.terminationTime = terminationTime
+			w.mu.Unlock()
+			if w.OnTermination != nil {
+				w.OnTermination(terminationTime)
+			}
+			return terminationTime, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return time.Time{}, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// TerminationTime returns the termination time of the instance once the
+// watcher got the reclamation notice. ok is false until then, which tells a
+// context of Watch cancelled by the parent context from a reclamation.
+func (w *SpotTerminationWatcher) TerminationTime() (terminationTime time.Time, ok bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.terminationTime, !w.terminationTime.IsZero()
+}
+
+// Watch runs the watcher in the background and returns a context which is
+// cancelled when the instance is scheduled for reclamation, or when ctx is
+// done or cancel is called. TerminationTime tells the reclamation apart.
+func (w *SpotTerminationWatcher) Watch(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		defer cancel()
+		w.Run(ctx)
+	}()
+	return ctx, cancel
+}
@@ -0,0 +1,83 @@
+package metadata
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSpotTerminationTime(t *testing.T) {
+	meta := NewMockMetaData(nil, func(resource string) (string, error) {
+		return "", &StatusError{StatusCode: 404}
+	})
+	_, ok, err := meta.SpotTerminationTime()
+	if err != nil || ok {
+		t.Errorf("Unexpected termination notice: %v %v", ok, err)
+	}
+
+	meta = NewMockMetaData(nil, func(resource string) (string, error) {
+		return "2015-01-05T18:02:00Z", nil
+	})
+	terminationTime, ok, err := meta.SpotTerminationTime()
+	if err != nil || !ok || !terminationTime.Equal(time.Date(2015, 1, 5, 18, 2, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected termination notice: %v %v %v", terminationTime, ok, err)
+	}
+}
+
+func TestSpotTerminationWatcher(t *testing.T) {
+	var polls int32
+	meta := NewMockMetaData(nil, func(resource string) (string, error) {
+		if resource != SPOT_TERMINATION_TIME {
+			t.Errorf("Unexpected resource %s", resource)
+		}
+		if atomic.AddInt32(&polls, 1) < 3 {
+			return "", &StatusError{StatusCode: 404}
+		}
+		return "2015-01-05T18:02:00Z", nil
+	})
+
+	watcher := NewSpotTerminationWatcher(meta)
+	watcher.Interval = time.Millisecond
+	if _, ok := watcher.TerminationTime(); ok {
+		t.Error("Unexpected termination before the notice")
+	}
+	notified := make(chan time.Time, 1)
+	watcher.OnTermination = func(terminationTime time.Time) {
+		notified <- terminationTime
+	}
+
+	ctx, cancel := watcher.Watch(context.Background())
+	defer cancel()
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("The context is not cancelled on the termination notice")
+	}
+	if terminationTime := <-notified; terminationTime.IsZero() {
+		t.Error("Unexpected termination time")
+	}
+	if terminationTime, ok := watcher.TerminationTime(); !ok || !terminationTime.Equal(time.Date(2015, 1, 5, 18, 2, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected termination time %v %v", terminationTime, ok)
+	}
+	if atomic.LoadInt32(&polls) != 3 {
+		t.Errorf("Unexpected polls %d", polls)
+	}
+}
+
+func TestSpotTerminationWatcherParentCancelled(t *testing.T) {
+	meta := NewMockMetaData(nil, func(resource string) (string, error) {
+		return "", &StatusError{StatusCode: 404}
+	})
+	watcher := NewSpotTerminationWatcher(meta)
+	watcher.Interval = time.Millisecond
+
+	parent, cancelParent := context.WithCancel(context.Background())
+	ctx, cancel := watcher.Watch(parent)
+	defer cancel()
+	cancelParent()
+	<-ctx.Done()
+	if _, ok := watcher.TerminationTime(); ok {
+		t.Error("Expected no termination when the parent context is cancelled")
+	}
+}
@@ -0,0 +1,195 @@
+package mns
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MessageHandler processes a received message. Returning nil deletes the
+// message, returning an error leaves it in the queue to be redelivered once
+// its visibility timeout expires.
+type MessageHandler func(ctx context.Context, msg *MsgReceive) error
+
+type ConsumerConfig struct {
+	Workers           int           // Number of messages handled concurrently, 4 by default
+	WaitSeconds       int           // Long polling wait of each receive (1-30), 30 by default
+	VisibilityTimeout int           // Seconds a message stays invisible per extension, 60 by default
+	ExtendInterval    time.Duration // Max interval of the extensions while handling, half VisibilityTimeout by default
+	ErrorBackoff      time.Duration // Pause after a failed receive, 1s by default
+
+	// OnError is called with the errors of receive, delete and visibility
+	// extension calls and of the handlers
+	OnError func(err error)
+}
+
+// Consumer long polls a queue and dispatches the messages to a handler
+//
+//	consumer := mns.NewConsumer(client.Queue("orders"), handle, &mns.ConsumerConfig{Workers: 8})
+//	err := consumer.Run(ctx) // returns once ctx is cancelled and the running handlers are done
+type Consumer struct {
+	queue   *Queue
+	handler MessageHandler
+	config  ConsumerConfig
+}
+
+func NewConsumer(queue *Queue, handler MessageHandler, config *ConsumerConfig) *Consumer {
+	c := &Consumer{
+		queue:   queue,
+		handler: handler,
+	}
+	if config != nil {
+		c.config = *config
+	}
+	if c.config.Workers <= 0 {
+		c.config.Workers = 4
+	}
+	if c.config.WaitSeconds <= 0 || c.config.WaitSeconds > 30 {
+		c.config.WaitSeconds = 30
+	}
+	if c.config.VisibilityTimeout <= 0 {
+		c.config.VisibilityTimeout = 60
+	}
+	if c.config.ExtendInterval <= 0 {
+		c.config.ExtendInterval = time.Duration(c.config.VisibilityTimeout) * time.Second / 2
+	}
+	if c.config.ErrorBackoff <= 0 {
+		c.config.ErrorBackoff = time.Second
+	}
+	return c
+}
+
+// Run receives and handles messages until ctx is cancelled. It stops polling
+// at once and waits for the running handlers, which keep a context that is
+// not cancelled by ctx, before returning ctx.Err().
+func (c *Consumer) Run(ctx context.Context) error {
+	slots := make(chan struct{}, c.config.Workers)
+	var workers sync.WaitGroup
+	defer workers.Wait()
+
+	for {
+		// Only receive as many messages as there are idle workers, so no
+		// message waits for a worker while its visibility timeout runs
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case slots <- struct{}{}:
+		}
+		n := 1
+	acquire:
+		for n < MaxBatchSize {
+			select {
+			case slots <- struct{}{}:
+				n++
+			default:
+				break acquire
+			}
+		}
+
+		messages, err := c.queue.BatchReceiveMessageWithContext(ctx, n, c.config.WaitSeconds)
+		for i := len(messages); i < n; i++ {
+			<-slots
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			c.onError(err)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(c.config.ErrorBackoff):
+			}
+			continue
+		}
+
+		for i := range messages {
+			msg := messages[i]
+			workers.Add(1)
+			go func() {
+				defer func() {
+					<-slots
+					workers.Done()
+				}()
+				c.handle(&msg)
+			}()
+		}
+	}
+}
+
+func (c *Consumer) handle(msg *MsgReceive) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	receiptHandle := msg.ReceiptHandle
+	nextVisibleTime := msg.NextVisibleTime
+	extended := make(chan struct{})
+	go func() {
+		defer close(extended)
+		timer := time.NewTimer(c.extendDelay(nextVisibleTime, time.Now()))
+		defer timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+				result, err := c.queue.ChangeMessageVisibility(receiptHandle, c.config.VisibilityTimeout)
+				if err == nil {
+					receiptHandle = result.ReceiptHandle
+					nextVisibleTime = result.NextVisibleTime
+				} else {
+					c.onError(fmt.Errorf("mns: failed to extend visibility of message %s: %v", msg.MessageId, err))
+				}
+				timer.Reset(c.extendDelay(nextVisibleTime, time.Now()))
+			}
+		}
+	}()
+
+	err := c.callHandler(ctx, msg)
+	cancel()
+	<-extended
+
+	if err != nil {
+		c.onError(fmt.Errorf("mns: failed to handle message %s: %v", msg.MessageId, err))
+		return
+	}
+	if err := c.queue.DeleteMessage(receiptHandle); err != nil {
+		c.onError(fmt.Errorf("mns: failed to delete message %s: %v", msg.MessageId, err))
+	}
+}
+
+// Shortest delay between two visibility extensions of a message
+const minExtendDelay = 100 * time.Millisecond
+
+// extendDelay returns the delay before extending the visibility of a message
+// which becomes visible again at nextVisibleTime, in milliseconds. The message
+// is extended halfway to nextVisibleTime, and at most after ExtendInterval.
+func (c *Consumer) extendDelay(nextVisibleTime int64, now time.Time) time.Duration {
+	delay := c.config.ExtendInterval
+	if nextVisibleTime > 0 {
+		remaining := time.Unix(0, nextVisibleTime*int64(time.Millisecond)).Sub(now)
+		if remaining/2 < delay {
+			delay = remaining / 2
+		}
+	}
+	if delay < minExtendDelay {
+		delay = minExtendDelay
+	}
+	return delay
+}
+
+func (c *Consumer) callHandler(ctx context.Context, msg *MsgReceive) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return c.handler(ctx, msg)
+}
+
+func (c *Consumer) onError(err error) {
+	if c.config.OnError != nil {
+		c.config.OnError(err)
+	}
+}
@@ -0,0 +1,177 @@
+package mns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConsumer(t *testing.T) {
+	var mu sync.Mutex
+	pending := []string{"ok-1", "ok-2", "fail", "slow"}
+	deleted := map[string]bool{}
+	extended := 0
+
+	client, closeServer := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		query := r.URL.Query()
+		switch r.Method {
+		case http.MethodGet:
+			n, _ := strconv.Atoi(query.Get("numOfMessages"))
+			if len(pending) == 0 {
+				mu.Unlock()
+				time.Sleep(10 * time.Millisecond)
+				mu.Lock()
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`<Error><Code>MessageNotExist</Code><Message>empty</Message></Error>`))
+				return
+			}
+			if n > len(pending) {
+				n = len(pending)
+			}
+			body := "<Messages>"
+			for _, id := range pending[:n] {
+				body += fmt.Sprintf("<Message><MessageId>%s</MessageId><ReceiptHandle>%s</ReceiptHandle><MessageBody>%s</MessageBody></Message>", id, id, id)
+			}
+			pending = pending[n:]
+			w.Write([]byte(body + "</Messages>"))
+		case http.MethodPut:
+			extended++
+			w.Write([]byte("<ChangeVisibility><ReceiptHandle>" + query.Get("receiptHandle") + "-extended</ReceiptHandle></ChangeVisibility>"))
+		case http.MethodDelete:
+			deleted[query.Get("ReceiptHandle")] = true
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	defer closeServer()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var handled sync.WaitGroup
+	handled.Add(4)
+	consumer := NewConsumer(client.Queue("queue"), func(ctx context.Context, msg *MsgReceive) error {
+		defer handled.Done()
+		switch msg.MessageBody {
+		case "fail":
+			return errors.New("failed")
+		case "slow":
+			time.Sleep(100 * time.Millisecond)
+		}
+		return nil
+	}, &ConsumerConfig{
+		Workers:        2,
+		WaitSeconds:    1,
+		ExtendInterval: 30 * time.Millisecond,
+	})
+
+	done := make(chan error)
+	go func() {
+		done <- consumer.Run(ctx)
+	}()
+	handled.Wait()
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !deleted["ok-1"] || !deleted["ok-2"] || deleted["fail"] {
+		t.Errorf("Unexpected deleted messages: %v", deleted)
+	}
+	slowDeleted := false
+	for handle := range deleted {
+		slowDeleted = slowDeleted || strings.HasPrefix(handle, "slow-extended")
+	}
+	if extended == 0 || !slowDeleted {
+		t.Errorf("Expected the slow message to be extended and deleted with the new handle: %d %v", extended, deleted)
+	}
+}
+
+func TestConsumerExtendsBeforeNextVisibleTime(t *testing.T) {
+	var mu sync.Mutex
+	received := false
+	var deadline, firstExtension time.Time
+
+	client, closeServer := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodGet:
+			if received {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`<Error><Code>MessageNotExist</Code><Message>empty</Message></Error>`))
+				return
+			}
+			received = true
+			// The queue has a shorter visibility timeout than the consumer config
+			deadline = time.Now().Add(300 * time.Millisecond)
+			fmt.Fprintf(w, "<Messages><Message><MessageId>slow</MessageId><ReceiptHandle>slow</ReceiptHandle><MessageBody>slow</MessageBody><NextVisibleTime>%d</NextVisibleTime></Message></Messages>",
+				deadline.UnixNano()/int64(time.Millisecond))
+		case http.MethodPut:
+			if firstExtension.IsZero() {
+				firstExtension = time.Now()
+			}
+			fmt.Fprintf(w, "<ChangeVisibility><ReceiptHandle>slow</ReceiptHandle><NextVisibleTime>%d</NextVisibleTime></ChangeVisibility>",
+				time.Now().Add(time.Minute).UnixNano()/int64(time.Millisecond))
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	defer closeServer()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	handled := make(chan struct{})
+	consumer := NewConsumer(client.Queue("queue"), func(ctx context.Context, msg *MsgReceive) error {
+		defer close(handled)
+		time.Sleep(500 * time.Millisecond)
+		return nil
+	}, &ConsumerConfig{
+		Workers:           1,
+		WaitSeconds:       1,
+		VisibilityTimeout: 60,
+	})
+
+	done := make(chan error)
+	go func() {
+		done <- consumer.Run(ctx)
+	}()
+	<-handled
+	cancel()
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	if firstExtension.IsZero() || firstExtension.After(deadline) {
+		t.Errorf("Expected the message to be extended before %v, got %v", deadline, firstExtension)
+	}
+}
+
+func TestConsumerExtendDelay(t *testing.T) {
+	consumer := NewConsumer(nil, nil, &ConsumerConfig{VisibilityTimeout: 60})
+	now := time.Now()
+	millis := func(t time.Time) int64 {
+		return t.UnixNano() / int64(time.Millisecond)
+	}
+	cases := []struct {
+		nextVisibleTime int64
+		expected        time.Duration
+	}{
+		{0, 30 * time.Second},
+		{millis(now.Add(10 * time.Second)), 5 * time.Second},
+		{millis(now.Add(10 * time.Minute)), 30 * time.Second},
+		{millis(now.Add(-time.Second)), minExtendDelay},
+	}
+	for _, c := range cases {
+		delay := consumer.extendDelay(c.nextVisibleTime, now)
+		if diff := delay - c.expected; diff < -time.Millisecond || diff > time.Millisecond {
+			t.Errorf("Expected delay %v for %d, got %v", c.expected, c.nextVisibleTime, delay)
+		}
+	}
+}
@@ -0,0 +1,635 @@
+// Package mnstest provides an in-memory MNS queue service for the tests of
+// code using the mns package.
+//
+//	server := mnstest.NewServer("id", "secret")
+//	defer server.Close()
+//	server.CreateQueue("orders")
+//	client := server.NewClient()
+//	client.Queue("orders").SendMessage(mns.Message{MessageBody: "hello", DelaySeconds: 60})
+//	server.Advance(time.Minute) // the message becomes visible
+package mnstest
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/denverdino/aliyungo/mns"
+)
+
+const (
+	DefaultVisibilityTimeout = 30
+	DefaultPriority          = 8
+)
+
+// Message is the state of a message in a queue of the server
+type Message struct {
+	MessageId        string
+	MessageBody      string
+	Priority         int
+	EnqueueTime      time.Time
+	NextVisibleTime  time.Time
+	FirstDequeueTime time.Time
+	DequeueCount     int
+	ReceiptHandle    string // Handle of the last receive, empty until then
+	Deleted          bool
+}
+
+type queue struct {
+	attributes mns.QueueInfo
+	messages   []*Message
+}
+
+// Server serves the MNS queue APIs from memory. Requests must be signed with
+// the credentials of the server. The time of the server starts at the local
+// time and only moves forward with Advance, while long polling receives wait
+// for real time.
+type Server struct {
+	*httptest.Server
+	AccessKeyId     string
+	AccessKeySecret string
+
+	mu       sync.Mutex
+	now      time.Time
+	queues   map[string]*queue
+	sequence int
+	changed  chan struct{} // closed and replaced on every change
+}
+
+// NewServer starts a server accepting the requests signed with the given credentials
+func NewServer(accessKeyId, accessKeySecret string) *Server {
+	s := &Server{
+		AccessKeyId:     accessKeyId,
+		AccessKeySecret: accessKeySecret,
+		now:             time.Now(),
+		queues:          map[string]*queue{},
+		changed:         make(chan struct{}),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Endpoint is the endpoint of the server for mns.NewClient
+func (s *Server) Endpoint() string {
+	return strings.TrimPrefix(s.URL, "http://")
+}
+
+// NewClient returns a client of the server signing with its credentials
+func (s *Server) NewClient() *mns.Client {
+	return mns.NewClient(s.AccessKeyId, s.AccessKeySecret, s.Endpoint())
+}
+
+// Now returns the current time of the server
+func (s *Server) Now() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.now
+}
+
+// Advance moves the time of the server forward, making the delayed and the
+// invisible messages visible once their time is reached
+func (s *Server) Advance(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = s.now.Add(d)
+	s.notify()
+}
+
+// CreateQueue creates a queue with the default attributes
+func (s *Server) CreateQueue(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.createQueue(name, &mns.QueueAttributes{})
+}
+
+// Messages returns a copy of the messages of a queue, including the deleted ones, in sending order
+func (s *Server) Messages(queueName string) []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q := s.queues[queueName]
+	if q == nil {
+		return nil
+	}
+	messages := make([]Message, len(q.messages))
+	for i, msg := range q.messages {
+		messages[i] = *msg
+	}
+	return messages
+}
+
+func (s *Server) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidArgument", err.Error())
+		return
+	}
+	if err := s.verifySignature(r); err != nil {
+		writeError(w, http.StatusForbidden, "SignatureDoesNotMatch", err.Error())
+		return
+	}
+
+	// /queues, /queues/$queueName or /queues/$queueName/messages
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if parts[0] != "queues" || len(parts) > 3 || (len(parts) == 3 && parts[2] != "messages") {
+		writeError(w, http.StatusNotFound, "InvalidArgument", "unsupported resource "+r.URL.Path)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(parts) == 1 {
+		s.listQueues(w, r)
+		return
+	}
+	name := parts[1]
+	if len(parts) == 2 {
+		s.serveQueue(w, r, name, body)
+		return
+	}
+
+	q := s.queues[name]
+	if q == nil {
+		writeError(w, http.StatusNotFound, "QueueNotExist", "The queue name you provided is not exist.")
+		return
+	}
+	query := r.URL.Query()
+	switch r.Method {
+	case http.MethodPost:
+		s.sendMessages(w, q, body)
+	case http.MethodGet:
+		s.receiveMessages(w, r, q)
+	case http.MethodDelete:
+		s.deleteMessages(w, q, query.Get("ReceiptHandle"), body)
+	case http.MethodPut:
+		s.changeVisibility(w, q, query.Get("receiptHandle"), query.Get("visibilityTimeout"))
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "InvalidArgument", r.Method)
+	}
+}
+
+// verifySignature checks the Authorization header the way MNS does, see mns.Client.SignRequest
+func (s *Server) verifySignature(r *http.Request) error {
+	auth := r.Header.Get("Authorization")
+	prefix := "MNS " + s.AccessKeyId + ":"
+	if !strings.HasPrefix(auth, prefix) {
+		return fmt.Errorf("unexpected authorization %q", auth)
+	}
+
+	var headers []string
+	for k := range r.Header {
+		if lower := strings.ToLower(k); strings.HasPrefix(lower, mns.HeaderMNSPrefix) {
+			headers = append(headers, lower+":"+r.Header.Get(k))
+		}
+	}
+	sort.Strings(headers)
+
+	resource := r.URL.Path
+	if query := r.URL.Query(); len(query) > 0 {
+		var params []string
+		for k := range query {
+			params = append(params, url.QueryEscape(k)+"="+url.QueryEscape(query.Get(k)))
+		}
+		sort.Strings(params)
+		resource += "?" + strings.Join(params, "&")
+	}
+
+	signString := r.Method + "\n" + r.Header.Get("Content-MD5") + "\n" + r.Header.Get("Content-Type") + "\n" +
+		r.Header.Get("Date") + "\n" + strings.Join(headers, "\n") + "\n" + resource
+	mac := hmac.New(sha1.New, []byte(s.AccessKeySecret))
+	mac.Write([]byte(signString))
+	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(strings.TrimPrefix(auth, prefix)), []byte(expected)) {
+		return fmt.Errorf("the signature of %q does not match", signString)
+	}
+	return nil
+}
+
+func (s *Server) createQueue(name string, attributes *mns.QueueAttributes) {
+	now := s.now.Unix()
+	q := &queue{attributes: mns.QueueInfo{
+		QueueName:              name,
+		QueueURL:               s.URL + "/queues/" + name,
+		CreateTime:             now,
+		MaximumMessageSize:     65536,
+		MessageRetentionPeriod: 345600,
+		VisibilityTimeout:      DefaultVisibilityTimeout,
+	}}
+	setAttributes(&q.attributes, attributes)
+	s.queues[name] = q
+}
+
+func setAttributes(info *mns.QueueInfo, attributes *mns.QueueAttributes) {
+	if attributes.DelaySeconds != nil {
+		info.DelaySeconds = *attributes.DelaySeconds
+	}
+	if attributes.MaximumMessageSize != nil {
+		info.MaximumMessageSize = *attributes.MaximumMessageSize
+	}
+	if attributes.MessageRetentionPeriod != nil {
+		info.MessageRetentionPeriod = *attributes.MessageRetentionPeriod
+	}
+	if attributes.VisibilityTimeout != nil {
+		info.VisibilityTimeout = *attributes.VisibilityTimeout
+	}
+	if attributes.PollingWaitSeconds != nil {
+		info.PollingWaitSeconds = *attributes.PollingWaitSeconds
+	}
+	if attributes.LoggingEnabled != nil {
+		info.LoggingEnabled = *attributes.LoggingEnabled
+	}
+}
+
+func (s *Server) serveQueue(w http.ResponseWriter, r *http.Request, name string, body []byte) {
+	q := s.queues[name]
+	switch r.Method {
+	case http.MethodPut:
+		attributes := &mns.QueueAttributes{}
+		if len(body) > 0 {
+			if err := xml.Unmarshal(body, attributes); err != nil {
+				writeError(w, http.StatusBadRequest, "MalformedXML", err.Error())
+				return
+			}
+		}
+		if r.URL.Query().Get("metaoverride") == "true" {
+			if q == nil {
+				writeError(w, http.StatusNotFound, "QueueNotExist", "The queue name you provided is not exist.")
+				return
+			}
+			setAttributes(&q.attributes, attributes)
+			q.attributes.LastModifyTime = s.now.Unix()
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if q != nil {
+			writeError(w, http.StatusConflict, "QueueAlreadyExist", "The queue you want to create is already exist.")
+			return
+		}
+		s.createQueue(name, attributes)
+		w.Header().Set("Location", s.URL+"/queues/"+name)
+		w.WriteHeader(http.StatusCreated)
+	case http.MethodGet:
+		if q == nil {
+			writeError(w, http.StatusNotFound, "QueueNotExist", "The queue name you provided is not exist.")
+			return
+		}
+		writeXML(w, http.StatusOK, struct {
+			XMLName xml.Name `xml:"Queue"`
+			mns.QueueInfo
+		}{QueueInfo: s.queueInfo(q)})
+	case http.MethodDelete:
+		delete(s.queues, name)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "InvalidArgument", r.Method)
+	}
+}
+
+func (s *Server) queueInfo(q *queue) mns.QueueInfo {
+	info := q.attributes
+	for _, msg := range q.messages {
+		switch {
+		case msg.Deleted:
+		case msg.DequeueCount == 0 && msg.NextVisibleTime.After(s.now):
+			info.DelayMessages++
+		case msg.NextVisibleTime.After(s.now):
+			info.InactiveMessages++
+		default:
+			info.ActiveMessages++
+		}
+	}
+	return info
+}
+
+func (s *Server) listQueues(w http.ResponseWriter, r *http.Request) {
+	prefix := r.Header.Get("x-mns-prefix")
+	withMeta := r.Header.Get("x-mns-with-meta") == "true"
+	var names []string
+	for name := range s.queues {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	resp := struct {
+		XMLName xml.Name        `xml:"Queues"`
+		Queues  []mns.QueueInfo `xml:"Queue"`
+	}{}
+	for _, name := range names {
+		info := mns.QueueInfo{QueueURL: s.queues[name].attributes.QueueURL}
+		if withMeta {
+			info = s.queueInfo(s.queues[name])
+		}
+		resp.Queues = append(resp.Queues, info)
+	}
+	writeXML(w, http.StatusOK, resp)
+}
+
+type sentMessage struct {
+	XMLName        xml.Name `xml:"Message"`
+	MessageId      string   `xml:"MessageId,omitempty"`
+	MessageBodyMD5 string   `xml:"MessageBodyMD5,omitempty"`
+	ErrorCode      string   `xml:"ErrorCode,omitempty"`
+	ErrorMessage   string   `xml:"ErrorMessage,omitempty"`
+}
+
+func (s *Server) sendMessages(w http.ResponseWriter, q *queue, body []byte) {
+	var batch struct {
+		XMLName  xml.Name
+		Messages []mns.Message `xml:"Message"`
+	}
+	if err := xml.Unmarshal(body, &batch); err == nil && batch.XMLName.Local == "Messages" {
+		resp := struct {
+			XMLName  xml.Name      `xml:"Messages"`
+			Messages []sentMessage `xml:"Message"`
+		}{}
+		status := http.StatusCreated
+		for _, message := range batch.Messages {
+			sent, err := s.sendMessage(q, message)
+			if err != nil {
+				sent = sentMessage{ErrorCode: "InvalidArgument", ErrorMessage: err.Error()}
+				status = http.StatusInternalServerError
+			}
+			resp.Messages = append(resp.Messages, sent)
+		}
+		writeXML(w, status, resp)
+		return
+	}
+
+	var message mns.Message
+	if err := xml.Unmarshal(body, &message); err != nil {
+		writeError(w, http.StatusBadRequest, "MalformedXML", err.Error())
+		return
+	}
+	sent, err := s.sendMessage(q, message)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "InvalidArgument", err.Error())
+		return
+	}
+	writeXML(w, http.StatusCreated, sent)
+}
+
+func (s *Server) sendMessage(q *queue, message mns.Message) (sentMessage, error) {
+	if len(message.MessageBody) > q.attributes.MaximumMessageSize {
+		return sentMessage{}, fmt.Errorf("message body exceeds %d bytes", q.attributes.MaximumMessageSize)
+	}
+	delay := q.attributes.DelaySeconds
+	if message.DelaySeconds > 0 {
+		delay = message.DelaySeconds
+	}
+	priority := message.Priority
+	if priority == 0 {
+		priority = DefaultPriority
+	}
+
+	s.sequence++
+	msg := &Message{
+		MessageId:       fmt.Sprintf("%016X", s.sequence),
+		MessageBody:     message.MessageBody,
+		Priority:        priority,
+		EnqueueTime:     s.now,
+		NextVisibleTime: s.now.Add(time.Duration(delay) * time.Second),
+	}
+	q.messages = append(q.messages, msg)
+	s.notify()
+	return sentMessage{MessageId: msg.MessageId, MessageBodyMD5: bodyMD5(msg.MessageBody)}, nil
+}
+
+type receivedMessage struct {
+	XMLName          xml.Name `xml:"Message"`
+	MessageId        string   `xml:"MessageId"`
+	ReceiptHandle    string   `xml:"ReceiptHandle,omitempty"`
+	MessageBodyMD5   string   `xml:"MessageBodyMD5"`
+	MessageBody      string   `xml:"MessageBody"`
+	EnqueueTime      int64    `xml:"EnqueueTime"`
+	NextVisibleTime  int64    `xml:"NextVisibleTime,omitempty"`
+	FirstDequeueTime int64    `xml:"FirstDequeueTime,omitempty"`
+	DequeueCount     int      `xml:"DequeueCount"`
+	Priority         int      `xml:"Priority"`
+}
+
+func (s *Server) receiveMessages(w http.ResponseWriter, r *http.Request, q *queue) {
+	query := r.URL.Query()
+	peek := query.Get("peekonly") == "true"
+	batch := query.Get("numOfMessages") != ""
+	n := 1
+	if batch {
+		n, _ = strconv.Atoi(query.Get("numOfMessages"))
+		if n < 1 || n > mns.MaxBatchSize {
+			writeError(w, http.StatusBadRequest, "InvalidArgument", "numOfMessages is out of range")
+			return
+		}
+	}
+	wait := q.attributes.PollingWaitSeconds
+	if query.Get("waitseconds") != "" {
+		wait, _ = strconv.Atoi(query.Get("waitseconds"))
+	}
+
+	// Long polling waits for real time, woken up by sends and Advance
+	deadline := time.NewTimer(time.Duration(wait) * time.Second)
+	defer deadline.Stop()
+	var messages []*Message
+	for {
+		messages = s.visibleMessages(q, n)
+		if len(messages) > 0 || peek || wait == 0 {
+			break
+		}
+		changed := s.changed
+		s.mu.Unlock()
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+		case <-deadline.C:
+			wait = 0
+		}
+		s.mu.Lock()
+		if r.Context().Err() != nil {
+			return
+		}
+		if s.queues[q.attributes.QueueName] != q {
+			writeError(w, http.StatusNotFound, "QueueNotExist", "The queue name you provided is not exist.")
+			return
+		}
+	}
+	if len(messages) == 0 {
+		writeError(w, http.StatusNotFound, "MessageNotExist", "Message not exist.")
+		return
+	}
+
+	received := make([]receivedMessage, len(messages))
+	for i, msg := range messages {
+		if !peek {
+			s.sequence++
+			msg.DequeueCount++
+			if msg.FirstDequeueTime.IsZero() {
+				msg.FirstDequeueTime = s.now
+			}
+			msg.ReceiptHandle = fmt.Sprintf("%s-%d", msg.MessageId, s.sequence)
+			msg.NextVisibleTime = s.now.Add(time.Duration(q.attributes.VisibilityTimeout) * time.Second)
+		}
+		received[i] = receivedMessage{
+			MessageId:      msg.MessageId,
+			MessageBodyMD5: bodyMD5(msg.MessageBody),
+			MessageBody:    msg.MessageBody,
+			EnqueueTime:    millis(msg.EnqueueTime),
+			DequeueCount:   msg.DequeueCount,
+			Priority:       msg.Priority,
+		}
+		if !peek {
+			received[i].ReceiptHandle = msg.ReceiptHandle
+			received[i].NextVisibleTime = millis(msg.NextVisibleTime)
+			received[i].FirstDequeueTime = millis(msg.FirstDequeueTime)
+		}
+	}
+	if !peek {
+		s.notify()
+	}
+
+	if !batch {
+		writeXML(w, http.StatusOK, received[0])
+		return
+	}
+	writeXML(w, http.StatusOK, struct {
+		XMLName  xml.Name          `xml:"Messages"`
+		Messages []receivedMessage `xml:"Message"`
+	}{Messages: received})
+}
+
+// visibleMessages returns up to n visible messages by priority, then sending order
+func (s *Server) visibleMessages(q *queue, n int) []*Message {
+	var visible []*Message
+	for _, msg := range q.messages {
+		if !msg.Deleted && !msg.NextVisibleTime.After(s.now) {
+			visible = append(visible, msg)
+		}
+	}
+	sort.SliceStable(visible, func(i, j int) bool {
+		return visible[i].Priority < visible[j].Priority
+	})
+	if len(visible) > n {
+		visible = visible[:n]
+	}
+	return visible
+}
+
+// findHandle returns the message of a receipt handle which is still valid
+func (s *Server) findHandle(q *queue, receiptHandle string) (*Message, string, string) {
+	for _, msg := range q.messages {
+		if msg.ReceiptHandle == receiptHandle && receiptHandle != "" {
+			if msg.Deleted {
+				return nil, "MessageNotExist", "Message not exist."
+			}
+			if !msg.NextVisibleTime.After(s.now) {
+				return nil, "MessageNotExist", "The receipt handle has expired."
+			}
+			return msg, "", ""
+		}
+	}
+	return nil, "ReceiptHandleError", "The receipt handle you provide is not valid."
+}
+
+func (s *Server) deleteMessages(w http.ResponseWriter, q *queue, receiptHandle string, body []byte) {
+	if receiptHandle != "" {
+		msg, code, message := s.findHandle(q, receiptHandle)
+		if msg == nil {
+			writeError(w, http.StatusNotFound, code, message)
+			return
+		}
+		msg.Deleted = true
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	var batch struct {
+		ReceiptHandles []string `xml:"ReceiptHandle"`
+	}
+	if err := xml.Unmarshal(body, &batch); err != nil || len(batch.ReceiptHandles) == 0 {
+		writeError(w, http.StatusBadRequest, "MalformedXML", "no receipt handle")
+		return
+	}
+	resp := struct {
+		XMLName xml.Name              `xml:"Errors"`
+		Errors  []mns.BatchErrorEntry `xml:"Error"`
+	}{}
+	for _, handle := range batch.ReceiptHandles {
+		msg, code, message := s.findHandle(q, handle)
+		if msg == nil {
+			resp.Errors = append(resp.Errors, mns.BatchErrorEntry{ErrorCode: code, ErrorMessage: message, ReceiptHandle: handle})
+			continue
+		}
+		msg.Deleted = true
+	}
+	if len(resp.Errors) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeXML(w, http.StatusNotFound, resp)
+}
+
+func (s *Server) changeVisibility(w http.ResponseWriter, q *queue, receiptHandle, visibilityTimeout string) {
+	timeout, err := strconv.Atoi(visibilityTimeout)
+	if err != nil || timeout < 1 {
+		writeError(w, http.StatusBadRequest, "InvalidArgument", "visibilityTimeout is out of range")
+		return
+	}
+	msg, code, message := s.findHandle(q, receiptHandle)
+	if msg == nil {
+		writeError(w, http.StatusNotFound, code, message)
+		return
+	}
+	s.sequence++
+	msg.ReceiptHandle = fmt.Sprintf("%s-%d", msg.MessageId, s.sequence)
+	msg.NextVisibleTime = s.now.Add(time.Duration(timeout) * time.Second)
+	writeXML(w, http.StatusOK, struct {
+		XMLName xml.Name `xml:"ChangeVisibility"`
+		mns.ChangeVisibility
+	}{ChangeVisibility: mns.ChangeVisibility{ReceiptHandle: msg.ReceiptHandle, NextVisibleTime: millis(msg.NextVisibleTime)}})
+}
+
+func bodyMD5(body string) string {
+	sum := md5.Sum([]byte(body))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func millis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func writeXML(w http.ResponseWriter, status int, v interface{}) {
+	data, err := xml.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/xml;charset=utf-8")
+	w.WriteHeader(status)
+	w.Write([]byte(xml.Header))
+	w.Write(data)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeXML(w, status, struct {
+		XMLName xml.Name `xml:"Error"`
+		Xmlns   string   `xml:"xmlns,attr"`
+		Code    string   `xml:"Code"`
+		Message string   `xml:"Message"`
+	}{Xmlns: mns.MNSXmlNamespace, Code: code, Message: message})
+}
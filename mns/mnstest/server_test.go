@@ -0,0 +1,87 @@
+package mnstest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/denverdino/aliyungo/mns"
+)
+
+func TestServer(t *testing.T) {
+	server := NewServer("id", "secret")
+	defer server.Close()
+	client := server.NewClient()
+
+	visibilityTimeout := 60
+	if err := client.CreateQueue("orders", &mns.QueueAttributes{VisibilityTimeout: &visibilityTimeout}); err != nil {
+		t.Fatalf("Failed to create queue: %v", err)
+	}
+	queue := client.Queue("orders")
+	if _, err := queue.SendMessage(mns.Message{MessageBody: "delayed", DelaySeconds: 30}); err != nil {
+		t.Fatalf("Failed to send: %v", err)
+	}
+	if _, err := queue.BatchSendMessage(mns.Message{MessageBody: "low", Priority: 10}, mns.Message{MessageBody: "high", Priority: 1}); err != nil {
+		t.Fatalf("Failed to send: %v", err)
+	}
+
+	messages, err := queue.BatchReceiveMessage(16, 0)
+	if err != nil || len(messages) != 2 || messages[0].MessageBody != "high" || messages[1].MessageBody != "low" {
+		t.Fatalf("Unexpected messages: %+v %v", messages, err)
+	}
+	if err := queue.DeleteMessage(messages[0].ReceiptHandle); err != nil {
+		t.Errorf("Failed to delete: %v", err)
+	}
+
+	// The undeleted message is redelivered once its visibility timeout expires
+	server.Advance(30 * time.Second)
+	messages, err = queue.BatchReceiveMessage(16, 0)
+	if err != nil || len(messages) != 1 || messages[0].MessageBody != "delayed" {
+		t.Fatalf("Unexpected messages: %+v %v", messages, err)
+	}
+	server.Advance(30 * time.Second)
+	messages, err = queue.BatchReceiveMessage(16, 0)
+	if err != nil || len(messages) != 1 || messages[0].MessageBody != "low" || messages[0].DequeueCount != 2 {
+		t.Fatalf("Unexpected messages: %+v %v", messages, err)
+	}
+	handle := messages[0].ReceiptHandle
+	visibility, err := queue.ChangeMessageVisibility(handle, 10)
+	if err != nil {
+		t.Fatalf("Failed to change visibility: %v", err)
+	}
+	if err := queue.DeleteMessage(handle); err == nil {
+		t.Errorf("Expected the previous receipt handle to be invalid")
+	}
+	if err := queue.DeleteMessage(visibility.ReceiptHandle); err != nil {
+		t.Errorf("Failed to delete: %v", err)
+	}
+
+	sent := server.Messages("orders")
+	if len(sent) != 3 || !sent[1].Deleted || !sent[2].Deleted || sent[0].Deleted {
+		t.Errorf("Unexpected messages in server: %+v", sent)
+	}
+
+	// Long polling returns once a message is sent
+	received := make(chan []mns.MsgReceive)
+	go func() {
+		messages, _ := queue.BatchReceiveMessageWithContext(context.Background(), 1, 30)
+		received <- messages
+	}()
+	time.Sleep(10 * time.Millisecond)
+	queue.SendMessage(mns.Message{MessageBody: "later"})
+	select {
+	case messages := <-received:
+		if len(messages) != 1 || messages[0].MessageBody != "later" {
+			t.Errorf("Unexpected messages: %+v", messages)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Long polling did not return")
+	}
+
+	wrong := mns.NewClient("id", "wrong", server.Endpoint())
+	if _, err := wrong.Queue("orders").SendMessage(mns.Message{MessageBody: "x"}); err == nil {
+		t.Errorf("Expected a signature error")
+	} else if e, ok := err.(*mns.Error); !ok || e.Code != "SignatureDoesNotMatch" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
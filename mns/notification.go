@@ -0,0 +1,210 @@
+package mns
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/md5"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Max difference between the Date of a notification and the local time,
+// older notifications are rejected as replays
+const NotificationDateTolerance = 15 * time.Minute
+
+// Client downloading the signing certificates
+var certificateClient = &http.Client{Timeout: 10 * time.Second}
+
+// SigningCertificateURLReg matches the URLs of the MNS signing certificates,
+// which are in the mnstest bucket of Alibaba Cloud. Any other certificate
+// could be hosted by an attacker, e.g. in an OSS bucket under aliyuncs.com.
+var SigningCertificateURLReg = regexp.MustCompile(`^http(|s)://mnstest\.oss-[0-9a-z-]+\.aliyuncs\.com/x509_public_certificate\.pem$`)
+
+// Notification is the message pushed to an HTTP endpoint subscription. With
+// the SIMPLIFIED content format only Message is set.
+type Notification struct {
+	TopicOwner       string `xml:"TopicOwner" json:"TopicOwner"`
+	TopicName        string `xml:"TopicName" json:"TopicName"`
+	Subscriber       string `xml:"Subscriber" json:"Subscriber"`
+	SubscriptionName string `xml:"SubscriptionName" json:"SubscriptionName"`
+	MessageId        string `xml:"MessageId" json:"MessageId"`
+	MessageMD5       string `xml:"MessageMD5" json:"MessageMD5"`
+	MessageTag       string `xml:"MessageTag" json:"MessageTag"`
+	Message          string `xml:"Message" json:"Message"`
+	PublishTime      int64  `xml:"PublishTime" json:"PublishTime"`
+}
+
+type certificateCache struct {
+	lock        sync.RWMutex
+	certificate map[string]*rsa.PublicKey
+}
+
+var signingCertificates = certificateCache{certificate: map[string]*rsa.PublicKey{}}
+
+// NotificationHandler returns an http.Handler verifying the signature of the
+// notifications pushed by MNS and passing them to handle. The endpoint replies
+// 204 when handle succeeds, and 500 otherwise so that MNS retries the push.
+func NotificationHandler(handle func(notification *Notification) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := verifyNotification(r, body); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		notification, err := DecodeNotification(body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := handle(notification); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+//验证MNS向HTTP Endpoint推送的消息签名, 证书地址取自 x-mns-signing-cert-url 请求头,
+//须匹配 SigningCertificateURLReg, 下载后缓存. 请求体须与 Content-MD5 一致, Date 须在 NotificationDateTolerance 之内.
+//请求体读取后会被重置, 该方法是并发安全的
+func VerifyNotification(r *http.Request) error {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	r.Body.Close()
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return verifyNotification(r, body)
+}
+
+func verifyNotification(r *http.Request, body []byte) error {
+	if err := verifyContentMD5(r.Header.Get("Content-MD5"), body); err != nil {
+		return err
+	}
+	date, err := http.ParseTime(r.Header.Get("Date"))
+	if err != nil {
+		return fmt.Errorf("invalid notification date: %v", err)
+	}
+	if skew := time.Since(date); skew > NotificationDateTolerance || skew < -NotificationDateTolerance {
+		return fmt.Errorf("notification date %s is out of tolerance", r.Header.Get("Date"))
+	}
+
+	certURL, err := base64.StdEncoding.DecodeString(r.Header.Get("x-mns-signing-cert-url"))
+	if err != nil {
+		return err
+	}
+	certificate, err := signingCertificates.get(string(certURL))
+	if err != nil {
+		return err
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(r.Header.Get("Authorization"))
+	if err != nil {
+		return err
+	}
+
+	headers := make(map[string]string)
+	for k := range r.Header {
+		headers[strings.ToLower(k)] = r.Header.Get(k)
+	}
+	signString := r.Method + "\n" + r.Header.Get("Content-MD5") + "\n" + r.Header.Get("Content-Type") + "\n" +
+		r.Header.Get("Date") + "\n" + canonicalizeHeader(headers) + "\n" + r.URL.RequestURI()
+
+	hashed := sha1.Sum([]byte(signString))
+	return rsa.VerifyPKCS1v15(certificate, crypto.SHA1, hashed[:], signature)
+}
+
+// verifyContentMD5 checks the signed Content-MD5 header against the body. The
+// header is the base64 of the MD5 digest, or of its hex form as written by the
+// MNS SDKs.
+func verifyContentMD5(contentMD5 string, body []byte) error {
+	if contentMD5 == "" {
+		return errors.New("notification has no Content-MD5")
+	}
+	sum := md5.Sum(body)
+	if contentMD5 != base64.StdEncoding.EncodeToString(sum[:]) &&
+		contentMD5 != base64.StdEncoding.EncodeToString([]byte(hex.EncodeToString(sum[:]))) {
+		return errors.New("notification body does not match Content-MD5")
+	}
+	return nil
+}
+
+// DecodeNotification decodes the XML or JSON body of a notification, any
+// other body is the message of the SIMPLIFIED content format
+func DecodeNotification(body []byte) (*Notification, error) {
+	notification := &Notification{}
+	trimmed := bytes.TrimSpace(body)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("<?xml")) || bytes.HasPrefix(trimmed, []byte("<Notification")):
+		if err := xml.Unmarshal(trimmed, notification); err != nil {
+			return nil, err
+		}
+	case bytes.HasPrefix(trimmed, []byte("{")) && json.Unmarshal(trimmed, notification) == nil && notification.MessageId != "":
+	default:
+		notification = &Notification{Message: string(body)}
+	}
+	return notification, nil
+}
+
+func (cache *certificateCache) get(certURL string) (*rsa.PublicKey, error) {
+	//判断证书是否来自于阿里云
+	if !SigningCertificateURLReg.MatchString(certURL) {
+		return nil, fmt.Errorf("certificate address error: %s", certURL)
+	}
+
+	cache.lock.RLock()
+	certificate := cache.certificate[certURL]
+	cache.lock.RUnlock()
+	if certificate != nil {
+		return certificate, nil
+	}
+
+	res, err := certificateClient.Get(certURL)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("certificate error: %s", res.Status)
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(res.Body, 64*1024))
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(body)
+	if block == nil {
+		return nil, errors.New("certificate error")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	certificate, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("certificate error: not a RSA public key")
+	}
+
+	cache.lock.Lock()
+	cache.certificate[certURL] = certificate
+	cache.lock.Unlock()
+	return certificate, nil
+}
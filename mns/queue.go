@@ -1,10 +1,14 @@
 package mns
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/xml"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
 //队列接口PATH
@@ -15,38 +19,16 @@ func getPath(queue string) string {
 	return "/queues/" + queue + "/messages"
 }
 
-//发送队列消息
+//发送队列消息, message 为 XML 格式的 Message, 其他内容作为消息体发送. time 参数未使用
 func (queue *Queue) Send(time int64, message []byte) (msg MsgSend, err error) {
-	req := &request{
-		endpoint:    queue.Endpoint,
-		method:      http.MethodPost,
-		path:        getPath(queue.QueueName),
-		payload:     message,
-		contentType: "text/xml",
-		headers:     map[string]string{},
-	}
-
-	response, err := queue.doRequest(req)
-	if err != nil {
-		return
-	}
-
-	defer response.Body.Close()
-	//err = xml.NewDecoder(response.Body).Decode(msg)
-
-	data, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		return
+	var envelope struct {
+		XMLName xml.Name `xml:"Message"`
+		Message
 	}
-	//fmt.Printf("receive message: %s \n", data)
-	err = xml.Unmarshal(data, &msg)
-
-	if err != nil {
-		return
+	if xml.Unmarshal(message, &envelope) != nil {
+		envelope.Message = Message{MessageBody: string(message)}
 	}
-
-	return
-
+	return queue.SendMessage(envelope.Message)
 }
 
 //消费队列消息
@@ -85,6 +67,10 @@ func (queue *Queue) Receive(messageChan chan MsgReceive, errChan chan error) {
 		errChan <- err
 		return
 	}
+	if err = queue.decodeMessage(&rs); err != nil {
+		errChan <- err
+		return
+	}
 
 	messageChan <- rs
 	return
@@ -113,3 +99,299 @@ func (queue *Queue) Delete(receiptHandle string, errChan chan error) {
 	errChan <- nil
 	return
 }
+
+const (
+	MaxBatchSize = 16
+)
+
+//发送消息, 可设置 DelaySeconds 和 Priority. Base64 队列的消息体会先进行 Base64 编码,
+//MNS 返回的 MessageBodyMD5 与消息体不一致时返回 *MD5MismatchError
+func (queue *Queue) SendMessage(message Message) (msg MsgSend, err error) {
+	message.Xmlns = MNSXmlNamespace
+	message.MessageBody = queue.encodeBody(message.MessageBody)
+	data, err := marshalXML(message)
+	if err != nil {
+		return
+	}
+
+	req := &request{
+		endpoint:    queue.Endpoint,
+		method:      http.MethodPost,
+		path:        getPath(queue.QueueName),
+		payload:     data,
+		contentType: "text/xml",
+	}
+	if err = queue.requestWithXmlResponse(req, &msg); err != nil {
+		return
+	}
+	err = checkBodyMD5(msg.MessageId, message.MessageBody, msg.MessageBodyMD5)
+	return
+}
+
+//批量发送消息, 最多16条. 部分消息失败时返回 *BatchError, 结果中失败的消息带有 ErrorCode
+func (queue *Queue) BatchSendMessage(messages ...Message) ([]MsgSend, error) {
+	if len(messages) == 0 || len(messages) > MaxBatchSize {
+		return nil, fmt.Errorf("mns: batch size %d is out of [1, %d]", len(messages), MaxBatchSize)
+	}
+	batch := batchSendRequest{
+		Xmlns:    MNSXmlNamespace,
+		Messages: make([]Message, len(messages)),
+	}
+	for i, message := range messages {
+		message.Xmlns = ""
+		message.MessageBody = queue.encodeBody(message.MessageBody)
+		batch.Messages[i] = message
+	}
+	data, err := marshalXML(batch)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &request{
+		endpoint:    queue.Endpoint,
+		method:      http.MethodPost,
+		path:        getPath(queue.QueueName),
+		payload:     data,
+		contentType: "text/xml",
+	}
+	resp, err := queue.sendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	result := batchSendResponse{}
+	if xml.Unmarshal(body, &result) != nil || len(result.Messages) != len(messages) {
+		if resp.StatusCode == http.StatusCreated {
+			return nil, fmt.Errorf("mns: unexpected batch send response: %s", body)
+		}
+		return nil, decodeError(resp, body)
+	}
+
+	batchErr := &BatchError{StatusCode: resp.StatusCode}
+	var md5Err error
+	for i, msg := range result.Messages {
+		if msg.ErrorCode != "" {
+			batchErr.Errors = append(batchErr.Errors, BatchErrorEntry{
+				Index:        i,
+				ErrorCode:    msg.ErrorCode,
+				ErrorMessage: msg.ErrorMessage,
+			})
+		} else if err := checkBodyMD5(msg.MessageId, batch.Messages[i].MessageBody, msg.MessageBodyMD5); err != nil && md5Err == nil {
+			md5Err = err
+		}
+	}
+	if len(batchErr.Errors) > 0 {
+		return result.Messages, batchErr
+	}
+	return result.Messages, md5Err
+}
+
+//批量消费消息, 最多16条, waitSeconds 为长轮询等待时间(0-30), 小于0时使用队列的 PollingWaitSeconds.
+//队列中没有消息时返回空列表
+func (queue *Queue) BatchReceiveMessage(numOfMessages, waitSeconds int) ([]MsgReceive, error) {
+	return queue.BatchReceiveMessageWithContext(context.Background(), numOfMessages, waitSeconds)
+}
+
+//批量消费消息, ctx 取消时中断长轮询
+func (queue *Queue) BatchReceiveMessageWithContext(ctx context.Context, numOfMessages, waitSeconds int) ([]MsgReceive, error) {
+	if numOfMessages <= 0 || numOfMessages > MaxBatchSize {
+		return nil, fmt.Errorf("mns: batch size %d is out of [1, %d]", numOfMessages, MaxBatchSize)
+	}
+	params := map[string]string{
+		"numOfMessages": strconv.Itoa(numOfMessages),
+	}
+	if waitSeconds >= 0 {
+		params["waitseconds"] = strconv.Itoa(waitSeconds)
+	}
+	return queue.receiveMessages(ctx, params)
+}
+
+//查看消息, 不改变消息状态. 队列中没有消息时返回 nil
+func (queue *Queue) PeekMessage() (*MsgReceive, error) {
+	req := &request{
+		endpoint: queue.Endpoint,
+		method:   http.MethodGet,
+		path:     getPath(queue.QueueName),
+		params: map[string]string{
+			"peekonly": "true",
+		},
+		contentType: "text/xml",
+	}
+
+	msg := &MsgReceive{}
+	if err := queue.requestWithXmlResponse(req, msg); err != nil {
+		if isMessageNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := queue.decodeMessage(msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+//批量查看消息, 最多16条
+func (queue *Queue) BatchPeekMessage(numOfMessages int) ([]MsgReceive, error) {
+	if numOfMessages <= 0 || numOfMessages > MaxBatchSize {
+		return nil, fmt.Errorf("mns: batch size %d is out of [1, %d]", numOfMessages, MaxBatchSize)
+	}
+	return queue.receiveMessages(context.Background(), map[string]string{
+		"peekonly":      "true",
+		"numOfMessages": strconv.Itoa(numOfMessages),
+	})
+}
+
+func (queue *Queue) receiveMessages(ctx context.Context, params map[string]string) ([]MsgReceive, error) {
+	req := &request{
+		ctx:         ctx,
+		endpoint:    queue.Endpoint,
+		method:      http.MethodGet,
+		path:        getPath(queue.QueueName),
+		params:      params,
+		contentType: "text/xml",
+	}
+
+	result := batchReceiveResponse{}
+	if err := queue.requestWithXmlResponse(req, &result); err != nil {
+		if isMessageNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	for i := range result.Messages {
+		if err := queue.decodeMessage(&result.Messages[i]); err != nil {
+			return nil, err
+		}
+	}
+	return result.Messages, nil
+}
+
+//删除消息
+func (queue *Queue) DeleteMessage(receiptHandle string) error {
+	req := &request{
+		endpoint: queue.Endpoint,
+		method:   http.MethodDelete,
+		path:     getPath(queue.QueueName),
+		params: map[string]string{
+			"ReceiptHandle": receiptHandle,
+		},
+		contentType: "text/xml",
+	}
+	return queue.requestWithClose(req)
+}
+
+//批量删除消息, 最多16条. 部分消息删除失败时返回 *BatchError
+func (queue *Queue) BatchDeleteMessage(receiptHandles ...string) error {
+	if len(receiptHandles) == 0 || len(receiptHandles) > MaxBatchSize {
+		return fmt.Errorf("mns: batch size %d is out of [1, %d]", len(receiptHandles), MaxBatchSize)
+	}
+	data, err := marshalXML(batchDeleteRequest{
+		Xmlns:          MNSXmlNamespace,
+		ReceiptHandles: receiptHandles,
+	})
+	if err != nil {
+		return err
+	}
+
+	req := &request{
+		endpoint:    queue.Endpoint,
+		method:      http.MethodDelete,
+		path:        getPath(queue.QueueName),
+		payload:     data,
+		contentType: "text/xml",
+	}
+	resp, err := queue.sendRequest(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	result := batchDeleteErrors{}
+	if xml.Unmarshal(body, &result) != nil || len(result.Errors) == 0 {
+		return decodeError(resp, body)
+	}
+	for i := range result.Errors {
+		result.Errors[i].Index = indexOf(receiptHandles, result.Errors[i].ReceiptHandle)
+	}
+	return &BatchError{StatusCode: resp.StatusCode, Errors: result.Errors}
+}
+
+//修改消息下次可见时间, 返回新的 ReceiptHandle
+func (queue *Queue) ChangeMessageVisibility(receiptHandle string, visibilityTimeout int) (*ChangeVisibility, error) {
+	req := &request{
+		endpoint: queue.Endpoint,
+		method:   http.MethodPut,
+		path:     getPath(queue.QueueName),
+		params: map[string]string{
+			"receiptHandle":     receiptHandle,
+			"visibilityTimeout": strconv.Itoa(visibilityTimeout),
+		},
+		contentType: "text/xml",
+	}
+
+	result := &ChangeVisibility{}
+	if err := queue.requestWithXmlResponse(req, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (queue *Queue) encodeBody(body string) string {
+	if queue.Base64 {
+		return base64.StdEncoding.EncodeToString([]byte(body))
+	}
+	return body
+}
+
+// decodeMessage verifies the MD5 of the received body and decodes it when the queue is Base64
+func (queue *Queue) decodeMessage(msg *MsgReceive) error {
+	if err := checkBodyMD5(msg.MessageId, msg.MessageBody, msg.MessageBodyMD5); err != nil {
+		return err
+	}
+	if queue.Base64 {
+		body, err := base64.StdEncoding.DecodeString(msg.MessageBody)
+		if err != nil {
+			return fmt.Errorf("mns: failed to decode body of message %s: %v", msg.MessageId, err)
+		}
+		msg.MessageBody = string(body)
+	}
+	return nil
+}
+
+// checkBodyMD5 compares the MD5 of body with the MessageBodyMD5 returned by MNS, if any
+func checkBodyMD5(messageId, body, bodyMD5 string) error {
+	if bodyMD5 == "" {
+		return nil
+	}
+	if expected := Md5([]byte(body)); !strings.EqualFold(expected, bodyMD5) {
+		return &MD5MismatchError{MessageId: messageId, Expected: expected, Actual: bodyMD5}
+	}
+	return nil
+}
+
+func isMessageNotExist(err error) bool {
+	e, ok := err.(*Error)
+	return ok && e.Code == "MessageNotExist"
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,147 @@
+package mns
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestBatchOperations(t *testing.T) {
+	var lastBody string
+	var lastRequest *http.Request
+	client, closeServer := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		lastBody = string(data)
+		lastRequest = r
+		query := r.URL.Query()
+		switch {
+		case r.Method == http.MethodPost:
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<Messages xmlns="http://mns.aliyuncs.com/doc/v1/">
+  <Message><MessageId>id-1</MessageId><MessageBodyMD5>0CC175B9C0F1B6A831C399E269772661</MessageBodyMD5></Message>
+  <Message><ErrorCode>MalformedXML</ErrorCode><ErrorMessage>bad</ErrorMessage></Message>
+</Messages>`))
+		case r.Method == http.MethodGet && query.Get("peekonly") == "true":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<Error xmlns="http://mns.aliyuncs.com/doc/v1/"><Code>MessageNotExist</Code><Message>empty</Message></Error>`))
+		case r.Method == http.MethodGet:
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<Messages xmlns="http://mns.aliyuncs.com/doc/v1/">
+  <Message><MessageId>id-1</MessageId><ReceiptHandle>handle-1</ReceiptHandle><MessageBody>a</MessageBody><DequeueCount>1</DequeueCount></Message>
+  <Message><MessageId>id-2</MessageId><ReceiptHandle>handle-2</ReceiptHandle><MessageBody>b</MessageBody><DequeueCount>2</DequeueCount></Message>
+</Messages>`))
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<Errors xmlns="http://mns.aliyuncs.com/doc/v1/">
+  <Error><ErrorCode>ReceiptHandleError</ErrorCode><ErrorMessage>expired</ErrorMessage><ReceiptHandle>handle-2</ReceiptHandle></Error>
+</Errors>`))
+		case r.Method == http.MethodPut:
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<ChangeVisibility xmlns="http://mns.aliyuncs.com/doc/v1/"><ReceiptHandle>handle-3</ReceiptHandle><NextVisibleTime>1250700999</NextVisibleTime></ChangeVisibility>`))
+		}
+	})
+	defer closeServer()
+	queue := client.Queue("queue")
+
+	results, err := queue.BatchSendMessage(Message{MessageBody: "a", DelaySeconds: 10}, Message{MessageBody: "b", Priority: 1})
+	batchErr, ok := err.(*BatchError)
+	if !ok || len(batchErr.Errors) != 1 || batchErr.Errors[0].Index != 1 || batchErr.Errors[0].ErrorCode != "MalformedXML" {
+		t.Fatalf("Expected a partial batch error, got %v", err)
+	}
+	if len(results) != 2 || results[0].MessageId != "id-1" {
+		t.Errorf("Unexpected batch send results: %+v", results)
+	}
+	if !strings.Contains(lastBody, "<DelaySeconds>10</DelaySeconds>") || !strings.Contains(lastBody, "<Priority>1</Priority>") {
+		t.Errorf("Unexpected batch send body: %s", lastBody)
+	}
+	if _, err := queue.BatchSendMessage(make([]Message, 17)...); err == nil {
+		t.Errorf("Expected an error for more than 16 messages")
+	}
+
+	messages, err := queue.BatchReceiveMessage(16, 30)
+	if err != nil {
+		t.Fatalf("Failed to receive messages: %v", err)
+	}
+	if len(messages) != 2 || messages[1].DequeueCount != 2 {
+		t.Errorf("Unexpected messages: %+v", messages)
+	}
+	if lastRequest.URL.Query().Get("waitseconds") != "30" || lastRequest.URL.Query().Get("numOfMessages") != "16" {
+		t.Errorf("Unexpected batch receive query: %s", lastRequest.URL.RawQuery)
+	}
+
+	msg, err := queue.PeekMessage()
+	if err != nil || msg != nil {
+		t.Errorf("Expected no message from empty queue, got %v %v", msg, err)
+	}
+
+	err = queue.BatchDeleteMessage("handle-1", "handle-2")
+	batchErr, ok = err.(*BatchError)
+	if !ok || len(batchErr.Errors) != 1 || batchErr.Errors[0].Index != 1 {
+		t.Errorf("Expected a partial batch delete error, got %v", err)
+	}
+	if !strings.Contains(lastBody, "<ReceiptHandle>handle-1</ReceiptHandle><ReceiptHandle>handle-2</ReceiptHandle>") {
+		t.Errorf("Unexpected batch delete body: %s", lastBody)
+	}
+
+	visibility, err := queue.ChangeMessageVisibility("handle-1", 60)
+	if err != nil {
+		t.Fatalf("Failed to change visibility: %v", err)
+	}
+	if visibility.ReceiptHandle != "handle-3" || lastRequest.URL.Query().Get("visibilityTimeout") != "60" {
+		t.Errorf("Unexpected change visibility result: %+v", visibility)
+	}
+}
+
+func TestBase64AndMD5(t *testing.T) {
+	var lastBody string
+	bodyMD5 := "0733351879B2FA9BD05C7CA3061529C0"
+	client, closeServer := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		lastBody = string(data)
+		switch r.Method {
+		case http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`<Message><MessageId>id-1</MessageId><MessageBodyMD5>` + bodyMD5 + `</MessageBodyMD5></Message>`))
+		case http.MethodGet:
+			w.Write([]byte(`<Messages><Message><MessageId>id-1</MessageId><MessageBodyMD5>` + bodyMD5 +
+				`</MessageBodyMD5><MessageBody>aGVsbG8=</MessageBody><ReceiptHandle>handle-1</ReceiptHandle></Message></Messages>`))
+		}
+	})
+	defer closeServer()
+	queue := client.Queue("queue")
+	queue.Base64 = true
+
+	msg, err := queue.SendMessage(Message{MessageBody: "hello"})
+	if err != nil || msg.MessageId != "id-1" {
+		t.Fatalf("Failed to send message: %+v %v", msg, err)
+	}
+	if !strings.Contains(lastBody, "<MessageBody>aGVsbG8=</MessageBody>") {
+		t.Errorf("Expected a Base64 encoded body, got %s", lastBody)
+	}
+
+	messages, err := queue.BatchReceiveMessage(1, 0)
+	if err != nil || len(messages) != 1 || messages[0].MessageBody != "hello" {
+		t.Fatalf("Unexpected messages: %+v %v", messages, err)
+	}
+
+	bodyMD5 = "5D41402ABC4B2A76B9719D911017C592"
+	_, err = queue.SendMessage(Message{MessageBody: "hello"})
+	if e, ok := err.(*MD5MismatchError); !ok || e.MessageId != "id-1" || e.Actual != bodyMD5 {
+		t.Errorf("Expected a MD5 mismatch on send, got %v", err)
+	}
+	if _, err = queue.BatchReceiveMessage(1, 0); err == nil {
+		t.Errorf("Expected a MD5 mismatch on receive")
+	}
+
+	queue.Base64 = false
+	if _, err = queue.Send(0, []byte("a < b & c")); err == nil {
+		t.Errorf("Expected a MD5 mismatch on send")
+	}
+	if !strings.Contains(lastBody, "<MessageBody>a &lt; b &amp; c</MessageBody>") {
+		t.Errorf("Expected an escaped body, got %s", lastBody)
+	}
+}
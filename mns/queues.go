@@ -0,0 +1,128 @@
+package mns
+
+import (
+	"net/http"
+	"strconv"
+)
+
+//队列管理接口PATH
+//PUT /queues/$queueName HTTP/1.1
+//PUT /queues/$queueName?metaoverride=true HTTP/1.1
+//GET /queues/$queueName HTTP/1.1
+//DELETE /queues/$queueName HTTP/1.1
+//GET /queues HTTP/1.1
+func getQueuePath(queue string) string {
+	return "/queues/" + queue
+}
+
+// Queue returns the queue named name
+func (client *Client) Queue(name string) *Queue {
+	return &Queue{
+		Client:    client,
+		QueueName: name,
+	}
+}
+
+//创建队列
+func (client *Client) CreateQueue(name string, attributes *QueueAttributes) error {
+	if attributes == nil {
+		attributes = &QueueAttributes{}
+	}
+	attributes.Xmlns = MNSXmlNamespace
+	data, err := marshalXML(attributes)
+	if err != nil {
+		return err
+	}
+
+	req := &request{
+		method:      http.MethodPut,
+		path:        getQueuePath(name),
+		payload:     data,
+		contentType: "text/xml",
+	}
+	return client.requestWithClose(req)
+}
+
+//修改队列属性
+func (client *Client) SetQueueAttributes(name string, attributes *QueueAttributes) error {
+	if attributes == nil {
+		attributes = &QueueAttributes{}
+	}
+	attributes.Xmlns = MNSXmlNamespace
+	data, err := marshalXML(attributes)
+	if err != nil {
+		return err
+	}
+
+	req := &request{
+		method: http.MethodPut,
+		path:   getQueuePath(name),
+		params: map[string]string{
+			"metaoverride": "true",
+		},
+		payload:     data,
+		contentType: "text/xml",
+	}
+	return client.requestWithClose(req)
+}
+
+//获取队列属性及消息数
+func (client *Client) GetQueueAttributes(name string) (*QueueInfo, error) {
+	req := &request{
+		method:      http.MethodGet,
+		path:        getQueuePath(name),
+		contentType: "text/xml",
+	}
+
+	info := &QueueInfo{}
+	if err := client.requestWithXmlResponse(req, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+//删除队列
+func (client *Client) DeleteQueue(name string) error {
+	req := &request{
+		method:      http.MethodDelete,
+		path:        getQueuePath(name),
+		contentType: "text/xml",
+	}
+	return client.requestWithClose(req)
+}
+
+//列出队列, 通过 NextMarker 翻页
+func (client *Client) ListQueue(args *ListQueueArgs) (*ListQueueResponse, error) {
+	req := &request{
+		method:      http.MethodGet,
+		path:        "/queues",
+		contentType: "text/xml",
+	}
+	if args != nil {
+		req.headers = listHeaders(args.Prefix, args.Marker, args.RetNumber, args.WithMeta)
+	}
+
+	resp := &ListQueueResponse{}
+	if err := client.requestWithXmlResponse(req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// listHeaders builds the paging headers of the list APIs
+func listHeaders(prefix, marker string, retNumber int, withMeta bool) map[string]string {
+	headers := map[string]string{}
+	if prefix != "" {
+		headers["x-mns-prefix"] = prefix
+	}
+	if marker != "" {
+		headers["x-mns-marker"] = marker
+	}
+	if retNumber > 0 {
+		headers["x-mns-ret-number"] = strconv.Itoa(retNumber)
+	}
+	if withMeta {
+		headers["x-mns-with-meta"] = "true"
+	}
+	return headers
+}
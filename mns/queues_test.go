@@ -0,0 +1,93 @@
+package mns
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newTestServer(t *testing.T, handler http.HandlerFunc) (*Client, func()) {
+	server := httptest.NewServer(handler)
+	client := NewClient("id", "secret", strings.TrimPrefix(server.URL, "http://"))
+	return client, server.Close
+}
+
+func TestQueueManagement(t *testing.T) {
+	var lastBody string
+	var lastRequest *http.Request
+	client, closeServer := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		lastBody = string(data)
+		lastRequest = r
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/queues":
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<Queues xmlns="http://mns.aliyuncs.com/doc/v1/">
+  <Queue><QueueURL>http://endpoint/queues/tenant-1</QueueURL></Queue>
+  <Queue><QueueURL>http://endpoint/queues/tenant-2</QueueURL></Queue>
+  <NextMarker>tenant-3</NextMarker>
+</Queues>`))
+		case r.Method == http.MethodGet:
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<Queue xmlns="http://mns.aliyuncs.com/doc/v1/">
+  <QueueName>tenant-1</QueueName>
+  <VisibilityTimeout>60</VisibilityTimeout>
+  <ActiveMessages>12</ActiveMessages>
+  <InactiveMessages>3</InactiveMessages>
+  <DelayMessages>1</DelayMessages>
+  <LoggingEnabled>True</LoggingEnabled>
+</Queue>`))
+		case r.Method == http.MethodPut:
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	defer closeServer()
+
+	visibilityTimeout := 60
+	err := client.CreateQueue("tenant-1", &QueueAttributes{VisibilityTimeout: &visibilityTimeout})
+	if err != nil {
+		t.Fatalf("Failed to create queue: %v", err)
+	}
+	if !strings.Contains(lastBody, "<VisibilityTimeout>60</VisibilityTimeout>") || strings.Contains(lastBody, "DelaySeconds") {
+		t.Errorf("Unexpected create queue body: %s", lastBody)
+	}
+
+	delaySeconds := 0
+	if err := client.SetQueueAttributes("tenant-1", &QueueAttributes{DelaySeconds: &delaySeconds}); err != nil {
+		t.Fatalf("Failed to set queue attributes: %v", err)
+	}
+	if lastRequest.URL.Query().Get("metaoverride") != "true" || !strings.Contains(lastBody, "<DelaySeconds>0</DelaySeconds>") {
+		t.Errorf("Unexpected set queue attributes request: %s %s", lastRequest.URL, lastBody)
+	}
+
+	if err := client.SetQueueAttributes("tenant-1", nil); err != nil {
+		t.Fatalf("Failed to set nil queue attributes: %v", err)
+	}
+
+	info, err := client.GetQueueAttributes("tenant-1")
+	if err != nil {
+		t.Fatalf("Failed to get queue attributes: %v", err)
+	}
+	if info.QueueName != "tenant-1" || info.ActiveMessages != 12 || info.InactiveMessages != 3 || info.DelayMessages != 1 {
+		t.Errorf("Unexpected queue attributes: %+v", info)
+	}
+
+	list, err := client.ListQueue(&ListQueueArgs{Prefix: "tenant-", RetNumber: 2})
+	if err != nil {
+		t.Fatalf("Failed to list queues: %v", err)
+	}
+	if lastRequest.Header.Get("x-mns-prefix") != "tenant-" || lastRequest.Header.Get("x-mns-ret-number") != "2" {
+		t.Errorf("Unexpected list queue headers: %v", lastRequest.Header)
+	}
+	if len(list.Queues) != 2 || list.NextMarker != "tenant-3" {
+		t.Errorf("Unexpected list queue response: %+v", list)
+	}
+
+	if err := client.DeleteQueue("tenant-1"); err != nil {
+		t.Fatalf("Failed to delete queue: %v", err)
+	}
+}
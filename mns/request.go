@@ -2,9 +2,11 @@ package mns
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"net/url"
@@ -15,6 +17,7 @@ import (
 )
 
 type request struct {
+	ctx         context.Context
 	endpoint    string
 	method      string
 	path        string
@@ -43,6 +46,19 @@ func (req *request) url() string {
 }
 
 func (client *Client) doRequest(req *request) (*http.Response, error) {
+	resp, err := client.sendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != 200 && resp.StatusCode != 201 && resp.StatusCode != 204 {
+		return nil, buildError(resp)
+	}
+	return resp, nil
+}
+
+// sendRequest signs and sends the request, leaving the status code to the caller
+func (client *Client) sendRequest(req *request) (*http.Response, error) {
 
 	payload := req.payload
 
@@ -76,7 +92,11 @@ func (client *Client) doRequest(req *request) (*http.Response, error) {
 		reader = bytes.NewReader(payload)
 	}
 
-	hreq, err := http.NewRequest(req.method, req.url(), reader)
+	ctx := req.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	hreq, err := http.NewRequestWithContext(ctx, req.method, req.url(), reader)
 	if err != nil {
 		return nil, err
 	}
@@ -100,11 +120,40 @@ func (client *Client) doRequest(req *request) (*http.Response, error) {
 	if client.debug {
 		log.Printf("Invoke %s %s %d (%v)", req.method, req.url(), resp.StatusCode, t1.Sub(t0))
 	}
+	return resp, nil
+}
+
+func (client *Client) requestWithXmlResponse(req *request, v interface{}) error {
+	resp, err := client.doRequest(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 && resp.StatusCode != 201 && resp.StatusCode != 204 {
-		return nil, buildError(resp)
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
 	}
-	return resp, nil
+	return xml.Unmarshal(data, v)
+}
+
+func (client *Client) requestWithClose(req *request) error {
+	resp, err := client.doRequest(req)
+	if err != nil {
+		return err
+	}
+
+	resp.Body.Close()
+	return nil
+}
+
+// marshalXML encodes v as the body of a request with the XML declaration
+func marshalXML(v interface{}) ([]byte, error) {
+	data, err := xml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
 }
 
 type Error struct {
@@ -117,17 +166,59 @@ func (err *Error) Error() string {
 	return fmt.Sprintf("aliyun MNS API Error: Status Code: %d Code: %s Message: %s", err.StatusCode, err.Code, err.Message)
 }
 
+// MD5MismatchError is returned when the MessageBodyMD5 computed by MNS does
+// not match the message body sent or received
+type MD5MismatchError struct {
+	MessageId string
+	Expected  string // MD5 of the body on the client side
+	Actual    string // MessageBodyMD5 returned by MNS
+}
+
+func (err *MD5MismatchError) Error() string {
+	return fmt.Sprintf("mns: MD5 mismatch of message %s: expected %s, got %s", err.MessageId, err.Expected, err.Actual)
+}
+
+// BatchErrorEntry is the failure of one entry of a batch operation. Index is
+// the position of the entry in the batch, ReceiptHandle is set for deletions.
+type BatchErrorEntry struct {
+	Index         int    `xml:"-"`
+	ErrorCode     string `xml:"ErrorCode"`
+	ErrorMessage  string `xml:"ErrorMessage"`
+	ReceiptHandle string `xml:"ReceiptHandle"`
+}
+
+// BatchError is returned when some entries of a batch operation failed while
+// the others succeeded
+type BatchError struct {
+	StatusCode int
+	Errors     []BatchErrorEntry
+}
+
+func (err *BatchError) Error() string {
+	if len(err.Errors) == 0 {
+		return fmt.Sprintf("aliyun MNS API Error: Status Code: %d batch failed", err.StatusCode)
+	}
+	first := err.Errors[0]
+	return fmt.Sprintf("aliyun MNS API Error: Status Code: %d %d entries failed, first Code: %s Message: %s",
+		err.StatusCode, len(err.Errors), first.ErrorCode, first.ErrorMessage)
+}
+
 func buildError(resp *http.Response) error {
 	defer resp.Body.Close()
-	err := &Error{}
-	e := xml.NewDecoder(resp.Body).Decode(err)
+	data, e := ioutil.ReadAll(resp.Body)
+	if e != nil {
+		return &Error{StatusCode: resp.StatusCode, Message: e.Error()}
+	}
+	return decodeError(resp, data)
+}
+
+func decodeError(resp *http.Response, data []byte) error {
+	err := &Error{StatusCode: resp.StatusCode}
+	e := xml.Unmarshal(data, err)
 	if e != nil {
 		err.Message = e.Error()
-	} else {
-		err.StatusCode = resp.StatusCode
-		if err.Message == "" {
-			err.Message = resp.Status
-		}
+	} else if err.Message == "" {
+		err.Message = resp.Status
 	}
 
 	return err
@@ -0,0 +1,253 @@
+package mns
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+)
+
+//主题接口PATH
+//PUT /topics/$topicName HTTP/1.1
+//POST /topics/$topicName/messages HTTP/1.1
+//PUT /topics/$topicName/subscriptions/$subscriptionName HTTP/1.1
+func getTopicPath(topic string) string {
+	return "/topics/" + topic
+}
+
+func getSubscriptionPath(topic, subscription string) string {
+	return "/topics/" + topic + "/subscriptions/" + subscription
+}
+
+type Topic struct {
+	*Client
+	TopicName string
+}
+
+// Topic returns the topic named name
+func (client *Client) Topic(name string) *Topic {
+	return &Topic{
+		Client:    client,
+		TopicName: name,
+	}
+}
+
+// QueueEndpoint is the subscription endpoint pushing the messages of a topic to a queue
+func QueueEndpoint(region, accountId, queueName string) string {
+	return fmt.Sprintf("acs:mns:%s:%s:queues/%s", region, accountId, queueName)
+}
+
+//创建主题
+func (client *Client) CreateTopic(name string, attributes *TopicAttributes) error {
+	if attributes == nil {
+		attributes = &TopicAttributes{}
+	}
+	attributes.Xmlns = MNSXmlNamespace
+	data, err := marshalXML(attributes)
+	if err != nil {
+		return err
+	}
+
+	req := &request{
+		method:      http.MethodPut,
+		path:        getTopicPath(name),
+		payload:     data,
+		contentType: "text/xml",
+	}
+	return client.requestWithClose(req)
+}
+
+//修改主题属性
+func (client *Client) SetTopicAttributes(name string, attributes *TopicAttributes) error {
+	attributes.Xmlns = MNSXmlNamespace
+	data, err := marshalXML(attributes)
+	if err != nil {
+		return err
+	}
+
+	req := &request{
+		method: http.MethodPut,
+		path:   getTopicPath(name),
+		params: map[string]string{
+			"metaoverride": "true",
+		},
+		payload:     data,
+		contentType: "text/xml",
+	}
+	return client.requestWithClose(req)
+}
+
+//获取主题属性
+func (client *Client) GetTopicAttributes(name string) (*TopicInfo, error) {
+	req := &request{
+		method:      http.MethodGet,
+		path:        getTopicPath(name),
+		contentType: "text/xml",
+	}
+
+	info := &TopicInfo{}
+	if err := client.requestWithXmlResponse(req, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+//删除主题
+func (client *Client) DeleteTopic(name string) error {
+	req := &request{
+		method:      http.MethodDelete,
+		path:        getTopicPath(name),
+		contentType: "text/xml",
+	}
+	return client.requestWithClose(req)
+}
+
+//列出主题, 通过 NextMarker 翻页
+func (client *Client) ListTopic(args *ListTopicArgs) (*ListTopicResponse, error) {
+	req := &request{
+		method:      http.MethodGet,
+		path:        "/topics",
+		contentType: "text/xml",
+	}
+	if args != nil {
+		req.headers = listHeaders(args.Prefix, args.Marker, args.RetNumber, args.WithMeta)
+	}
+
+	resp := &ListTopicResponse{}
+	if err := client.requestWithXmlResponse(req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+//发布消息
+func (topic *Topic) PublishMessage(message TopicMessage) (msg MsgSend, err error) {
+	message.Xmlns = MNSXmlNamespace
+	data, err := marshalXML(message)
+	if err != nil {
+		return
+	}
+
+	req := &request{
+		endpoint:    topic.Endpoint,
+		method:      http.MethodPost,
+		path:        getTopicPath(topic.TopicName) + "/messages",
+		payload:     data,
+		contentType: "text/xml",
+	}
+	err = topic.requestWithXmlResponse(req, &msg)
+	return
+}
+
+//订阅主题, Endpoint 为队列(见 QueueEndpoint)或 HTTP 地址
+func (topic *Topic) Subscribe(subscriptionName string, attributes *SubscriptionAttributes) error {
+	if attributes == nil {
+		attributes = &SubscriptionAttributes{}
+	}
+	attributes.Xmlns = MNSXmlNamespace
+	data, err := marshalXML(attributes)
+	if err != nil {
+		return err
+	}
+
+	req := &request{
+		endpoint:    topic.Endpoint,
+		method:      http.MethodPut,
+		path:        getSubscriptionPath(topic.TopicName, subscriptionName),
+		payload:     data,
+		contentType: "text/xml",
+	}
+	return topic.requestWithClose(req)
+}
+
+//修改订阅属性, 只能修改 NotifyStrategy
+func (topic *Topic) SetSubscriptionAttributes(subscriptionName string, notifyStrategy NotifyStrategy) error {
+	data, err := marshalXML(&SubscriptionAttributes{
+		Xmlns:          MNSXmlNamespace,
+		NotifyStrategy: notifyStrategy,
+	})
+	if err != nil {
+		return err
+	}
+
+	req := &request{
+		endpoint: topic.Endpoint,
+		method:   http.MethodPut,
+		path:     getSubscriptionPath(topic.TopicName, subscriptionName),
+		params: map[string]string{
+			"metaoverride": "true",
+		},
+		payload:     data,
+		contentType: "text/xml",
+	}
+	return topic.requestWithClose(req)
+}
+
+//获取订阅属性
+func (topic *Topic) GetSubscriptionAttributes(subscriptionName string) (*SubscriptionInfo, error) {
+	req := &request{
+		endpoint:    topic.Endpoint,
+		method:      http.MethodGet,
+		path:        getSubscriptionPath(topic.TopicName, subscriptionName),
+		contentType: "text/xml",
+	}
+
+	info := &SubscriptionInfo{}
+	if err := topic.requestWithXmlResponse(req, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+//取消订阅
+func (topic *Topic) Unsubscribe(subscriptionName string) error {
+	req := &request{
+		endpoint:    topic.Endpoint,
+		method:      http.MethodDelete,
+		path:        getSubscriptionPath(topic.TopicName, subscriptionName),
+		contentType: "text/xml",
+	}
+	return topic.requestWithClose(req)
+}
+
+//列出主题的订阅, 通过 NextMarker 翻页
+func (topic *Topic) ListSubscription(args *ListSubscriptionArgs) (*ListSubscriptionResponse, error) {
+	req := &request{
+		endpoint:    topic.Endpoint,
+		method:      http.MethodGet,
+		path:        getTopicPath(topic.TopicName) + "/subscriptions",
+		contentType: "text/xml",
+	}
+	if args != nil {
+		req.headers = listHeaders(args.Prefix, args.Marker, args.RetNumber, false)
+	}
+
+	resp := &ListSubscriptionResponse{}
+	if err := topic.requestWithXmlResponse(req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// MarshalXML encodes the attributes as JSON text in the DirectMail and DirectSMS elements
+func (attrs *MessageAttributes) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	var elements struct {
+		DirectMail string `xml:"DirectMail,omitempty"`
+		DirectSMS  string `xml:"DirectSMS,omitempty"`
+	}
+	if attrs.DirectMail != nil {
+		data, err := json.Marshal(attrs.DirectMail)
+		if err != nil {
+			return err
+		}
+		elements.DirectMail = string(data)
+	}
+	if attrs.DirectSMS != nil {
+		data, err := json.Marshal(attrs.DirectSMS)
+		if err != nil {
+			return err
+		}
+		elements.DirectSMS = string(data)
+	}
+	return e.EncodeElement(elements, start)
+}
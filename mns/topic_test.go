@@ -0,0 +1,188 @@
+package mns
+
+import (
+	"crypto"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTopic(t *testing.T) {
+	var lastBody string
+	var lastRequest *http.Request
+	client, closeServer := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		lastBody = string(data)
+		lastRequest = r
+		switch r.Method {
+		case http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`<Message><MessageId>id-1</MessageId><MessageBodyMD5>md5</MessageBodyMD5></Message>`))
+		case http.MethodPut:
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+	defer closeServer()
+
+	if err := client.CreateTopic("topic", nil); err != nil {
+		t.Fatalf("Failed to create topic: %v", err)
+	}
+	topic := client.Topic("topic")
+	err := topic.Subscribe("sub", &SubscriptionAttributes{
+		Endpoint:            QueueEndpoint("cn-hangzhou", "123", "queue"),
+		FilterTag:           "order",
+		NotifyContentFormat: NotifyContentFormatJSON,
+	})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	if lastRequest.URL.Path != "/topics/topic/subscriptions/sub" ||
+		!strings.Contains(lastBody, "<Endpoint>acs:mns:cn-hangzhou:123:queues/queue</Endpoint>") ||
+		!strings.Contains(lastBody, "<FilterTag>order</FilterTag>") {
+		t.Errorf("Unexpected subscribe request: %s %s", lastRequest.URL, lastBody)
+	}
+
+	if err := topic.Subscribe("sub", nil); err != nil {
+		t.Fatalf("Failed to subscribe with nil attributes: %v", err)
+	}
+
+	msg, err := topic.PublishMessage(TopicMessage{
+		MessageBody: "hello",
+		MessageTag:  "order",
+		MessageAttributes: &MessageAttributes{
+			DirectMail: &MailAttributes{Subject: "subject", AccountName: "a@example.com"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to publish: %v", err)
+	}
+	if msg.MessageId != "id-1" || !strings.Contains(lastBody, "<MessageTag>order</MessageTag>") ||
+		!strings.Contains(lastBody, "<DirectMail>{&#34;Subject&#34;:&#34;subject&#34;") {
+		t.Errorf("Unexpected publish request: %s", lastBody)
+	}
+
+	if err := topic.Unsubscribe("sub"); err != nil {
+		t.Fatalf("Failed to unsubscribe: %v", err)
+	}
+}
+
+func TestNotificationHandler(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certURL := "https://mnstest.oss-cn-hangzhou.aliyuncs.com/x509_public_certificate.pem"
+	signingCertificates.lock.Lock()
+	signingCertificates.certificate[certURL] = &key.PublicKey
+	signingCertificates.lock.Unlock()
+
+	var received *Notification
+	server := httptest.NewServer(NotificationHandler(func(n *Notification) error {
+		received = n
+		return nil
+	}))
+	defer server.Close()
+
+	body := `<?xml version="1.0" encoding="utf-8"?><Notification xmlns="http://mns.aliyuncs.com/doc/v1/"><TopicName>topic</TopicName><MessageId>id-1</MessageId><Message>hello</Message><PublishTime>1449556920000</PublishTime></Notification>`
+	sum := md5.Sum([]byte(body))
+	contentMD5 := base64.StdEncoding.EncodeToString(sum[:])
+	now := time.Now().UTC().Format(http.TimeFormat)
+	send := func(sign bool, sentBody string, date string) int {
+		req, _ := http.NewRequest(http.MethodPost, server.URL+"/notify", strings.NewReader(sentBody))
+		req.Header.Set("Content-MD5", contentMD5)
+		req.Header.Set("Content-Type", "text/xml;charset=utf-8")
+		req.Header.Set("Date", date)
+		req.Header.Set("x-mns-request-id", "request-id")
+		req.Header.Set("x-mns-version", MNSAPIVersion)
+		req.Header.Set("x-mns-signing-cert-url", base64.StdEncoding.EncodeToString([]byte(certURL)))
+		signString := "POST\n" + contentMD5 + "\ntext/xml;charset=utf-8\n" + date + "\n" +
+			"x-mns-request-id:request-id\nx-mns-signing-cert-url:" + req.Header.Get("x-mns-signing-cert-url") +
+			"\nx-mns-version:" + MNSAPIVersion + "\n/notify"
+		if !sign {
+			signString += "tampered"
+		}
+		hashed := sha1.Sum([]byte(signString))
+		signature, _ := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA1, hashed[:])
+		req.Header.Set("Authorization", base64.StdEncoding.EncodeToString(signature))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := send(false, body, now); code != http.StatusForbidden || received != nil {
+		t.Errorf("Expected a forged notification to be rejected, got %d", code)
+	}
+	forged := strings.Replace(body, "hello", "forged", 1)
+	if code := send(true, forged, now); code != http.StatusForbidden || received != nil {
+		t.Errorf("Expected a notification with a forged body to be rejected, got %d", code)
+	}
+	if code := send(true, body, "Wed, 25 May 2016 10:46:14 GMT"); code != http.StatusForbidden || received != nil {
+		t.Errorf("Expected a stale notification to be rejected, got %d", code)
+	}
+	if code := send(true, body, now); code != http.StatusNoContent {
+		t.Fatalf("Expected the notification to be accepted, got %d", code)
+	}
+	if received == nil || received.TopicName != "topic" || received.Message != "hello" || received.PublishTime != 1449556920000 {
+		t.Errorf("Unexpected notification: %+v", received)
+	}
+}
+
+func TestDecodeNotification(t *testing.T) {
+	n, err := DecodeNotification([]byte(`{"TopicName":"topic","MessageId":"id-1","Message":"hello"}`))
+	if err != nil || n.MessageId != "id-1" || n.Message != "hello" {
+		t.Errorf("Unexpected JSON notification: %+v %v", n, err)
+	}
+	n, err = DecodeNotification([]byte(`{"order":1}`))
+	if err != nil || n.Message != `{"order":1}` {
+		t.Errorf("Unexpected simplified notification: %+v %v", n, err)
+	}
+}
+
+func TestVerifyContentMD5(t *testing.T) {
+	body := []byte("hello")
+	sum := md5.Sum(body)
+	if err := verifyContentMD5(base64.StdEncoding.EncodeToString(sum[:]), body); err != nil {
+		t.Errorf("Failed to verify Content-MD5: %v", err)
+	}
+	if err := verifyContentMD5(base64.StdEncoding.EncodeToString([]byte(hex.EncodeToString(sum[:]))), body); err != nil {
+		t.Errorf("Failed to verify hex Content-MD5: %v", err)
+	}
+	if err := verifyContentMD5("", body); err == nil {
+		t.Errorf("Expected a missing Content-MD5 to be rejected")
+	}
+}
+
+func TestSigningCertificateURL(t *testing.T) {
+	for _, certURL := range []string{
+		"http://mnstest.oss-cn-hangzhou.aliyuncs.com/x509_public_certificate.pem",
+		"https://mnstest.oss-cn-shanghai.aliyuncs.com/x509_public_certificate.pem",
+	} {
+		if !SigningCertificateURLReg.MatchString(certURL) {
+			t.Errorf("Expected the MNS certificate URL %s to be accepted", certURL)
+		}
+	}
+	for _, certURL := range []string{
+		"https://evil.oss-cn-hangzhou.aliyuncs.com/cert.pem",
+		"https://evil.oss-cn-hangzhou.aliyuncs.com/x509_public_certificate.pem",
+		"https://mnstest.oss-cn-hangzhou.aliyuncs.com.example.com/x509_public_certificate.pem",
+		"https://example.com/x509_public_certificate.pem",
+	} {
+		if _, err := signingCertificates.get(certURL); err == nil {
+			t.Errorf("Expected the certificate URL %s to be rejected", certURL)
+		}
+	}
+}
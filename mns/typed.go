@@ -0,0 +1,98 @@
+//go:build go1.21
+// +build go1.21
+
+package mns
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/denverdino/aliyungo/util"
+)
+
+// TypedQueue sends and decodes values of type T through a queue. Queue
+// messages have no attributes, so the body carries the codec ID followed by
+// ":" and the Base64 encoded payload, and a single queue can hold messages
+// of different codecs.
+//
+//	orders := mns.NewTypedQueue[Order](client.Queue("orders"), util.GzipCodec(util.JSONCodec))
+//	_, err := orders.Send(Order{Id: "order-1"})
+//	consumer := mns.NewConsumer(orders.Queue, orders.Handler(handleOrder), nil)
+type TypedQueue[T any] struct {
+	*Queue
+
+	// Codec encodes the sent values, and decodes the bodies without codec ID
+	Codec util.Codec
+}
+
+func NewTypedQueue[T any](queue *Queue, codec util.Codec) *TypedQueue[T] {
+	return &TypedQueue[T]{
+		Queue: queue,
+		Codec: codec,
+	}
+}
+
+// Send encodes and sends v
+func (q *TypedQueue[T]) Send(v T) (MsgSend, error) {
+	return q.SendMessage(v, Message{})
+}
+
+// SendMessage encodes v into the body of message, which sets DelaySeconds and Priority
+func (q *TypedQueue[T]) SendMessage(v T, message Message) (MsgSend, error) {
+	data, err := q.Codec.Marshal(v)
+	if err != nil {
+		return MsgSend{}, err
+	}
+	message.MessageBody = q.Codec.ID() + ":" + base64.StdEncoding.EncodeToString(data)
+	return q.Queue.SendMessage(message)
+}
+
+// Decode decodes the body of msg with the codec it names, or with Codec when
+// the body has no codec ID. A body is self-describing when it is a codec ID,
+// a colon and base64 data, a codec ID which is not registered is an error.
+func (q *TypedQueue[T]) Decode(msg *MsgReceive) (T, error) {
+	var v T
+	codec, data := q.Codec, []byte(msg.MessageBody)
+	if i := strings.IndexByte(msg.MessageBody, ':'); i > 0 && isCodecID(msg.MessageBody[:i]) {
+		if payload, err := base64.StdEncoding.DecodeString(msg.MessageBody[i+1:]); err == nil {
+			c, ok := q.lookupCodec(msg.MessageBody[:i])
+			if !ok {
+				return v, fmt.Errorf("mns: unknown codec %q of message %s", msg.MessageBody[:i], msg.MessageId)
+			}
+			codec, data = c, payload
+		}
+	}
+	err := codec.Unmarshal(data, &v)
+	return v, err
+}
+
+// Handler adapts handle to a MessageHandler decoding the messages for a Consumer
+func (q *TypedQueue[T]) Handler(handle func(ctx context.Context, v T, msg *MsgReceive) error) MessageHandler {
+	return func(ctx context.Context, msg *MsgReceive) error {
+		v, err := q.Decode(msg)
+		if err != nil {
+			return err
+		}
+		return handle(ctx, v, msg)
+	}
+}
+
+func (q *TypedQueue[T]) lookupCodec(id string) (util.Codec, bool) {
+	if id == q.Codec.ID() {
+		return q.Codec, true
+	}
+	return util.GetCodec(id)
+}
+
+// isCodecID reports whether id has the syntax of a codec ID, such as "json"
+// or "gzip+protobuf"
+func isCodecID(id string) bool {
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || strings.ContainsRune("+-._", r)) {
+			return false
+		}
+	}
+	return id != ""
+}
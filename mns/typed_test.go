@@ -0,0 +1,63 @@
+//go:build go1.21
+// +build go1.21
+
+package mns
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/denverdino/aliyungo/util"
+)
+
+func TestTypedQueue(t *testing.T) {
+	type order struct {
+		Id string
+	}
+
+	var sent Message
+	client, closeServer := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		xml.Unmarshal(data, &sent)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`<Message><MessageId>id-1</MessageId></Message>`))
+	})
+	defer closeServer()
+
+	orders := NewTypedQueue[order](client.Queue("orders"), util.GzipCodec(util.JSONCodec))
+	if _, err := orders.SendMessage(order{Id: "order-1"}, Message{DelaySeconds: 10}); err != nil {
+		t.Fatalf("Failed to send: %v", err)
+	}
+	if sent.DelaySeconds != 10 {
+		t.Errorf("Expected the delay to be kept, got %+v", sent)
+	}
+
+	o, err := orders.Decode(&MsgReceive{MessageBody: sent.MessageBody})
+	if err != nil || o.Id != "order-1" {
+		t.Errorf("Unexpected decoded value: %+v %v", o, err)
+	}
+
+	// Bodies without codec ID are decoded with the codec of the queue
+	plain := NewTypedQueue[order](client.Queue("orders"), util.JSONCodec)
+	o, err = plain.Decode(&MsgReceive{MessageBody: `{"Id":"order-2"}`})
+	if err != nil || o.Id != "order-2" {
+		t.Errorf("Unexpected decoded value: %+v %v", o, err)
+	}
+	// and self-describing bodies with the codec they name
+	o, err = plain.Decode(&MsgReceive{MessageBody: sent.MessageBody})
+	if err != nil || o.Id != "order-1" {
+		t.Errorf("Unexpected decoded value: %+v %v", o, err)
+	}
+
+	_, err = plain.Decode(&MsgReceive{MessageId: "id-2", MessageBody: "avro:" + strings.SplitN(sent.MessageBody, ":", 2)[1]})
+	if err == nil || !strings.Contains(err.Error(), `unknown codec "avro"`) {
+		t.Errorf("Expected an unknown codec error, got %v", err)
+	}
+	text := NewTypedQueue[string](client.Queue("orders"), util.JSONCodec)
+	if s, err := text.Decode(&MsgReceive{MessageBody: `"see http://example.com"`}); err != nil || s != "see http://example.com" {
+		t.Errorf("Unexpected decoded value: %q %v", s, err)
+	}
+}
@@ -1,5 +1,7 @@
 package mns
 
+import "encoding/xml"
+
 type Queue struct {
 	*Client
 	QueueName string
@@ -7,21 +9,210 @@ type Queue struct {
 }
 
 type Message struct {
-	MessageBody string `xml:"MessageBody"`
+	Xmlns        string `xml:"xmlns,attr,omitempty"`
+	MessageBody  string `xml:"MessageBody"`
+	DelaySeconds int    `xml:"DelaySeconds,omitempty"` // 0-604800
+	Priority     int    `xml:"Priority,omitempty"`     // 1-16, 8 by default
 }
 
 type MsgSend struct {
 	MessageId      string `xml:"MessageId"`
 	MessageBodyMD5 string `xml:"MessageBodyMD5"`
+
+	// Set instead of the above when the message failed in a batch
+	ErrorCode    string `xml:"ErrorCode"`
+	ErrorMessage string `xml:"ErrorMessage"`
 }
 
 type MsgReceive struct {
-	MessageId       string `xml:"MessageId"`
-	MessageBodyMD5  string `xml:"MessageBodyMD5"`
-	MessageBody     string `xml:"MessageBody"`
+	MessageId        string `xml:"MessageId"`
+	MessageBodyMD5   string `xml:"MessageBodyMD5"`
+	MessageBody      string `xml:"MessageBody"`
+	ReceiptHandle    string `xml:"ReceiptHandle"`
+	EnqueueTime      int64  `xml:"EnqueueTime"`
+	NextVisibleTime  int64  `xml:"NextVisibleTime"`
+	DequeueCount     int    `xml:"DequeueCount"`
+	Priority         int    `xml:"Priority"`
+	FirstDequeueTime int64  `xml:"FirstDequeueTime"`
+}
+
+const MNSXmlNamespace = "http://mns.aliyuncs.com/doc/v1/"
+
+// QueueAttributes are the settable attributes of a queue. Nil fields keep
+// the default value on creation and the current value on update.
+type QueueAttributes struct {
+	XMLName                xml.Name `xml:"Queue"`
+	Xmlns                  string   `xml:"xmlns,attr"`
+	DelaySeconds           *int     `xml:"DelaySeconds,omitempty"`           // 0-604800
+	MaximumMessageSize     *int     `xml:"MaximumMessageSize,omitempty"`     // 1024-65536 bytes
+	MessageRetentionPeriod *int     `xml:"MessageRetentionPeriod,omitempty"` // 60-604800 seconds
+	VisibilityTimeout      *int     `xml:"VisibilityTimeout,omitempty"`      // 1-43200 seconds
+	PollingWaitSeconds     *int     `xml:"PollingWaitSeconds,omitempty"`     // 0-30
+	LoggingEnabled         *bool    `xml:"LoggingEnabled,omitempty"`
+}
+
+type QueueInfo struct {
+	QueueURL               string `xml:"QueueURL"`
+	QueueName              string `xml:"QueueName"`
+	CreateTime             int64  `xml:"CreateTime"`
+	LastModifyTime         int64  `xml:"LastModifyTime"`
+	DelaySeconds           int    `xml:"DelaySeconds"`
+	MaximumMessageSize     int    `xml:"MaximumMessageSize"`
+	MessageRetentionPeriod int    `xml:"MessageRetentionPeriod"`
+	VisibilityTimeout      int    `xml:"VisibilityTimeout"`
+	PollingWaitSeconds     int    `xml:"PollingWaitSeconds"`
+	ActiveMessages         int64  `xml:"ActiveMessages"`
+	InactiveMessages       int64  `xml:"InactiveMessages"`
+	DelayMessages          int64  `xml:"DelayMessages"`
+	LoggingEnabled         bool   `xml:"LoggingEnabled"`
+}
+
+type ListQueueArgs struct {
+	Prefix    string
+	Marker    string
+	RetNumber int  // 1-1000, 1000 by default
+	WithMeta  bool // Fill the attributes of the queues besides QueueURL
+}
+
+type ListQueueResponse struct {
+	Queues     []QueueInfo `xml:"Queue"`
+	NextMarker string      `xml:"NextMarker"`
+}
+
+type batchSendRequest struct {
+	XMLName  xml.Name  `xml:"Messages"`
+	Xmlns    string    `xml:"xmlns,attr"`
+	Messages []Message `xml:"Message"`
+}
+
+type batchSendResponse struct {
+	Messages []MsgSend `xml:"Message"`
+}
+
+type batchReceiveResponse struct {
+	Messages []MsgReceive `xml:"Message"`
+}
+
+type batchDeleteRequest struct {
+	XMLName        xml.Name `xml:"ReceiptHandles"`
+	Xmlns          string   `xml:"xmlns,attr"`
+	ReceiptHandles []string `xml:"ReceiptHandle"`
+}
+
+type batchDeleteErrors struct {
+	Errors []BatchErrorEntry `xml:"Error"`
+}
+
+type ChangeVisibility struct {
 	ReceiptHandle   string `xml:"ReceiptHandle"`
-	EnqueueTime     int64  `xml:"EnqueueTime"`
 	NextVisibleTime int64  `xml:"NextVisibleTime"`
-	DequeueCount    int    `xml:"DequeueCount"`
-	Priority        int    `xml:"Priority"`
+}
+
+type TopicAttributes struct {
+	XMLName            xml.Name `xml:"Topic"`
+	Xmlns              string   `xml:"xmlns,attr"`
+	MaximumMessageSize *int     `xml:"MaximumMessageSize,omitempty"` // 1024-65536 bytes
+	LoggingEnabled     *bool    `xml:"LoggingEnabled,omitempty"`
+}
+
+type TopicInfo struct {
+	TopicURL               string `xml:"TopicURL"`
+	TopicName              string `xml:"TopicName"`
+	CreateTime             int64  `xml:"CreateTime"`
+	LastModifyTime         int64  `xml:"LastModifyTime"`
+	MaximumMessageSize     int    `xml:"MaximumMessageSize"`
+	MessageRetentionPeriod int    `xml:"MessageRetentionPeriod"`
+	MessageCount           int64  `xml:"MessageCount"`
+	LoggingEnabled         bool   `xml:"LoggingEnabled"`
+}
+
+type ListTopicArgs struct {
+	Prefix    string
+	Marker    string
+	RetNumber int  // 1-1000, 1000 by default
+	WithMeta  bool // Fill the attributes of the topics besides TopicURL
+}
+
+type ListTopicResponse struct {
+	Topics     []TopicInfo `xml:"Topic"`
+	NextMarker string      `xml:"NextMarker"`
+}
+
+type MailAttributes struct {
+	Subject        string `json:"Subject"`
+	AccountName    string `json:"AccountName"`
+	AddressType    int    `json:"AddressType"`
+	IsHtml         bool   `json:"IsHtml"`
+	ReplyToAddress bool   `json:"ReplyToAddress"`
+}
+
+type SMSAttributes struct {
+	FreeSignName string `json:"FreeSignName"`
+	TemplateCode string `json:"TemplateCode"`
+	Type         string `json:"Type"` // "singleContent" or "multiContent"
+	Receiver     string `json:"Receiver"`
+	SmsParams    string `json:"SmsParams"`
+}
+
+// MessageAttributes are used by the mail and sms endpoints, they are sent as JSON
+type MessageAttributes struct {
+	DirectMail *MailAttributes `xml:"-"`
+	DirectSMS  *SMSAttributes  `xml:"-"`
+}
+
+type TopicMessage struct {
+	XMLName           xml.Name           `xml:"Message"`
+	Xmlns             string             `xml:"xmlns,attr"`
+	MessageBody       string             `xml:"MessageBody"`
+	MessageTag        string             `xml:"MessageTag,omitempty"` // Matched against the FilterTag of subscriptions
+	MessageAttributes *MessageAttributes `xml:"MessageAttributes,omitempty"`
+}
+
+type NotifyStrategy string
+
+const (
+	NotifyStrategyBackoffRetry          = NotifyStrategy("BACKOFF_RETRY")
+	NotifyStrategyExponentialDecayRetry = NotifyStrategy("EXPONENTIAL_DECAY_RETRY")
+)
+
+type NotifyContentFormat string
+
+const (
+	NotifyContentFormatXML        = NotifyContentFormat("XML")
+	NotifyContentFormatJSON       = NotifyContentFormat("JSON")
+	NotifyContentFormatSimplified = NotifyContentFormat("SIMPLIFIED")
+)
+
+type SubscriptionAttributes struct {
+	XMLName             xml.Name            `xml:"Subscription"`
+	Xmlns               string              `xml:"xmlns,attr"`
+	Endpoint            string              `xml:"Endpoint,omitempty"` // See QueueEndpoint, or an http(s) URL
+	FilterTag           string              `xml:"FilterTag,omitempty"`
+	NotifyStrategy      NotifyStrategy      `xml:"NotifyStrategy,omitempty"`
+	NotifyContentFormat NotifyContentFormat `xml:"NotifyContentFormat,omitempty"`
+}
+
+type SubscriptionInfo struct {
+	SubscriptionURL     string              `xml:"SubscriptionURL"`
+	SubscriptionName    string              `xml:"SubscriptionName"`
+	Subscriber          string              `xml:"Subscriber"`
+	TopicOwner          string              `xml:"TopicOwner"`
+	TopicName           string              `xml:"TopicName"`
+	Endpoint            string              `xml:"Endpoint"`
+	FilterTag           string              `xml:"FilterTag"`
+	NotifyStrategy      NotifyStrategy      `xml:"NotifyStrategy"`
+	NotifyContentFormat NotifyContentFormat `xml:"NotifyContentFormat"`
+	CreateTime          int64               `xml:"CreateTime"`
+	LastModifyTime      int64               `xml:"LastModifyTime"`
+}
+
+type ListSubscriptionArgs struct {
+	Prefix    string
+	Marker    string
+	RetNumber int
+}
+
+type ListSubscriptionResponse struct {
+	Subscriptions []SubscriptionInfo `xml:"Subscription"`
+	NextMarker    string             `xml:"NextMarker"`
 }
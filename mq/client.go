@@ -1,10 +1,15 @@
 package mq
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type Client struct {
@@ -40,7 +45,7 @@ func NewClient(ak string, sk string, endpoint string, topic string,
 
 func getSendUrl(endpoint string, topic string, time int64, tag string, key string) string {
 	return endpoint + "/message/?topic=" + topic + "&time=" +
-		strconv.FormatInt(time, 10) + "&tag=" + tag + "&key=" + key
+		strconv.FormatInt(time, 10) + "&tag=" + url.QueryEscape(tag) + "&key=" + url.QueryEscape(key)
 }
 
 func getReceiveUrl(endpoint, topic string, time int64, tag string, num int) string {
@@ -48,6 +53,11 @@ func getReceiveUrl(endpoint, topic string, time int64, tag string, num int) stri
 		strconv.FormatInt(time, 10) + "&tag=" + tag + "&num=" + strconv.Itoa(num)
 }
 
+func getAckUrl(endpoint, topic string, time int64, msgHandle string) string {
+	return endpoint + "/message/?msgHandle=" + url.QueryEscape(msgHandle) + "&topic=" + topic +
+		"&time=" + strconv.FormatInt(time, 10)
+}
+
 func getSendSign(topic string, producerId string, messageBody []byte, time int64, sk string) (sign string) {
 	signStr := topic + newline + producerId + newline + Md5(messageBody) + newline + strconv.FormatInt(time, 10)
 	sign = HamSha1(signStr, []byte(sk))
@@ -60,6 +70,12 @@ func getReceiveSign(topic string, consumerId string, time int64, sk string) stri
 	return HamSha1(signStr, []byte(sk))
 }
 
+func getAckSign(topic string, consumerId string, msgHandle string, time int64, sk string) string {
+	// [topic+”\n”+ cid+”\n”+msgHandle+”\n”+time]
+	signStr := topic + newline + consumerId + newline + msgHandle + newline + strconv.FormatInt(time, 10)
+	return HamSha1(signStr, []byte(sk))
+}
+
 func getReceiveHeader(ak, sign, consumerId string) (map[string]string, error) {
 	if consumerId == "" {
 		return nil, fmt.Errorf("consumer id is not provided")
@@ -82,72 +98,182 @@ func getSendHeader(ak string, sign string, producerId string) (header map[string
 	return header, nil
 }
 
+// 发送消息, 使用客户端的 Tag 和 Key
 func (client *Client) Send(time int64, message []byte) (msgId string, err error) {
-	url := getSendUrl(client.Endpoint, client.Topic, time, client.Tag, client.Key)
+	return client.send(context.Background(), time, message, nil)
+}
+
+// 发送消息, 可设置定时投递时间, 顺序消息的 ShardingKey 及用户属性
+func (client *Client) SendMessage(message []byte, properties *MessageProperties) (msgId string, err error) {
+	return client.SendMessageWithContext(context.Background(), message, properties)
+}
+
+func (client *Client) SendMessageWithContext(ctx context.Context, message []byte, properties *MessageProperties) (msgId string, err error) {
+	return client.send(ctx, GetCurrentMillisecond(), message, properties)
+}
+
+func (client *Client) send(ctx context.Context, time int64, message []byte, properties *MessageProperties) (string, error) {
+	tag, key := client.Tag, client.Key
+	if properties != nil {
+		if properties.Tag != "" {
+			tag = properties.Tag
+		}
+		if properties.Key != "" {
+			key = properties.Key
+		}
+	}
+	query, err := getPropertiesQuery(properties)
+	if err != nil {
+		return "", err
+	}
+	url := getSendUrl(client.Endpoint, client.Topic, time, tag, key) + query
 	sign := getSendSign(client.Topic, client.ProducerId, message, time, client.SecretKey)
 	header, err := getSendHeader(client.AccessKey, sign, client.ProducerId)
 	if err != nil {
 		return "", err
 	}
-	response, status, err := httpPost(url, header, message)
+	response, status, err := httpRequest(ctx, http.MethodPost, url, header, message, sendTimeout)
 	if err != nil {
 		return "", err
 	}
-
-	fmt.Printf("receive message: %s %d", response, status)
-	statusMessage := getStatusCodeMessage(status)
-	if statusMessage != "" {
-		return "", errors.New(statusMessage)
+	if !isSuccess(status) {
+		return "", newError(status, response)
 	}
 
-	var rs interface{}
-	err = json.Unmarshal(response, &rs)
-	if err != nil {
+	var result struct {
+		MsgId      string `json:"msgId"`
+		SendStatus string `json:"sendStatus"`
+	}
+	if err = json.Unmarshal(response, &result); err != nil {
 		return "", err
 	}
-
-	result := rs.(map[string]interface{})
-
-	sendStatus := result["sendStatus"].(string)
-	if sendStatus != "SEND_OK" {
-		return "", errors.New(sendStatus)
+	if result.SendStatus != "SEND_OK" {
+		return "", &Error{StatusCode: status, Code: result.SendStatus, Message: string(response)}
 	}
+	return result.MsgId, nil
+}
 
-	return result["msgId"].(string), nil
+// getPropertiesQuery encodes the properties, which are sent as "k1:v1|k2:v2"
+// so that the keys and values cannot contain ':' nor '|'
+func getPropertiesQuery(properties *MessageProperties) (string, error) {
+	if properties == nil {
+		return "", nil
+	}
+	query := ""
+	startDeliverTime := properties.StartDeliverTime
+	if startDeliverTime.IsZero() && properties.DelaySeconds > 0 {
+		startDeliverTime = time.Now().Add(time.Duration(properties.DelaySeconds) * time.Second)
+	}
+	if !startDeliverTime.IsZero() {
+		query += "&startdelivertime=" + strconv.FormatInt(startDeliverTime.UnixNano()/int64(time.Millisecond), 10)
+	}
+	if properties.ShardingKey != "" {
+		query += "&shardingkey=" + url.QueryEscape(properties.ShardingKey)
+	}
+	if len(properties.Properties) > 0 {
+		keys := make([]string, 0, len(properties.Properties))
+		for k := range properties.Properties {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		pairs := make([]string, len(keys))
+		for i, k := range keys {
+			v := properties.Properties[k]
+			if k == "" || strings.ContainsAny(k, ":|") || strings.ContainsAny(v, ":|") {
+				return "", fmt.Errorf("invalid property %q:%q, keys and values cannot contain ':' nor '|'", k, v)
+			}
+			pairs[i] = k + ":" + v
+		}
+		query += "&properties=" + url.QueryEscape(strings.Join(pairs, "|"))
+	}
+	return query, nil
 }
 
+// 消费最新的一条消息
 func (client *Client) ReceiveMessage(messageChan chan string, errChan chan error) {
-	// only listen for the latest message
-	time := GetCurrentUnixMicro()
-	url := getReceiveUrl(client.Endpoint, client.Topic, time, client.Tag, 1)
-	sign := getReceiveSign(client.Topic, client.ConsumerId, time, client.SecretKey)
-	header, err := getReceiveHeader(client.AccessKey, sign, client.ConsumerId)
+	messages, err := client.ReceiveMessages(context.Background(), &ReceiveArgs{NumOfMessages: 1})
 	if err != nil {
 		errChan <- err
 		return
 	}
-	response, status, err := HttpGet(url, header)
-	if err != nil {
-		errChan <- err
+
+	if len(messages) > 0 {
+		messageChan <- messages[0].Body
+	} else {
+		errChan <- fmt.Errorf("no message available")
 		return
 	}
+}
 
-	fmt.Printf("receive message: %s %d", response, status)
-	statusMessage := getStatusCodeMessage(status)
-	if statusMessage != "" {
-		errChan <- errors.New(statusMessage)
-		return
+// 批量消费消息, 设置 WaitSeconds 时长轮询等待, ctx 取消时中断. 没有消息时返回空列表.
+// 处理完成后需通过 AckMessage 确认, 否则消息会被重新投递
+func (client *Client) ReceiveMessages(ctx context.Context, args *ReceiveArgs) ([]Message, error) {
+	num, waitSeconds, tag := 1, 0, client.Tag
+	if args != nil {
+		if args.NumOfMessages > 0 {
+			num = args.NumOfMessages
+		}
+		waitSeconds = args.WaitSeconds
+		if args.Tag != "" {
+			tag = args.Tag
+		}
+	}
+	if num > MaxNumOfMessages {
+		return nil, fmt.Errorf("number of messages %d is out of [1, %d]", num, MaxNumOfMessages)
 	}
 
-	messages := make([]MessageResponse, 0)
-	json.Unmarshal(response, &messages)
+	tag = url.QueryEscape(tag)
+	now := GetCurrentMillisecond()
+	url := getReceiveUrl(client.Endpoint, client.Topic, now, tag, num)
+	if waitSeconds > 0 {
+		url += "&waitseconds=" + strconv.Itoa(waitSeconds)
+	}
+	sign := getReceiveSign(client.Topic, client.ConsumerId, now, client.SecretKey)
+	header, err := getReceiveHeader(client.AccessKey, sign, client.ConsumerId)
+	if err != nil {
+		return nil, err
+	}
+	response, status, err := httpRequest(ctx, http.MethodGet, url, header, nil, receiveTimeout+time.Duration(waitSeconds)*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if !isSuccess(status) {
+		return nil, newError(status, response)
+	}
 
-	if len(messages) > 0 {
-		fmt.Printf("size of messages is %d", len(messages))
-		message := messages[0]
-		messageChan <- message.Body
-	} else {
-		errChan <- fmt.Errorf("no message available")
-		return
+	messages := make([]Message, 0)
+	if len(response) > 0 {
+		if err = json.Unmarshal(response, &messages); err != nil {
+			return nil, err
+		}
+	}
+	return messages, nil
+}
+
+// 确认消息已消费, msgHandle 为消费到的消息的 MsgHandle
+func (client *Client) AckMessage(msgHandle string) error {
+	return client.AckMessageWithContext(context.Background(), msgHandle)
+}
+
+func (client *Client) AckMessageWithContext(ctx context.Context, msgHandle string) error {
+	now := GetCurrentMillisecond()
+	url := getAckUrl(client.Endpoint, client.Topic, now, msgHandle)
+	sign := getAckSign(client.Topic, client.ConsumerId, msgHandle, now, client.SecretKey)
+	header, err := getReceiveHeader(client.AccessKey, sign, client.ConsumerId)
+	if err != nil {
+		return err
+	}
+	response, status, err := httpRequest(ctx, http.MethodDelete, url, header, nil, sendTimeout)
+	if err != nil {
+		return err
 	}
+	if !isSuccess(status) {
+		return newError(status, response)
+	}
+	return nil
 }
+
+const (
+	sendTimeout    = 5 * time.Second
+	receiveTimeout = 10 * time.Second
+)
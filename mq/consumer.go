@@ -0,0 +1,138 @@
+package mq
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MessageHandler processes a received message. Returning nil acks the
+// message, returning an error leaves it to be redelivered by MQ.
+type MessageHandler func(ctx context.Context, msg *Message) error
+
+type ConsumerConfig struct {
+	Workers      int           // Number of messages handled concurrently, 4 by default
+	WaitSeconds  int           // Long polling wait of each receive (1-30), 30 by default
+	Tag          string        // Tag filter, the Tag of the client by default
+	ErrorBackoff time.Duration // Pause after a failed receive, 1s by default
+
+	// OnError is called with the errors of receive and ack calls and of the handlers
+	OnError func(err error)
+}
+
+// Consumer long polls the topic of a client and dispatches the messages to a handler
+//
+//	consumer := mq.NewConsumer(client, handle, &mq.ConsumerConfig{Workers: 8, Tag: "TagA||TagB"})
+//	err := consumer.Run(ctx) // returns once ctx is cancelled and the running handlers are done
+type Consumer struct {
+	client  *Client
+	handler MessageHandler
+	config  ConsumerConfig
+}
+
+func NewConsumer(client *Client, handler MessageHandler, config *ConsumerConfig) *Consumer {
+	c := &Consumer{
+		client:  client,
+		handler: handler,
+	}
+	if config != nil {
+		c.config = *config
+	}
+	if c.config.Workers <= 0 {
+		c.config.Workers = 4
+	}
+	if c.config.WaitSeconds <= 0 || c.config.WaitSeconds > 30 {
+		c.config.WaitSeconds = 30
+	}
+	if c.config.ErrorBackoff <= 0 {
+		c.config.ErrorBackoff = time.Second
+	}
+	return c
+}
+
+// Run receives and handles messages until ctx is cancelled. It stops polling
+// at once and waits for the running handlers, which keep a context that is
+// not cancelled by ctx, before returning ctx.Err().
+func (c *Consumer) Run(ctx context.Context) error {
+	slots := make(chan struct{}, c.config.Workers)
+	var workers sync.WaitGroup
+	defer workers.Wait()
+
+	for {
+		// Only receive as many messages as there are idle workers
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case slots <- struct{}{}:
+		}
+		n := 1
+	acquire:
+		for n < MaxNumOfMessages {
+			select {
+			case slots <- struct{}{}:
+				n++
+			default:
+				break acquire
+			}
+		}
+
+		messages, err := c.client.ReceiveMessages(ctx, &ReceiveArgs{
+			NumOfMessages: n,
+			WaitSeconds:   c.config.WaitSeconds,
+			Tag:           c.config.Tag,
+		})
+		for i := len(messages); i < n; i++ {
+			<-slots
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			c.onError(err)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(c.config.ErrorBackoff):
+			}
+			continue
+		}
+
+		for i := range messages {
+			msg := messages[i]
+			workers.Add(1)
+			go func() {
+				defer func() {
+					<-slots
+					workers.Done()
+				}()
+				c.handle(&msg)
+			}()
+		}
+	}
+}
+
+func (c *Consumer) handle(msg *Message) {
+	if err := c.callHandler(msg); err != nil {
+		c.onError(fmt.Errorf("mq: failed to handle message %s: %v", msg.MsgId, err))
+		return
+	}
+	if err := c.client.AckMessage(msg.MsgHandle); err != nil {
+		c.onError(fmt.Errorf("mq: failed to ack message %s: %v", msg.MsgId, err))
+	}
+}
+
+func (c *Consumer) callHandler(msg *Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return c.handler(context.Background(), msg)
+}
+
+func (c *Consumer) onError(err error) {
+	if c.config.OnError != nil {
+		c.config.OnError(err)
+	}
+}
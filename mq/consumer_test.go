@@ -0,0 +1,150 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSendReceiveAck(t *testing.T) {
+	var lastRequest *http.Request
+	var lastBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastRequest = r
+		lastBody, _ = ioutil.ReadAll(r.Body)
+		switch r.Method {
+		case http.MethodPost:
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"msgId":"id-1","sendStatus":"SEND_OK"}`))
+		case http.MethodGet:
+			w.Write([]byte(`[{"body":"hello","msgHandle":"handle-1","msgId":"id-1","tag":"TagA"}]`))
+		case http.MethodDelete:
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+	client := NewClient("ak", "sk", server.URL, "topic", "pid", "cid", "key", "TagA")
+
+	deliverTime := time.Unix(1600000000, 0)
+	msgId, err := client.SendMessage([]byte("hello"), &MessageProperties{
+		Tag:              "TagB",
+		Key:              "order 1&key=2",
+		StartDeliverTime: deliverTime,
+		ShardingKey:      "order-1",
+		Properties:       map[string]string{"b": "2", "a": "1"},
+	})
+	if err != nil || msgId != "id-1" {
+		t.Fatalf("Failed to send message: %s %v", msgId, err)
+	}
+	query := lastRequest.URL.Query()
+	if query.Get("tag") != "TagB" || query.Get("key") != "order 1&key=2" || query.Get("shardingkey") != "order-1" ||
+		query.Get("startdelivertime") != "1600000000000" || query.Get("properties") != "a:1|b:2" {
+		t.Errorf("Unexpected send query: %s", lastRequest.URL.RawQuery)
+	}
+	ts, _ := strconv.ParseInt(query.Get("time"), 10, 64)
+	if lastRequest.Header.Get("Signature") != getSendSign("topic", "pid", lastBody, ts, "sk") {
+		t.Errorf("Unexpected send signature")
+	}
+
+	for _, properties := range []map[string]string{{"a:b": "1"}, {"a": "1|b:2"}, {"": "1"}} {
+		lastRequest = nil
+		if _, err := client.SendMessage([]byte("hello"), &MessageProperties{Properties: properties}); err == nil || lastRequest != nil {
+			t.Errorf("Expected the properties %v to be rejected, got %v", properties, err)
+		}
+	}
+
+	messages, err := client.ReceiveMessages(context.Background(), &ReceiveArgs{NumOfMessages: 16, WaitSeconds: 10, Tag: "TagA||TagB"})
+	if err != nil || len(messages) != 1 || messages[0].MsgHandle != "handle-1" {
+		t.Fatalf("Unexpected messages: %+v %v", messages, err)
+	}
+	query = lastRequest.URL.Query()
+	if query.Get("num") != "16" || query.Get("waitseconds") != "10" || query.Get("tag") != "TagA||TagB" {
+		t.Errorf("Unexpected receive query: %s", lastRequest.URL.RawQuery)
+	}
+
+	err = client.AckMessage("handle-1")
+	if e, ok := err.(*Error); !ok || e.StatusCode != http.StatusForbidden || e.Code != "AuthenticationFailed" {
+		t.Errorf("Expected an authentication error, got %v", err)
+	}
+	if lastRequest.URL.Query().Get("msgHandle") != "handle-1" {
+		t.Errorf("Unexpected ack query: %s", lastRequest.URL.RawQuery)
+	}
+}
+
+func TestConsumer(t *testing.T) {
+	var mu sync.Mutex
+	pending := []string{"ok-1", "ok-2", "fail"}
+	acked := map[string]bool{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		query := r.URL.Query()
+		switch r.Method {
+		case http.MethodGet:
+			n, _ := strconv.Atoi(query.Get("num"))
+			if n > len(pending) {
+				n = len(pending)
+			}
+			messages := []Message{}
+			for _, id := range pending[:n] {
+				messages = append(messages, Message{MsgId: id, MsgHandle: id, Body: id})
+			}
+			pending = pending[n:]
+			if len(messages) == 0 {
+				time.Sleep(10 * time.Millisecond)
+			}
+			json.NewEncoder(w).Encode(messages)
+		case http.MethodDelete:
+			acked[query.Get("msgHandle")] = true
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+	client := NewClient("ak", "sk", server.URL, "topic", "pid", "cid", "", "*")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var handled sync.WaitGroup
+	handled.Add(3)
+	var errs []error
+	consumer := NewConsumer(client, func(ctx context.Context, msg *Message) error {
+		defer handled.Done()
+		if msg.Body == "fail" {
+			return errors.New("failed")
+		}
+		return nil
+	}, &ConsumerConfig{
+		Workers: 2,
+		OnError: func(err error) {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+		},
+	})
+
+	done := make(chan error)
+	go func() {
+		done <- consumer.Run(ctx)
+	}()
+	handled.Wait()
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !acked["ok-1"] || !acked["ok-2"] || acked["fail"] {
+		t.Errorf("Unexpected acked messages: %v", acked)
+	}
+	if len(errs) != 1 {
+		t.Errorf("Expected one handler error, got %v", errs)
+	}
+}
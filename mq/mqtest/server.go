@@ -0,0 +1,381 @@
+// Package mqtest provides an in-memory MQ HTTP service for the tests of code
+// using the mq package.
+//
+//	server := mqtest.NewServer("ak", "sk")
+//	defer server.Close()
+//	client := mq.NewClient("ak", "sk", server.URL, "topic", "pid", "cid", "", "*")
+//	client.SendMessage([]byte("hello"), &mq.MessageProperties{DelaySeconds: 60})
+//	server.Advance(time.Minute) // the message becomes deliverable
+package mqtest
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/denverdino/aliyungo/mq"
+)
+
+// DefaultInvisibleTime is the time a received message stays invisible to its
+// consumer group before it is redelivered if not acked
+const DefaultInvisibleTime = 5 * time.Minute
+
+// Message is a message sent to a topic of the server
+type Message struct {
+	MsgId            string
+	Topic            string
+	Tag              string
+	Key              string
+	Body             []byte
+	ProducerId       string
+	BornTime         time.Time
+	StartDeliverTime time.Time
+	ShardingKey      string
+	Properties       map[string]string
+}
+
+// delivery is the state of a message for a consumer group
+type delivery struct {
+	message        *Message
+	nextVisible    time.Time
+	handle         string
+	reconsumeTimes int
+	acked          bool
+}
+
+// Server serves the MQ HTTP APIs from memory. Requests must be signed with
+// the credentials of the server. The time of the server starts at the local
+// time and only moves forward with Advance, while long polling receives wait
+// for real time.
+type Server struct {
+	*httptest.Server
+	AccessKey string
+	SecretKey string
+
+	// InvisibleTime is the time a received message stays invisible, DefaultInvisibleTime by default
+	InvisibleTime time.Duration
+
+	mu       sync.Mutex
+	now      time.Time
+	messages map[string][]*Message           // by topic
+	groups   map[string]map[string]*delivery // by topic + "\n" + consumer id, then message id
+	sequence int
+	changed  chan struct{} // closed and replaced on every change
+}
+
+// NewServer starts a server accepting the requests signed with the given credentials
+func NewServer(accessKey, secretKey string) *Server {
+	s := &Server{
+		AccessKey:     accessKey,
+		SecretKey:     secretKey,
+		InvisibleTime: DefaultInvisibleTime,
+		now:           time.Now(),
+		messages:      map[string][]*Message{},
+		groups:        map[string]map[string]*delivery{},
+		changed:       make(chan struct{}),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Now returns the current time of the server
+func (s *Server) Now() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.now
+}
+
+// Advance moves the time of the server forward, making the timed and the
+// unacked messages deliverable once their time is reached
+func (s *Server) Advance(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = s.now.Add(d)
+	s.notify()
+}
+
+// Sent returns the messages sent to a topic in sending order
+func (s *Server) Sent(topic string) []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	messages := make([]Message, len(s.messages[topic]))
+	for i, msg := range s.messages[topic] {
+		messages[i] = *msg
+	}
+	return messages
+}
+
+// Acked returns the IDs of the messages of a topic acked by a consumer group, in sending order
+func (s *Server) Acked(topic, consumerId string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	group := s.groups[topic+"\n"+consumerId]
+	var ids []string
+	for _, msg := range s.messages[topic] {
+		if d := group[msg.MsgId]; d != nil && d.acked {
+			ids = append(ids, msg.MsgId)
+		}
+	}
+	return ids
+}
+
+func (s *Server) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "BadRequest", err.Error())
+		return
+	}
+	query := r.URL.Query()
+	topic := query.Get("topic")
+	if topic == "" || query.Get("time") == "" {
+		writeError(w, http.StatusBadRequest, "BadRequest", "topic and time are required")
+		return
+	}
+
+	// See getSendSign, getReceiveSign and getAckSign of the mq package
+	var signString string
+	switch r.Method {
+	case http.MethodPost:
+		signString = topic + "\n" + r.Header.Get("ProducerId") + "\n" + mq.Md5(body) + "\n" + query.Get("time")
+	case http.MethodGet:
+		signString = topic + "\n" + r.Header.Get("ConsumerId") + "\n" + query.Get("time")
+	case http.MethodDelete:
+		signString = topic + "\n" + r.Header.Get("ConsumerId") + "\n" + query.Get("msgHandle") + "\n" + query.Get("time")
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "BadRequest", r.Method)
+		return
+	}
+	mac := hmac.New(sha1.New, []byte(s.SecretKey))
+	mac.Write([]byte(signString))
+	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	if r.Header.Get("AccessKey") != s.AccessKey || !hmac.Equal([]byte(r.Header.Get("Signature")), []byte(expected)) {
+		writeError(w, http.StatusForbidden, "SignatureDoesNotMatch", fmt.Sprintf("the signature of %q does not match", signString))
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.Method {
+	case http.MethodPost:
+		s.send(w, r, topic, body)
+	case http.MethodGet:
+		s.receive(w, r, topic)
+	case http.MethodDelete:
+		s.ack(w, topic, r.Header.Get("ConsumerId"), query.Get("msgHandle"))
+	}
+}
+
+func (s *Server) send(w http.ResponseWriter, r *http.Request, topic string, body []byte) {
+	query := r.URL.Query()
+	if r.Header.Get("ProducerId") == "" {
+		writeError(w, http.StatusForbidden, "AuthenticationFailed", "producer id is not provided")
+		return
+	}
+
+	s.sequence++
+	msg := &Message{
+		MsgId:       fmt.Sprintf("%016X", s.sequence),
+		Topic:       topic,
+		Tag:         query.Get("tag"),
+		Key:         query.Get("key"),
+		Body:        body,
+		ProducerId:  r.Header.Get("ProducerId"),
+		BornTime:    s.now,
+		ShardingKey: query.Get("shardingkey"),
+	}
+	if value := query.Get("startdelivertime"); value != "" {
+		millis, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "BadRequest", "invalid startdelivertime "+value)
+			return
+		}
+		msg.StartDeliverTime = time.Unix(0, millis*int64(time.Millisecond))
+	}
+	if value := query.Get("properties"); value != "" {
+		msg.Properties = map[string]string{}
+		for _, pair := range strings.Split(value, "|") {
+			kv := strings.SplitN(pair, ":", 2)
+			if len(kv) != 2 {
+				writeError(w, http.StatusBadRequest, "BadRequest", "invalid properties "+value)
+				return
+			}
+			msg.Properties[kv[0]] = kv[1]
+		}
+	}
+	s.messages[topic] = append(s.messages[topic], msg)
+	s.notify()
+
+	writeJSON(w, http.StatusCreated, map[string]string{
+		"msgId":      msg.MsgId,
+		"sendStatus": "SEND_OK",
+	})
+}
+
+type receivedMessage struct {
+	Body           string            `json:"body"`
+	BornTime       string            `json:"bornTime"`
+	Key            string            `json:"key"`
+	MsgHandle      string            `json:"msgHandle"`
+	MsgId          string            `json:"msgId"`
+	ReconsumeTimes int               `json:"reconsumeTimes"`
+	Tag            string            `json:"tag"`
+	Properties     map[string]string `json:"properties,omitempty"`
+}
+
+func (s *Server) receive(w http.ResponseWriter, r *http.Request, topic string) {
+	query := r.URL.Query()
+	consumerId := r.Header.Get("ConsumerId")
+	if consumerId == "" {
+		writeError(w, http.StatusForbidden, "AuthenticationFailed", "consumer id is not provided")
+		return
+	}
+	num, _ := strconv.Atoi(query.Get("num"))
+	if num < 1 || num > mq.MaxNumOfMessages {
+		writeError(w, http.StatusBadRequest, "BadRequest", "num is out of range")
+		return
+	}
+	wait, _ := strconv.Atoi(query.Get("waitseconds"))
+
+	// Long polling waits for real time, woken up by sends and Advance
+	deadline := time.NewTimer(time.Duration(wait) * time.Second)
+	defer deadline.Stop()
+	var deliveries []*delivery
+	for {
+		deliveries = s.deliverable(topic, consumerId, query.Get("tag"), num)
+		if len(deliveries) > 0 || wait == 0 {
+			break
+		}
+		changed := s.changed
+		s.mu.Unlock()
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+		case <-deadline.C:
+			wait = 0
+		}
+		s.mu.Lock()
+		if r.Context().Err() != nil {
+			return
+		}
+	}
+
+	received := make([]receivedMessage, len(deliveries))
+	for i, d := range deliveries {
+		if d.handle != "" {
+			d.reconsumeTimes++
+		}
+		s.sequence++
+		d.handle = fmt.Sprintf("%s-%d", d.message.MsgId, s.sequence)
+		d.nextVisible = s.now.Add(s.InvisibleTime)
+		received[i] = receivedMessage{
+			Body:           string(d.message.Body),
+			BornTime:       strconv.FormatInt(d.message.BornTime.UnixNano()/int64(time.Millisecond), 10),
+			Key:            d.message.Key,
+			MsgHandle:      d.handle,
+			MsgId:          d.message.MsgId,
+			ReconsumeTimes: d.reconsumeTimes,
+			Tag:            d.message.Tag,
+			Properties:     d.message.Properties,
+		}
+	}
+	writeJSON(w, http.StatusOK, received)
+}
+
+// deliverable returns up to num messages for a consumer group in sending order.
+// A message with a sharding key waits for the previous messages of the key to be acked.
+func (s *Server) deliverable(topic, consumerId, tag string, num int) []*delivery {
+	key := topic + "\n" + consumerId
+	group := s.groups[key]
+	if group == nil {
+		group = map[string]*delivery{}
+		s.groups[key] = group
+	}
+
+	var deliveries []*delivery
+	blocked := map[string]bool{}
+	for _, msg := range s.messages[topic] {
+		if len(deliveries) == num {
+			break
+		}
+		if !matchTag(tag, msg.Tag) {
+			continue
+		}
+		d := group[msg.MsgId]
+		if d == nil {
+			d = &delivery{message: msg, nextVisible: msg.StartDeliverTime}
+			group[msg.MsgId] = d
+		}
+		if d.acked {
+			continue
+		}
+		if msg.ShardingKey != "" {
+			if blocked[msg.ShardingKey] {
+				continue
+			}
+			blocked[msg.ShardingKey] = true
+		}
+		if !d.nextVisible.After(s.now) {
+			deliveries = append(deliveries, d)
+		}
+	}
+	return deliveries
+}
+
+func matchTag(filter, tag string) bool {
+	if filter == "" || filter == "*" {
+		return true
+	}
+	for _, t := range strings.Split(filter, "||") {
+		if strings.TrimSpace(t) == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) ack(w http.ResponseWriter, topic, consumerId, handle string) {
+	for _, d := range s.groups[topic+"\n"+consumerId] {
+		if d.handle == handle && handle != "" {
+			if d.acked || !d.nextVisible.After(s.now) {
+				break
+			}
+			d.acked = true
+			s.notify()
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "MessageNotExist", "the message handle is invalid or expired")
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json;charset=utf-8")
+	w.WriteHeader(status)
+	w.Write(data)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, map[string]string{
+		"code": code,
+		"info": message,
+	})
+}
@@ -0,0 +1,76 @@
+package mqtest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/denverdino/aliyungo/mq"
+)
+
+func TestServer(t *testing.T) {
+	server := NewServer("ak", "sk")
+	defer server.Close()
+	client := mq.NewClient("ak", "sk", server.URL, "topic", "pid", "cid", "", "*")
+	ctx := context.Background()
+
+	for _, props := range []*mq.MessageProperties{
+		{Tag: "TagA", ShardingKey: "order-1"},
+		{Tag: "TagA", ShardingKey: "order-1"},
+		{Tag: "TagB", DelaySeconds: 60},
+		{Tag: "TagC", Properties: map[string]string{"k": "v"}},
+	} {
+		if _, err := client.SendMessage([]byte(props.Tag), props); err != nil {
+			t.Fatalf("Failed to send: %v", err)
+		}
+	}
+
+	messages, err := client.ReceiveMessages(ctx, &mq.ReceiveArgs{NumOfMessages: 16, Tag: "TagA||TagB"})
+	if err != nil || len(messages) != 1 || messages[0].Tag != "TagA" {
+		t.Fatalf("Expected only the first ordered message, got %+v %v", messages, err)
+	}
+	if err := client.AckMessage(messages[0].MsgHandle); err != nil {
+		t.Fatalf("Failed to ack: %v", err)
+	}
+
+	// DelaySeconds is relative to the local time, the server starts at the local time
+	server.Advance(61 * time.Second)
+	messages, err = client.ReceiveMessages(ctx, &mq.ReceiveArgs{NumOfMessages: 16, Tag: "TagA||TagB"})
+	if err != nil || len(messages) != 2 || messages[0].Tag != "TagA" || messages[1].Tag != "TagB" {
+		t.Fatalf("Unexpected messages: %+v %v", messages, err)
+	}
+
+	// Unacked messages are redelivered after the invisible time
+	server.Advance(DefaultInvisibleTime)
+	messages, err = client.ReceiveMessages(ctx, &mq.ReceiveArgs{NumOfMessages: 16, Tag: "TagA||TagB"})
+	if err != nil || len(messages) != 2 || messages[0].ReconsumeTimes != 1 {
+		t.Fatalf("Unexpected messages: %+v %v", messages, err)
+	}
+	for _, msg := range messages {
+		if err := client.AckMessage(msg.MsgHandle); err != nil {
+			t.Errorf("Failed to ack: %v", err)
+		}
+	}
+	if acked := server.Acked("topic", "cid"); len(acked) != 3 {
+		t.Errorf("Unexpected acked messages: %v", acked)
+	}
+
+	// Other consumer groups receive all the messages
+	other := mq.NewClient("ak", "sk", server.URL, "topic", "pid", "other", "", "")
+	messages, err = other.ReceiveMessages(ctx, &mq.ReceiveArgs{NumOfMessages: 16, Tag: "TagC"})
+	if err != nil || len(messages) != 1 || messages[0].Properties["k"] != "v" {
+		t.Fatalf("Unexpected messages: %+v %v", messages, err)
+	}
+
+	sent := server.Sent("topic")
+	if len(sent) != 4 || sent[2].StartDeliverTime.IsZero() || string(sent[3].Body) != "TagC" {
+		t.Errorf("Unexpected sent messages: %+v", sent)
+	}
+
+	wrong := mq.NewClient("ak", "wrong", server.URL, "topic", "pid", "cid", "", "")
+	if _, err := wrong.Send(mq.GetCurrentMillisecond(), []byte("x")); err == nil {
+		t.Errorf("Expected a signature error")
+	} else if e, ok := err.(*mq.Error); !ok || e.Code != "SignatureDoesNotMatch" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+//go:build go1.21
+// +build go1.21
+
+package mq
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/denverdino/aliyungo/util"
+)
+
+// CodecProperty is the user property carrying the codec ID of the messages sent by a TypedClient
+const CodecProperty = "codec"
+
+// TypedClient sends and decodes values of type T. The codec ID is set in the
+// CodecProperty user property and the payload is Base64 encoded, so that a
+// single topic can carry messages of different codecs.
+//
+//	orders := mq.NewTypedClient[*pb.Order](client, util.ProtobufCodec)
+//	msgId, err := orders.Send(&pb.Order{Id: "order-1"}, nil)
+//	consumer := mq.NewConsumer(client, orders.Handler(handleOrder), nil)
+type TypedClient[T any] struct {
+	*Client
+
+	// Codec encodes the sent values, and decodes the messages without codec ID
+	Codec util.Codec
+}
+
+func NewTypedClient[T any](client *Client, codec util.Codec) *TypedClient[T] {
+	return &TypedClient[T]{
+		Client: client,
+		Codec:  codec,
+	}
+}
+
+// Send encodes and sends v with the optional properties
+func (c *TypedClient[T]) Send(v T, properties *MessageProperties) (msgId string, err error) {
+	data, err := c.Codec.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	props := MessageProperties{}
+	if properties != nil {
+		props = *properties
+	}
+	userProperties := make(map[string]string, len(props.Properties)+1)
+	for k, v := range props.Properties {
+		userProperties[k] = v
+	}
+	userProperties[CodecProperty] = c.Codec.ID()
+	props.Properties = userProperties
+
+	body := []byte(base64.StdEncoding.EncodeToString(data))
+	return c.SendMessage(body, &props)
+}
+
+// Decode decodes the body of msg with the codec named in its properties, or
+// with Codec when the message has no codec ID. A codec ID which is not
+// registered is an error.
+func (c *TypedClient[T]) Decode(msg *Message) (T, error) {
+	var v T
+	codec, data := c.Codec, []byte(msg.Body)
+	if id, ok := msg.Properties[CodecProperty]; ok {
+		registered, ok := c.lookupCodec(id)
+		if !ok {
+			return v, fmt.Errorf("mq: unknown codec %q of message %s", id, msg.MsgId)
+		}
+		payload, err := base64.StdEncoding.DecodeString(msg.Body)
+		if err != nil {
+			return v, fmt.Errorf("mq: failed to decode body of message %s: %v", msg.MsgId, err)
+		}
+		codec, data = registered, payload
+	}
+	err := codec.Unmarshal(data, &v)
+	return v, err
+}
+
+// Handler adapts handle to a MessageHandler decoding the messages for a Consumer
+func (c *TypedClient[T]) Handler(handle func(ctx context.Context, v T, msg *Message) error) MessageHandler {
+	return func(ctx context.Context, msg *Message) error {
+		v, err := c.Decode(msg)
+		if err != nil {
+			return err
+		}
+		return handle(ctx, v, msg)
+	}
+}
+
+func (c *TypedClient[T]) lookupCodec(id string) (util.Codec, bool) {
+	if id == c.Codec.ID() {
+		return c.Codec, true
+	}
+	return util.GetCodec(id)
+}
@@ -0,0 +1,49 @@
+//go:build go1.21
+// +build go1.21
+
+package mq
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/denverdino/aliyungo/util"
+	"github.com/golang/protobuf/ptypes/wrappers"
+)
+
+func TestTypedClient(t *testing.T) {
+	var lastRequest *http.Request
+	var lastBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastRequest = r
+		lastBody, _ = ioutil.ReadAll(r.Body)
+		w.Write([]byte(`{"msgId":"id-1","sendStatus":"SEND_OK"}`))
+	}))
+	defer server.Close()
+	client := NewClient("ak", "sk", server.URL, "topic", "pid", "cid", "", "")
+
+	values := NewTypedClient[*wrappers.StringValue](client, util.ProtobufCodec)
+	properties := &MessageProperties{Properties: map[string]string{"source": "test"}}
+	if _, err := values.Send(&wrappers.StringValue{Value: "hello"}, properties); err != nil {
+		t.Fatalf("Failed to send: %v", err)
+	}
+	if p := lastRequest.URL.Query().Get("properties"); p != "codec:protobuf|source:test" {
+		t.Errorf("Unexpected properties: %s", p)
+	}
+	if len(properties.Properties) != 1 {
+		t.Errorf("Expected the properties of the caller to be left unchanged, got %v", properties.Properties)
+	}
+
+	v, err := values.Decode(&Message{Body: string(lastBody), Properties: map[string]string{CodecProperty: "protobuf"}})
+	if err != nil || v.GetValue() != "hello" {
+		t.Errorf("Unexpected decoded value: %v %v", v, err)
+	}
+
+	_, err = values.Decode(&Message{MsgId: "id-2", Body: string(lastBody), Properties: map[string]string{CodecProperty: "avro"}})
+	if err == nil || !strings.Contains(err.Error(), `unknown codec "avro"`) {
+		t.Errorf("Expected an unknown codec error, got %v", err)
+	}
+}
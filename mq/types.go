@@ -1,5 +1,12 @@
 package mq
 
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
 var newline string = "\n"
 
 type SendMessage struct {
@@ -26,24 +33,64 @@ type Message struct {
 	MsgId          string `json:"msgId"`
 	ReconsumeTimes int    `json:"reconsumeTimes"`
 	Tag            string `json:"tag"`
+
+	Properties map[string]string `json:"properties"`
+}
+
+// MessageProperties are the optional properties of a sent message
+type MessageProperties struct {
+	Tag string // Overrides the Tag of the client
+	Key string // Overrides the Key of the client
+
+	// StartDeliverTime delivers a timed message at the given time,
+	// DelaySeconds delivers it after the given delay when StartDeliverTime is zero
+	StartDeliverTime time.Time
+	DelaySeconds     int
+
+	// ShardingKey routes the messages of an ordered topic, the messages with
+	// the same key are consumed in the order they were sent
+	ShardingKey string
+
+	// Properties are user properties delivered with the message
+	Properties map[string]string
 }
 
-// 生产者状态码
-func getStatusCodeMessage(statusCode int) string {
-	switch statusCode {
-	case 200:
-		return ""
-	case 201:
-		return ""
-	case 204:
-		return ""
-	case 400:
-		return "Bad Request"
-	case 403:
-		return "Authentication Failed"
-	case 408:
-		return "Request Timeout"
-	default:
-		return "Unknown Error"
+type ReceiveArgs struct {
+	NumOfMessages int    // 1-32, 1 by default
+	WaitSeconds   int    // Long polling wait (0-30), returns at once when 0
+	Tag           string // Tag filter such as "TagA||TagB", the Tag of the client by default, "*" for all
+}
+
+const MaxNumOfMessages = 32
+
+// Error is returned when MQ answers with a non-2xx status code or refuses to send a message
+type Error struct {
+	StatusCode int
+	Code       string `json:"code"`
+	Message    string `json:"info"`
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("aliyun MQ API Error: Status Code: %d Code: %s Message: %s", err.StatusCode, err.Code, err.Message)
+}
+
+// 根据状态码及响应内容生成错误
+func newError(statusCode int, body []byte) *Error {
+	err := &Error{StatusCode: statusCode}
+	if json.Unmarshal(body, err) != nil || (err.Code == "" && err.Message == "") {
+		err.Message = string(body)
+	}
+	if err.Code == "" {
+		switch statusCode {
+		case 400:
+			err.Code = "BadRequest"
+		case 403:
+			err.Code = "AuthenticationFailed"
+		case 408:
+			err.Code = "RequestTimeout"
+		default:
+			err.Code = http.StatusText(statusCode)
+		}
 	}
+	return err
 }
@@ -2,6 +2,7 @@ package mq
 
 import (
 	"bytes"
+	"context"
 	"crypto/hmac"
 	"crypto/md5"
 	"crypto/sha1"
@@ -10,9 +11,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
-	"net"
 	"net/http"
-
 	"time"
 )
 
@@ -51,118 +50,51 @@ func dump(data interface{}) {
 	fmt.Println(data)
 }
 
-// POST请求
-func httpPost(urlStr string, header map[string]string, body []byte) ([]byte, int, error) {
-
-	client := &http.Client{
-		Transport: &http.Transport{
-			Dial: func(netw, addr string) (net.Conn, error) {
-				conn, err := net.DialTimeout(netw, addr, time.Second*2)
-				if err != nil {
-					return nil, err
-				}
-				conn.SetDeadline(time.Now().Add(time.Second * 2))
-				return conn, nil
-			},
-			ResponseHeaderTimeout: time.Second * 2,
-		},
-	}
-
-	req, err := http.NewRequest("POST", urlStr, bytes.NewBuffer([]byte(body)))
+// httpRequest 发送请求并返回响应内容及状态码, 非2xx状态码时同样返回响应内容
+func httpRequest(ctx context.Context, method, urlStr string, header map[string]string, body []byte, timeout time.Duration) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, urlStr, bytes.NewReader(body))
 	if err != nil {
-		return []byte(""), 0, err
+		return nil, 0, err
 	}
 
 	req.Header.Set("Content-Type", "text/html;charset=utf-8")
-	// req.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
 	for k, v := range header {
 		req.Header.Set(k, v)
 	}
 
+	client := &http.Client{Timeout: timeout}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, 0, err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode != 200 && resp.StatusCode != 201 && resp.StatusCode != 204 {
-		return nil, resp.StatusCode, nil
-	}
 
 	data, err := ioutil.ReadAll(resp.Body)
 	return data, resp.StatusCode, err
 }
 
+func isSuccess(statusCode int) bool {
+	return statusCode == 200 || statusCode == 201 || statusCode == 204
+}
+
 // GET请求
 func HttpGet(urlStr string, header map[string]string) ([]byte, int, error) {
-
-	client := &http.Client{
-		Transport: &http.Transport{
-			Dial: func(netw, addr string) (net.Conn, error) {
-				conn, err := net.DialTimeout(netw, addr, time.Second*30)
-				if err != nil {
-					return nil, err
-				}
-				conn.SetDeadline(time.Now().Add(time.Second * 30))
-				return conn, nil
-			},
-			ResponseHeaderTimeout: time.Second * 30,
-		},
-	}
-	req, _ := http.NewRequest("GET", urlStr, nil)
-
-	req.Header.Set("Content-Type", "text/html;charset=utf-8")
-	// req.Header.Set("Content-Type", "application/x-www-form-urlencoded; param=value")
-	for k, v := range header {
-		req.Header.Set(k, v)
-	}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, 0, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 && resp.StatusCode != 201 && resp.StatusCode != 204 {
-		return nil, resp.StatusCode, nil
-	}
-
-	data, err := ioutil.ReadAll(resp.Body)
-	return data, resp.StatusCode, err
+	return httpRequestLegacy(http.MethodGet, urlStr, header, nil, time.Second*30)
 }
 
-// GET请求
+// DELETE请求
 func HttpDelete(urlStr string, header map[string]string) ([]byte, int, error) {
+	return httpRequestLegacy(http.MethodDelete, urlStr, header, nil, time.Second*2)
+}
 
-	client := &http.Client{
-		Transport: &http.Transport{
-			Dial: func(netw, addr string) (net.Conn, error) {
-				conn, err := net.DialTimeout(netw, addr, time.Second*2)
-				if err != nil {
-					return nil, err
-				}
-				conn.SetDeadline(time.Now().Add(time.Second * 2))
-				return conn, nil
-			},
-			ResponseHeaderTimeout: time.Second * 2,
-		},
-	}
-	req, _ := http.NewRequest("DELETE", urlStr, nil)
-
-	req.Header.Set("Content-Type", "text/html;charset=utf-8")
-	for k, v := range header {
-		req.Header.Set(k, v)
-	}
-
-	resp, err := client.Do(req)
+// httpRequestLegacy 非2xx状态码时不返回响应内容
+func httpRequestLegacy(method, urlStr string, header map[string]string, body []byte, timeout time.Duration) ([]byte, int, error) {
+	data, status, err := httpRequest(context.Background(), method, urlStr, header, body, timeout)
 	if err != nil {
-		return nil, 0, err
+		return nil, status, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 && resp.StatusCode != 201 && resp.StatusCode != 204 {
-		return nil, resp.StatusCode, nil
+	if !isSuccess(status) {
+		return nil, status, nil
 	}
-
-	data, err := ioutil.ReadAll(resp.Body)
-	return data, resp.StatusCode, err
+	return data, status, nil
 }
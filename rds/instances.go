@@ -1,10 +1,8 @@
 package rds
 
 import (
+	"context"
 	"fmt"
-	"time"
-
-	"log"
 
 	"github.com/denverdino/aliyungo/common"
 )
@@ -468,33 +466,19 @@ func (client *Client) WaitForInstance(instanceId string, status InstanceStatus,
 	if timeout <= 0 {
 		timeout = InstanceDefaultTimeout
 	}
-	for {
-		args := DescribeDBInstanceAttributeArgs{
+	waiter := common.NewWaiter(timeout, DefaultWaitForInterval, func() (interface{}, error) {
+		return client.DescribeDBInstanceAttribute(&DescribeDBInstanceAttributeArgs{
 			DBInstanceId: instanceId,
-		}
-
-		resp, err := client.DescribeDBInstanceAttribute(&args)
-		if err != nil {
-			return err
-		}
-
-		if timeout <= 0 {
-			return common.GetClientErrorFromString("Timeout")
-		}
-
-		timeout = timeout - DefaultWaitForInterval
-		time.Sleep(DefaultWaitForInterval * time.Second)
-
-		if len(resp.Items.DBInstanceAttribute) < 1 {
-			continue
-		}
-		instance := resp.Items.DBInstanceAttribute[0]
-		if instance.DBInstanceStatus == status {
-			break
-		}
+		})
+	}, func(result interface{}) bool {
+		return hasInstanceStatus(result.(*DescribeDBInstanceAttributeResponse), status)
+	})
+	_, err := waiter.Wait(context.Background())
+	return err
+}
 
-	}
-	return nil
+func hasInstanceStatus(resp *DescribeDBInstanceAttributeResponse, status InstanceStatus) bool {
+	return resp != nil && len(resp.Items.DBInstanceAttribute) > 0 && resp.Items.DBInstanceAttribute[0].DBInstanceStatus == status
 }
 
 // WaitForInstance waits for instance to given status
@@ -502,62 +486,29 @@ func (client *Client) WaitForInstanceAsyn(instanceId string, status InstanceStat
 	if timeout <= 0 {
 		timeout = InstanceDefaultTimeout
 	}
-	for {
-		args := DescribeDBInstanceAttributeArgs{
+	waiter := common.NewWaiter(timeout, DefaultWaitForInterval, func() (interface{}, error) {
+		return client.DescribeDBInstanceAttribute(&DescribeDBInstanceAttributeArgs{
 			DBInstanceId: instanceId,
-		}
-
-		resp, err := client.DescribeDBInstanceAttribute(&args)
-		if err != nil {
-			e, _ := err.(*common.Error)
-			if e.Code != "InvalidDBInstanceId.NotFound" && e.Code != "Forbidden.InstanceNotFound" {
-				return err
-			}
-		}
-
-		if timeout <= 0 {
-			return common.GetClientErrorFromString("Timeout")
-		}
-
-		timeout = timeout - DefaultWaitForInterval
-		time.Sleep(DefaultWaitForInterval * time.Second)
-		if resp != nil {
-
-			if len(resp.Items.DBInstanceAttribute) < 1 {
-				continue
-			}
-			instance := resp.Items.DBInstanceAttribute[0]
-			if instance.DBInstanceStatus == status {
-				break
-			}
-		}
-	}
-	return nil
+		})
+	}, func(result interface{}) bool {
+		return hasInstanceStatus(result.(*DescribeDBInstanceAttributeResponse), status)
+	})
+	waiter.Retryable = common.IsErrorCodeFunc("InvalidDBInstanceId.NotFound", "Forbidden.InstanceNotFound")
+	_, err := waiter.Wait(context.Background())
+	return err
 }
 
 func (client *Client) WaitForAllDatabase(instanceId string, databaseNames []string, status InstanceStatus, timeout int) error {
 	if timeout <= 0 {
 		timeout = InstanceDefaultTimeout
 	}
-	for {
-		args := DescribeDatabasesArgs{
+	waiter := common.NewWaiter(timeout, DefaultWaitForInterval, func() (interface{}, error) {
+		return client.DescribeDatabases(&DescribeDatabasesArgs{
 			DBInstanceId: instanceId,
-		}
-
-		resp, err := client.DescribeDatabases(&args)
-		if err != nil {
-			return err
-		}
-
-		if timeout <= 0 {
-			return common.GetClientErrorFromString("Timeout")
-		}
-
-		timeout = timeout - DefaultWaitForInterval
-		time.Sleep(DefaultWaitForInterval * time.Second)
-
+		})
+	}, func(result interface{}) bool {
+		resp := result.(*DescribeDatabasesResponse)
 		ready := 0
-
 		for _, nm := range databaseNames {
 			for _, db := range resp.Databases.Database {
 				if db.DBName == nm {
@@ -568,215 +519,101 @@ func (client *Client) WaitForAllDatabase(instanceId string, databaseNames []stri
 				}
 			}
 		}
-
-		if ready == len(databaseNames) {
-			break
-		}
-
-	}
-	return nil
+		return ready == len(databaseNames)
+	})
+	_, err := waiter.Wait(context.Background())
+	return err
 }
 
 func (client *Client) WaitForAccount(instanceId string, accountName string, status AccountStatus, timeout int) error {
 	if timeout <= 0 {
 		timeout = InstanceDefaultTimeout
 	}
-	for {
-		args := DescribeAccountsArgs{
+	waiter := common.NewWaiter(timeout, DefaultWaitForInterval, func() (interface{}, error) {
+		return client.DescribeAccounts(&DescribeAccountsArgs{
 			DBInstanceId: instanceId,
 			AccountName:  accountName,
-		}
-
-		resp, err := client.DescribeAccounts(&args)
-		if err != nil {
-			return err
-		}
-
-		accs := resp.Accounts.DBInstanceAccount
-		log.Printf("**********acc: %#v", accs)
-
-		if timeout <= 0 {
-			return common.GetClientErrorFromString("Timeout")
-		}
-
-		timeout = timeout - DefaultWaitForInterval
-		time.Sleep(DefaultWaitForInterval * time.Second)
-
-		if len(accs) < 1 {
-			continue
-		}
-
-		acc := accs[0]
-
-		if acc.AccountStatus == status {
-			break
-		}
-
-	}
-	return nil
+		})
+	}, func(result interface{}) bool {
+		accs := result.(*DescribeAccountsResponse).Accounts.DBInstanceAccount
+		return len(accs) > 0 && accs[0].AccountStatus == status
+	})
+	_, err := waiter.Wait(context.Background())
+	return err
 }
 
 func (client *Client) WaitForPublicConnection(instanceId string, timeout int) error {
-	if timeout <= 0 {
-		timeout = InstanceDefaultTimeout
-	}
-	for {
-		args := DescribeDBInstanceNetInfoArgs{
-			DBInstanceId: instanceId,
-		}
-
-		resp, err := client.DescribeDBInstanceNetInfo(&args)
-		if err != nil {
-			return err
-		}
-
-		if timeout <= 0 {
-			return common.GetClientErrorFromString("Timeout")
-		}
-
-		timeout = timeout - DefaultWaitForInterval
-		time.Sleep(DefaultWaitForInterval * time.Second)
-
-		ready := false
-		for _, info := range resp.DBInstanceNetInfos.DBInstanceNetInfo {
-			if info.IPType == Public {
-				ready = true
-			}
-		}
-
-		if ready {
-			break
-		}
-
-	}
-	return nil
+	return client.WaitForDBConnection(instanceId, Public, timeout)
 }
 
 func (client *Client) WaitForDBConnection(instanceId string, netType IPType, timeout int) error {
 	if timeout <= 0 {
 		timeout = InstanceDefaultTimeout
 	}
-	for {
-		args := DescribeDBInstanceNetInfoArgs{
+	waiter := common.NewWaiter(timeout, DefaultWaitForInterval, func() (interface{}, error) {
+		return client.DescribeDBInstanceNetInfo(&DescribeDBInstanceNetInfoArgs{
 			DBInstanceId: instanceId,
-		}
-
-		resp, err := client.DescribeDBInstanceNetInfo(&args)
-		if err != nil {
-			return err
-		}
-
-		if timeout <= 0 {
-			return common.GetClientErrorFromString("Timeout")
-		}
-
-		timeout = timeout - DefaultWaitForInterval
-		time.Sleep(DefaultWaitForInterval * time.Second)
-
-		ready := false
-		for _, info := range resp.DBInstanceNetInfos.DBInstanceNetInfo {
+		})
+	}, func(result interface{}) bool {
+		for _, info := range result.(*DescribeDBInstanceNetInfoResponse).DBInstanceNetInfos.DBInstanceNetInfo {
 			if info.IPType == netType {
-				ready = true
+				return true
 			}
 		}
-
-		if ready {
-			break
-		}
-
-	}
-	return nil
+		return false
+	})
+	_, err := waiter.Wait(context.Background())
+	return err
 }
 
 func (client *Client) WaitForAccountPrivilege(instanceId, accountName, dbName string, privilege AccountPrivilege, timeout int) error {
 	if timeout <= 0 {
 		timeout = InstanceDefaultTimeout
 	}
-	for {
-		args := DescribeAccountsArgs{
+	waiter := common.NewWaiter(timeout, DefaultWaitForInterval, func() (interface{}, error) {
+		return client.DescribeAccounts(&DescribeAccountsArgs{
 			DBInstanceId: instanceId,
 			AccountName:  accountName,
-		}
-
-		resp, err := client.DescribeAccounts(&args)
-		if err != nil {
-			return err
-		}
-
-		accs := resp.Accounts.DBInstanceAccount
-
-		if timeout <= 0 {
-			return common.GetClientErrorFromString("Timeout")
-		}
-
-		timeout = timeout - DefaultWaitForInterval
-		time.Sleep(DefaultWaitForInterval * time.Second)
-
+		})
+	}, func(result interface{}) bool {
+		accs := result.(*DescribeAccountsResponse).Accounts.DBInstanceAccount
 		if len(accs) < 1 {
-			continue
+			return false
 		}
-
-		acc := accs[0]
-
-		ready := false
-		for _, dp := range acc.DatabasePrivileges.DatabasePrivilege {
+		for _, dp := range accs[0].DatabasePrivileges.DatabasePrivilege {
 			if dp.DBName == dbName && dp.AccountPrivilege == privilege {
-				ready = true
+				return true
 			}
 		}
-
-		if ready {
-			break
-		}
-
-	}
-	return nil
+		return false
+	})
+	_, err := waiter.Wait(context.Background())
+	return err
 }
 
 func (client *Client) WaitForAccountPrivilegeRevoked(instanceId, accountName, dbName string, timeout int) error {
 	if timeout <= 0 {
 		timeout = InstanceDefaultTimeout
 	}
-	for {
-		args := DescribeAccountsArgs{
+	waiter := common.NewWaiter(timeout, DefaultWaitForInterval, func() (interface{}, error) {
+		return client.DescribeAccounts(&DescribeAccountsArgs{
 			DBInstanceId: instanceId,
 			AccountName:  accountName,
-		}
-
-		resp, err := client.DescribeAccounts(&args)
-		if err != nil {
-			return err
-		}
-
-		accs := resp.Accounts.DBInstanceAccount
-
+		})
+	}, func(result interface{}) bool {
+		accs := result.(*DescribeAccountsResponse).Accounts.DBInstanceAccount
 		if len(accs) < 1 {
-			continue
+			return false
 		}
-
-		acc := accs[0]
-
-		exist := false
-		for _, dp := range acc.DatabasePrivileges.DatabasePrivilege {
+		for _, dp := range accs[0].DatabasePrivileges.DatabasePrivilege {
 			if dp.DBName == dbName {
-				exist = true
-				break
+				return false
 			}
 		}
-
-		if !exist {
-			break
-		}
-
-		if timeout <= 0 {
-			return common.GetClientErrorFromString("Timeout")
-		}
-
-		timeout = timeout - DefaultWaitForInterval
-		time.Sleep(DefaultWaitForInterval * time.Second)
-
-	}
-	return nil
+		return true
+	})
+	_, err := waiter.Wait(context.Background())
+	return err
 }
 
 type DeleteDBInstanceArgs struct {
@@ -1,6 +1,7 @@
 package slb
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -467,8 +468,6 @@ const DefaultTimeout = 60        //60 seconds
 
 // WaitForListener waits for listener to given status
 func (client *Client) WaitForListener(loadBalancerId string, port int, listenerType ListenerType) (status ListenerStatus, err error) {
-	timeout := DefaultTimeout
-
 	args := &CommonLoadBalancerListenerArgs{
 		LoadBalancerId: loadBalancerId,
 		ListenerPort:   port,
@@ -477,20 +476,19 @@ func (client *Client) WaitForListener(loadBalancerId string, port int, listenerT
 	method := fmt.Sprintf("DescribeLoadBalancer%sListenerAttribute", listenerType)
 	response := &DescribeLoadBalancerListenerAttributeResponse{}
 
-	for {
-		timeout = timeout - DefaultWaitForInterval
-		if timeout <= 0 {
-			return response.Status, common.GetClientErrorFromString("Timeout")
-		}
-		time.Sleep(DefaultWaitForInterval * time.Second)
-		//Sleep first to ensure the previous request is sent
-		err = client.Invoke(method, args, response)
-		if err != nil {
-			return "", err
-		}
-		if response.Status == Running || response.Status == Stopped {
-			break
+	waiter := common.NewWaiter(DefaultTimeout, DefaultWaitForInterval, func() (interface{}, error) {
+		err := client.Invoke(method, args, response)
+		return response, err
+	}, func(result interface{}) bool {
+		return response.Status == Running || response.Status == Stopped
+	})
+	//Sleep first to ensure the previous request is sent
+	waiter.InitialDelay = DefaultWaitForInterval * time.Second
+	if _, err = waiter.Wait(context.Background()); err != nil {
+		if common.IsWaitTimeout(err) {
+			return response.Status, err
 		}
+		return "", err
 	}
 	return response.Status, nil
 }
@@ -509,26 +507,15 @@ func (client *Client) WaitForListenerAsyn(loadBalancerId string, port int, liste
 	method := fmt.Sprintf("DescribeLoadBalancer%sListenerAttribute", listenerType)
 	response := &DescribeLoadBalancerListenerAttributeResponse{}
 
-	for {
+	waiter := common.NewWaiter(timeout, DefaultWaitForInterval, func() (interface{}, error) {
 		err := client.Invoke(method, args, response)
-		e, _ := err.(*common.Error)
-		if e != nil {
-			if e.StatusCode == 404 || e.Code == "InvalidLoadBalancerId.NotFound" {
-				continue
-			}
-			return err
-		} else if response != nil && response.Status == status {
-			//TODO
-			break
-		}
-		timeout = timeout - DefaultWaitForInterval
-		if timeout <= 0 {
-			return common.GetClientErrorFromString("Timeout")
-		}
-		time.Sleep(DefaultWaitForInterval * time.Second)
-
-	}
-	return nil
+		return response, err
+	}, func(result interface{}) bool {
+		return response.Status == status
+	})
+	waiter.Retryable = isNotFound
+	_, err := waiter.Wait(context.Background())
+	return err
 }
 
 type DescribeListenerAccessControlAttributeResponse struct {
@@ -1,8 +1,8 @@
 package slb
 
 import (
+	"context"
 	"fmt"
-	"time"
 
 	"github.com/denverdino/aliyungo/common"
 	"github.com/denverdino/aliyungo/util"
@@ -339,26 +339,24 @@ func (client *Client) WaitForLoadBalancerAsyn(loadBalancerId string, status Stat
 		timeout = DefaultTimeout
 	}
 
-	for {
-		lb, err := client.DescribeLoadBalancerAttribute(loadBalancerId)
-
-		e, _ := err.(*common.Error)
-		if e != nil {
-			if e.StatusCode == 404 || e.Code == "InvalidLoadBalancerId.NotFound" {
-				continue
-			}
-			return err
-		} else if lb != nil && Status(lb.LoadBalancerStatus) == status {
-			//TODO
-			break
-		}
-		timeout = timeout - DefaultWaitForInterval
-		if timeout <= 0 {
-			return common.GetClientErrorFromString(fmt.Sprintf("Timeout waitting for load balacner %#v", status))
-		}
-		time.Sleep(DefaultWaitForInterval * time.Second)
+	waiter := common.NewWaiter(timeout, DefaultWaitForInterval, func() (interface{}, error) {
+		return client.DescribeLoadBalancerAttribute(loadBalancerId)
+	}, func(result interface{}) bool {
+		lb := result.(*LoadBalancerType)
+		return lb != nil && Status(lb.LoadBalancerStatus) == status
+	})
+	waiter.Retryable = isNotFound
+	_, err := waiter.Wait(context.Background())
+	if common.IsWaitTimeout(err) {
+		return common.GetClientErrorFromString(fmt.Sprintf("Timeout waitting for load balacner %#v", status))
 	}
-	return nil
+	return err
+}
+
+// isNotFound reports whether err is the NotFound error of a load balancer being created
+func isNotFound(err error) bool {
+	e, ok := err.(*common.Error)
+	return ok && e != nil && (e.StatusCode == 404 || e.Code == "InvalidLoadBalancerId.NotFound")
 }
 
 type SetLoadBalancerDeleteProtectionArgs struct {
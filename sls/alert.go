@@ -0,0 +1,165 @@
+package sls
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+type NotificationType string
+
+const (
+	NotificationTypeEmail         = NotificationType("Email")
+	NotificationTypeSMS           = NotificationType("SMS")
+	NotificationTypeDingTalk      = NotificationType("DingTalk")
+	NotificationTypeWebhook       = NotificationType("Webhook")
+	NotificationTypeMessageCenter = NotificationType("MessageCenter")
+)
+
+// Notification is a channel the alert is sent to. EmailList is used by Email,
+// MobileList by SMS, ServiceUri by DingTalk and Webhook.
+type Notification struct {
+	Type       NotificationType  `json:"type"`
+	Content    string            `json:"content"`
+	EmailList  []string          `json:"emailList,omitempty"`
+	MobileList []string          `json:"mobileList,omitempty"`
+	ServiceUri string            `json:"serviceUri,omitempty"`
+	Method     string            `json:"method,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+}
+
+type AlertQuery struct {
+	ChartTitle   string `json:"chartTitle"`
+	LogStore     string `json:"logStore"`
+	Query        string `json:"query"`
+	TimeSpanType string `json:"timeSpanType"` // "Relative", "Truncated" or "Custom"
+	Start        string `json:"start"`
+	End          string `json:"end"`
+}
+
+type AlertConfiguration struct {
+	Condition        string         `json:"condition"`
+	Dashboard        string         `json:"dashboard"`
+	QueryList        []AlertQuery   `json:"queryList"`
+	NotificationList []Notification `json:"notificationList"`
+	MuteUntil        int64          `json:"muteUntil,omitempty"`
+	Throttling       string         `json:"throttling,omitempty"`
+	NotifyThreshold  int            `json:"notifyThreshold,omitempty"`
+}
+
+type ScheduleType string
+
+const (
+	ScheduleTypeFixedRate = ScheduleType("FixedRate")
+	ScheduleTypeCron      = ScheduleType("Cron")
+	ScheduleTypeResident  = ScheduleType("Resident")
+)
+
+type Schedule struct {
+	Type           ScheduleType `json:"type"`
+	Interval       string       `json:"interval,omitempty"` // e.g. "5m"
+	CronExpression string       `json:"cronExpression,omitempty"`
+	Delay          int          `json:"delay,omitempty"`
+	RunImmediately bool         `json:"runImmediately,omitempty"`
+}
+
+type Alert struct {
+	Name             string              `json:"name"`
+	DisplayName      string              `json:"displayName,omitempty"`
+	Description      string              `json:"description,omitempty"`
+	State            string              `json:"state,omitempty"` // "Enabled" or "Disabled"
+	Schedule         *Schedule           `json:"schedule"`
+	Configuration    *AlertConfiguration `json:"configuration"`
+	CreateTime       int64               `json:"createTime,omitempty"`
+	LastModifiedTime int64               `json:"lastModifiedTime,omitempty"`
+}
+
+func (proj *Project) CreateAlert(alert *Alert) error {
+	data, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	req := &request{
+		method:      METHOD_POST,
+		path:        "/alerts",
+		payload:     data,
+		contentType: "application/json",
+	}
+	return proj.client.requestWithClose(req)
+}
+
+func (proj *Project) GetAlert(name string) (*Alert, error) {
+	req := &request{
+		method: METHOD_GET,
+		path:   "/alerts/" + name,
+	}
+
+	alert := &Alert{}
+	if err := proj.client.requestWithJsonResponse(req, alert); err != nil {
+		return nil, err
+	}
+	return alert, nil
+}
+
+func (proj *Project) UpdateAlert(alert *Alert) error {
+	data, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+
+	req := &request{
+		method:      METHOD_PUT,
+		path:        "/alerts/" + alert.Name,
+		payload:     data,
+		contentType: "application/json",
+	}
+	return proj.client.requestWithClose(req)
+}
+
+func (proj *Project) DeleteAlert(name string) error {
+	req := &request{
+		method: METHOD_DELETE,
+		path:   "/alerts/" + name,
+	}
+	return proj.client.requestWithClose(req)
+}
+
+func (proj *Project) EnableAlert(name string) error {
+	return proj.alertAction(name, "enable")
+}
+
+func (proj *Project) DisableAlert(name string) error {
+	return proj.alertAction(name, "disable")
+}
+
+func (proj *Project) alertAction(name, action string) error {
+	req := &request{
+		method: METHOD_PUT,
+		path:   "/alerts/" + name,
+		params: map[string]string{"action": action},
+	}
+	return proj.client.requestWithClose(req)
+}
+
+type AlertList struct {
+	Count  int      `json:"count,omitempty"`
+	Total  int      `json:"total,omitempty"`
+	Alerts []*Alert `json:"results,omitempty"`
+}
+
+func (proj *Project) ListAlert(offset, size int) (*AlertList, error) {
+	req := &request{
+		method: METHOD_GET,
+		path:   "/alerts",
+		params: map[string]string{
+			"size":   strconv.Itoa(size),
+			"offset": strconv.Itoa(offset),
+		},
+	}
+
+	list := &AlertList{}
+	if err := proj.client.requestWithJsonResponse(req, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
@@ -0,0 +1,60 @@
+package sls
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCreateAlert(t *testing.T) {
+	transport := &captureTransport{}
+	p := newCaptureProject(transport)
+
+	alert := &Alert{
+		Name:  "error-alert",
+		State: "Enabled",
+		Schedule: &Schedule{
+			Type:     ScheduleTypeFixedRate,
+			Interval: "5m",
+		},
+		Configuration: &AlertConfiguration{
+			Condition: "count > 10",
+			Dashboard: "dashboard",
+			QueryList: []AlertQuery{
+				{
+					ChartTitle:   "errors",
+					LogStore:     "app-log",
+					Query:        "level: ERROR | select count(1) as count",
+					TimeSpanType: "Relative",
+					Start:        "-5m",
+					End:          "now",
+				},
+			},
+			NotificationList: []Notification{
+				{
+					Type:       NotificationTypeDingTalk,
+					Content:    "${count} errors",
+					ServiceUri: "https://oapi.dingtalk.com/robot/send?access_token=token",
+				},
+			},
+		},
+	}
+	if err := p.CreateAlert(alert); err != nil {
+		t.Fatalf("Failed to create alert: %v", err)
+	}
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(transport.payload, &body); err != nil {
+		t.Fatalf("Invalid alert payload: %v", err)
+	}
+	notifications := body["configuration"].(map[string]interface{})["notificationList"].([]interface{})
+	if notifications[0].(map[string]interface{})["type"] != "DingTalk" {
+		t.Errorf("Unexpected notification payload: %s", transport.payload)
+	}
+
+	if err := p.DisableAlert("error-alert"); err != nil {
+		t.Fatalf("Failed to disable alert: %v", err)
+	}
+	if transport.req.Method != METHOD_PUT || transport.req.URL.Query().Get("action") != "disable" {
+		t.Errorf("Unexpected request %s %s", transport.req.Method, transport.req.URL)
+	}
+}
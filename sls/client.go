@@ -3,11 +3,14 @@ package sls
 import (
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/denverdino/aliyungo/common"
 	"github.com/golang/protobuf/proto"
-	//"time"
+	"github.com/opentracing/opentracing-go"
 	"os"
 	"strconv"
 )
@@ -22,12 +25,61 @@ type Client struct {
 	internal        bool
 	region          common.Region
 	endpoint        string
+	scheme          string
+	userAgent       string
+	disableTrace    bool
+	span            opentracing.Span
+	logger          *log.Logger
 }
 
 func (client *Client) SetDebug(debug bool) {
 	client.debug = debug
 }
 
+// SetEndpoint sets the endpoint suffix used to build the project domain.
+// A "http://" or "https://" prefix also sets the scheme, which is kept
+// otherwise.
+func (client *Client) SetEndpoint(endpoint string) {
+	scheme, endpoint := parseEndpoint(endpoint)
+	if scheme != "" {
+		client.scheme = scheme
+	}
+	client.endpoint = endpoint
+}
+
+// SetScheme sets the scheme of the requests, "https" by default
+func (client *Client) SetScheme(scheme string) {
+	client.scheme = strings.ToLower(scheme)
+}
+
+// SetUserAgent sets user agent to the request message
+func (client *Client) SetUserAgent(userAgent string) {
+	client.userAgent = userAgent
+}
+
+// SetTimeout sets the time limit of the requests, including reading the response body
+func (client *Client) SetTimeout(timeout time.Duration) {
+	if client.httpClient == nil {
+		client.httpClient = &http.Client{}
+	}
+	client.httpClient.Timeout = timeout
+}
+
+// SetDisableTrace close trace mode
+func (client *Client) SetDisableTrace(disableTrace bool) {
+	client.disableTrace = disableTrace
+}
+
+// SetSpan set the parent span
+func (client *Client) SetSpan(span opentracing.Span) {
+	client.span = span
+}
+
+// SetLogger sets the logger receiving the debug messages, the standard logger by default
+func (client *Client) SetLogger(logger *log.Logger) {
+	client.logger = logger
+}
+
 // SetTransport sets transport to the http client
 func (client *Client) SetTransport(transport http.RoundTripper) {
 	if client.httpClient == nil {
@@ -67,6 +119,7 @@ type PutLogsRequest struct {
 
 const (
 	SLSDefaultEndpoint = "log.aliyuncs.com"
+	SLSDefaultScheme   = "https"
 	SLSAPIVersion      = "0.6.0"
 	METHOD_GET         = "GET"
 	METHOD_POST        = "POST"
@@ -89,19 +142,16 @@ func NewClientForAssumeRole(region common.Region, internal bool, accessKeyId, ac
 		endpoint = SLSDefaultEndpoint
 	}
 
-	return &Client{
-		accessKeyId:     accessKeyId,
-		accessKeySecret: accessKeySecret,
-		securityToken:   securityToken,
-		internal:        internal,
-		region:          region,
-		version:         SLSAPIVersion,
-		endpoint:        endpoint,
-		httpClient:      &http.Client{},
-	}
+	client := NewClientWithEndpoint(endpoint, region, internal, accessKeyId, accessKeySecret)
+	client.securityToken = securityToken
+	return client
 }
 
 func NewClientWithEndpoint(endpoint string, region common.Region, internal bool, accessKeyId, accessKeySecret string) *Client {
+	scheme, endpoint := parseEndpoint(endpoint)
+	if scheme == "" {
+		scheme = SLSDefaultScheme
+	}
 	return &Client{
 		accessKeyId:     accessKeyId,
 		accessKeySecret: accessKeySecret,
@@ -109,10 +159,20 @@ func NewClientWithEndpoint(endpoint string, region common.Region, internal bool,
 		region:          region,
 		version:         SLSAPIVersion,
 		endpoint:        endpoint,
+		scheme:          scheme,
 		httpClient:      &http.Client{},
 	}
 }
 
+// parseEndpoint splits an optional scheme prefix from the endpoint, the
+// scheme is empty without prefix
+func parseEndpoint(endpoint string) (string, string) {
+	if i := strings.Index(endpoint, "://"); i >= 0 {
+		return strings.ToLower(endpoint[:i]), endpoint[i+3:]
+	}
+	return "", endpoint
+}
+
 func (client *Client) Project(name string) (*Project, error) {
 
 	//	newClient := client.forProject(name)
@@ -218,20 +278,38 @@ func (client *Client) PutLogs(putLogRequest *PutLogsRequest) error {
 		return nil
 	}
 
-	data, err := proto.Marshal(&putLogRequest.LogItems)
+	return client.putLogGroup(putLogRequest.Project, putLogRequest.LogStore, putLogRequest.HashKey, &putLogRequest.LogItems, nil)
+}
+
+// putLogGroup posts a log group to the logstore, routing it to the shard
+// owning hashKey when one is given and compressing it when compressor is set
+func (client *Client) putLogGroup(project, logstore, hashKey string, logGroup *LogGroup, compressor Compressor) error {
+	data, err := proto.Marshal(logGroup)
 	if err != nil {
 		return err
 	}
 
+	headers := map[string]string{"x-log-bodyrawsize": strconv.Itoa(len(data))}
+	if compressor != nil {
+		data, err = compressor.Compress(data)
+		if err != nil {
+			return err
+		}
+		headers["x-log-compresstype"] = compressor.CompressType()
+	}
+
 	req := &request{
 		method:      METHOD_POST,
-		path:        "/logstores/" + putLogRequest.LogStore + "/shards/lb",
+		path:        "/logstores/" + logstore + "/shards/lb",
 		payload:     data,
 		contentType: "application/x-protobuf",
-		headers:     map[string]string{"x-log-bodyrawsize": strconv.Itoa(len(data))},
+		headers:     headers,
+	}
+	if hashKey != "" {
+		req.path = "/logstores/" + logstore + "/shards/route"
+		req.params = map[string]string{"key": hashKey}
 	}
 
-	newClient := client.forProject(putLogRequest.Project)
+	newClient := client.forProject(project)
 	return newClient.requestWithClose(req)
-
 }
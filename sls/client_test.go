@@ -1,7 +1,12 @@
 package sls
 
 import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"net/http"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/denverdino/aliyungo/common"
@@ -56,3 +61,92 @@ func NewTestClientForDebug() *Client {
 	}
 	return testDebugClient
 }
+
+// captureTransport records the last request and replies with response, "{}" by default
+type captureTransport struct {
+	req      *http.Request
+	payload  []byte
+	response string
+}
+
+func (c *captureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.req = req
+	c.payload = nil
+	if req.Body != nil {
+		c.payload, _ = ioutil.ReadAll(req.Body)
+	}
+	response := c.response
+	if response == "" {
+		response = "{}"
+	}
+	return &http.Response{
+		StatusCode: 200,
+		Status:     "200 OK",
+		Body:       ioutil.NopCloser(bytes.NewBufferString(response)),
+		Request:    req,
+	}, nil
+}
+
+func newCaptureProject(transport *captureTransport) *Project {
+	client := NewClient(Region, false, "id", "secret")
+	client.SetTransport(transport)
+	p, _ := client.Project("project")
+	return p
+}
+
+func TestClientSchemeAndUserAgent(t *testing.T) {
+	transport := &captureTransport{}
+	client := NewClientForAssumeRole(Region, false, "id", "secret", "token")
+	client.SetEndpoint(SLSDefaultEndpoint)
+	client.SetTransport(transport)
+	client.SetUserAgent("test-agent")
+
+	p, _ := client.Project("project")
+	if _, err := p.GetLogstore("test"); err != nil {
+		t.Fatalf("Failed to get logstore: %v", err)
+	}
+	if transport.req.URL.Scheme != "https" {
+		t.Errorf("Expected https by default, got %s", transport.req.URL.Scheme)
+	}
+	if ua := transport.req.Header.Get("User-Agent"); !strings.HasSuffix(ua, " test-agent") {
+		t.Errorf("Unexpected user agent: %s", ua)
+	}
+
+	client.SetEndpoint("http://" + SLSDefaultEndpoint)
+	p, _ = client.Project("project")
+	if _, err := p.GetLogstore("test"); err != nil {
+		t.Fatalf("Failed to get logstore: %v", err)
+	}
+	if transport.req.URL.Scheme != "http" || transport.req.URL.Host != "project.cn-hangzhou.log.aliyuncs.com" {
+		t.Errorf("Unexpected url: %s", transport.req.URL)
+	}
+
+	client.SetEndpoint(SLSDefaultEndpoint)
+	p, _ = client.Project("project")
+	if _, err := p.GetLogstore("test"); err != nil {
+		t.Fatalf("Failed to get logstore: %v", err)
+	}
+	if transport.req.URL.Scheme != "http" {
+		t.Errorf("Expected the scheme to be kept, got %s", transport.req.URL.Scheme)
+	}
+}
+
+func TestClientDebugRedactsCredentials(t *testing.T) {
+	var buf bytes.Buffer
+	client := NewClientForAssumeRole(Region, false, "id", "secret", "token")
+	client.SetTransport(&captureTransport{})
+	client.SetDebug(true)
+	client.SetLogger(log.New(&buf, "", 0))
+
+	p, _ := client.Project("project")
+	if _, err := p.GetLogstore("test"); err != nil {
+		t.Fatalf("Failed to get logstore: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "REQUEST") {
+		t.Fatalf("Expected the request to be logged, got %s", out)
+	}
+	if strings.Contains(out, "LOG id:") || strings.Contains(out, ": token") {
+		t.Errorf("Credentials leaked in debug log: %s", out)
+	}
+}
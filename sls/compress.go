@@ -0,0 +1,131 @@
+package sls
+
+import (
+	"encoding/binary"
+)
+
+const (
+	CompressTypeLZ4  = "lz4"
+	CompressTypeZstd = "zstd"
+)
+
+// Compressor compresses the serialized log group before it is sent to SLS.
+// CompressType is the value of the x-log-compresstype header.
+type Compressor interface {
+	CompressType() string
+	Compress(data []byte) ([]byte, error)
+}
+
+type compressorFunc struct {
+	compressType string
+	compress     func(data []byte) ([]byte, error)
+}
+
+func (c *compressorFunc) CompressType() string {
+	return c.compressType
+}
+
+func (c *compressorFunc) Compress(data []byte) ([]byte, error) {
+	return c.compress(data)
+}
+
+// NewCompressor wraps a compression function as a Compressor, e.g. to send
+// zstd compressed payloads with an external zstd encoder:
+//
+//	sls.NewCompressor(sls.CompressTypeZstd, func(data []byte) ([]byte, error) {
+//		return encoder.EncodeAll(data, nil), nil
+//	})
+func NewCompressor(compressType string, compress func(data []byte) ([]byte, error)) Compressor {
+	return &compressorFunc{compressType: compressType, compress: compress}
+}
+
+// LZ4Compressor compresses payloads with the LZ4 block format
+var LZ4Compressor = NewCompressor(CompressTypeLZ4, func(data []byte) ([]byte, error) {
+	return lz4CompressBlock(data), nil
+})
+
+const (
+	lz4MinMatch     = 4
+	lz4LastLiterals = 5
+	lz4MFLimit      = 12
+	lz4HashLog      = 16
+	lz4MaxOffset    = 65535
+)
+
+// lz4CompressBlock encodes src as a single raw LZ4 block (no frame header),
+// which is what SLS expects together with x-log-bodyrawsize
+func lz4CompressBlock(src []byte) []byte {
+	dst := make([]byte, 0, len(src)+len(src)/255+16)
+
+	var table [1 << lz4HashLog]int32
+	anchor := 0
+	limit := len(src) - lz4MFLimit
+	matchLimit := len(src) - lz4LastLiterals
+
+	for i := 0; i < limit; {
+		seq := binary.LittleEndian.Uint32(src[i:])
+		h := (seq * 2654435761) >> (32 - lz4HashLog)
+		ref := int(table[h]) - 1
+		table[h] = int32(i + 1)
+
+		if ref < 0 || i-ref > lz4MaxOffset || binary.LittleEndian.Uint32(src[ref:]) != seq {
+			i++
+			continue
+		}
+
+		end := i + lz4MinMatch
+		for end < matchLimit && src[end] == src[ref+end-i] {
+			end++
+		}
+		for i > anchor && ref > 0 && src[i-1] == src[ref-1] {
+			i--
+			ref--
+		}
+
+		dst = lz4AppendSequence(dst, src[anchor:i], i-ref, end-i)
+		i = end
+		anchor = end
+	}
+
+	return lz4AppendLiterals(dst, src[anchor:])
+}
+
+func lz4AppendSequence(dst, literals []byte, offset, matchLen int) []byte {
+	litLen := len(literals)
+	matchLen -= lz4MinMatch
+
+	dst = append(dst, lz4Nibble(litLen)<<4|lz4Nibble(matchLen))
+	if litLen >= 15 {
+		dst = lz4AppendLength(dst, litLen-15)
+	}
+	dst = append(dst, literals...)
+	dst = append(dst, byte(offset), byte(offset>>8))
+	if matchLen >= 15 {
+		dst = lz4AppendLength(dst, matchLen-15)
+	}
+	return dst
+}
+
+func lz4AppendLiterals(dst, literals []byte) []byte {
+	litLen := len(literals)
+	dst = append(dst, lz4Nibble(litLen)<<4)
+	if litLen >= 15 {
+		dst = lz4AppendLength(dst, litLen-15)
+	}
+	return append(dst, literals...)
+}
+
+func lz4AppendLength(dst []byte, n int) []byte {
+	for n >= 255 {
+		dst = append(dst, 255)
+		n -= 255
+	}
+	return append(dst, byte(n))
+}
+
+func lz4Nibble(n int) byte {
+	if n >= 15 {
+		return 15
+	}
+	return byte(n)
+}
@@ -0,0 +1,163 @@
+package sls
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+type ChartType string
+
+const (
+	ChartTypeTable  = ChartType("table")
+	ChartTypeLine   = ChartType("line")
+	ChartTypeBar    = ChartType("bar")
+	ChartTypeArea   = ChartType("area")
+	ChartTypePie    = ChartType("pie")
+	ChartTypeNumber = ChartType("number")
+	ChartTypeMap    = ChartType("map")
+)
+
+type ChartSearch struct {
+	Logstore string `json:"logstore"`
+	Topic    string `json:"topic"`
+	Query    string `json:"query"`
+	Start    string `json:"start"` // Relative time such as "-3600s" or unix seconds
+	End      string `json:"end"`   // "now" or unix seconds
+}
+
+type ChartDisplay struct {
+	XAxis       []string `json:"xAxis,omitempty"`
+	YAxis       []string `json:"yAxis,omitempty"`
+	XPos        int      `json:"xPos"`
+	YPos        int      `json:"yPos"`
+	Width       int      `json:"width"`
+	Height      int      `json:"height"`
+	DisplayName string   `json:"displayName,omitempty"`
+}
+
+type Chart struct {
+	Title   string       `json:"title"`
+	Type    ChartType    `json:"type"`
+	Search  ChartSearch  `json:"search"`
+	Display ChartDisplay `json:"display"`
+}
+
+type Dashboard struct {
+	Name        string  `json:"dashboardName"`
+	DisplayName string  `json:"displayName,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Charts      []Chart `json:"charts"`
+}
+
+func (proj *Project) CreateDashboard(dashboard *Dashboard) error {
+	data, err := json.Marshal(dashboard)
+	if err != nil {
+		return err
+	}
+
+	req := &request{
+		method:      METHOD_POST,
+		path:        "/dashboards",
+		payload:     data,
+		contentType: "application/json",
+	}
+	return proj.client.requestWithClose(req)
+}
+
+func (proj *Project) GetDashboard(name string) (*Dashboard, error) {
+	req := &request{
+		method: METHOD_GET,
+		path:   "/dashboards/" + name,
+	}
+
+	dashboard := &Dashboard{}
+	if err := proj.client.requestWithJsonResponse(req, dashboard); err != nil {
+		return nil, err
+	}
+	return dashboard, nil
+}
+
+func (proj *Project) UpdateDashboard(dashboard *Dashboard) error {
+	data, err := json.Marshal(dashboard)
+	if err != nil {
+		return err
+	}
+
+	req := &request{
+		method:      METHOD_PUT,
+		path:        "/dashboards/" + dashboard.Name,
+		payload:     data,
+		contentType: "application/json",
+	}
+	return proj.client.requestWithClose(req)
+}
+
+func (proj *Project) DeleteDashboard(name string) error {
+	req := &request{
+		method: METHOD_DELETE,
+		path:   "/dashboards/" + name,
+	}
+	return proj.client.requestWithClose(req)
+}
+
+// CreateChart adds a chart to an existing dashboard
+func (proj *Project) CreateChart(dashboardName string, chart *Chart) error {
+	data, err := json.Marshal(chart)
+	if err != nil {
+		return err
+	}
+
+	req := &request{
+		method:      METHOD_POST,
+		path:        "/dashboards/" + dashboardName + "/charts",
+		payload:     data,
+		contentType: "application/json",
+	}
+	return proj.client.requestWithClose(req)
+}
+
+func (proj *Project) UpdateChart(dashboardName string, chart *Chart) error {
+	data, err := json.Marshal(chart)
+	if err != nil {
+		return err
+	}
+
+	req := &request{
+		method:      METHOD_PUT,
+		path:        "/dashboards/" + dashboardName + "/charts/" + chart.Title,
+		payload:     data,
+		contentType: "application/json",
+	}
+	return proj.client.requestWithClose(req)
+}
+
+func (proj *Project) DeleteChart(dashboardName, chartTitle string) error {
+	req := &request{
+		method: METHOD_DELETE,
+		path:   "/dashboards/" + dashboardName + "/charts/" + chartTitle,
+	}
+	return proj.client.requestWithClose(req)
+}
+
+type DashboardList struct {
+	Count      int      `json:"count,omitempty"`
+	Total      int      `json:"total,omitempty"`
+	Dashboards []string `json:"dashboards,omitempty"`
+}
+
+func (proj *Project) ListDashboard(offset, size int) (*DashboardList, error) {
+	req := &request{
+		method: METHOD_GET,
+		path:   "/dashboards",
+		params: map[string]string{
+			"size":   strconv.Itoa(size),
+			"offset": strconv.Itoa(offset),
+		},
+	}
+
+	list := &DashboardList{}
+	if err := proj.client.requestWithJsonResponse(req, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
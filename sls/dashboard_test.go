@@ -0,0 +1,51 @@
+package sls
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDashboardRoundTrip(t *testing.T) {
+	transport := &captureTransport{}
+	p := newCaptureProject(transport)
+
+	dashboard := &Dashboard{
+		Name:        "dashboard",
+		DisplayName: "Dashboard",
+		Charts: []Chart{
+			{
+				Title: "pv",
+				Type:  ChartTypeLine,
+				Search: ChartSearch{
+					Logstore: "access-log",
+					Query:    "* | select count(1) as pv, __time__ - __time__ % 60 as t group by t",
+					Start:    "-3600s",
+					End:      "now",
+				},
+				Display: ChartDisplay{
+					XAxis:  []string{"t"},
+					YAxis:  []string{"pv"},
+					Width:  5,
+					Height: 5,
+				},
+			},
+		},
+	}
+	if err := p.CreateDashboard(dashboard); err != nil {
+		t.Fatalf("Failed to create dashboard: %v", err)
+	}
+	if transport.req.Method != METHOD_POST || transport.req.URL.Path != "/dashboards" {
+		t.Errorf("Unexpected request %s %s", transport.req.Method, transport.req.URL.Path)
+	}
+
+	transport.response = string(transport.payload)
+	got, err := p.GetDashboard("dashboard")
+	if err != nil {
+		t.Fatalf("Failed to get dashboard: %v", err)
+	}
+	if !reflect.DeepEqual(got, dashboard) {
+		data, _ := json.Marshal(got)
+		t.Errorf("Dashboard does not round trip: %s", data)
+	}
+}
@@ -0,0 +1,180 @@
+//go:build go1.21
+// +build go1.21
+
+package sls
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+)
+
+type HandlerOptions struct {
+	// Topic of the logs, usually the service name
+	Topic string
+
+	// Source of the logs, the host name by default
+	Source string
+
+	// Level is the minimum level of the records sent, slog.LevelInfo by default
+	Level slog.Leveler
+
+	// AddSource adds the "source" key with the file:line of the log call
+	AddSource bool
+
+	// ReplaceAttr rewrites or drops (by returning an empty Attr) each non-group attribute
+	ReplaceAttr func(groups []string, a slog.Attr) slog.Attr
+}
+
+// Handler is a slog.Handler sending every record as a log through a Producer.
+// The message and level are stored under the slog.MessageKey and slog.LevelKey
+// keys, attributes in groups are flattened into "group.key" keys.
+//
+//	producer := sls.NewProducer(client, nil)
+//	defer producer.Close()
+//	logger := slog.New(sls.NewHandler(producer, "project", "logstore", &sls.HandlerOptions{Topic: "order-service"}))
+type Handler struct {
+	producer *Producer
+	project  string
+	logstore string
+	opts     HandlerOptions
+
+	groups   []string
+	contents []*Log_Content // preformatted attributes from WithAttrs
+}
+
+// NewHandler creates a Handler sending the records to the logstore through the producer
+func NewHandler(producer *Producer, project, logstore string, opts *HandlerOptions) *Handler {
+	h := &Handler{
+		producer: producer,
+		project:  project,
+		logstore: logstore,
+	}
+	if opts != nil {
+		h.opts = *opts
+	}
+	if h.opts.Source == "" {
+		h.opts.Source = hostSource()
+	}
+	return h
+}
+
+func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+	return level >= minLevel
+}
+
+func (h *Handler) Handle(_ context.Context, r slog.Record) error {
+	t := r.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+
+	contents := make([]*Log_Content, 0, 3+len(h.contents)+r.NumAttrs())
+	contents = h.appendAttr(contents, nil, slog.String(slog.LevelKey, r.Level.String()))
+	contents = h.appendAttr(contents, nil, slog.String(slog.MessageKey, r.Message))
+	if h.opts.AddSource && r.PC != 0 {
+		frame := r.Source()
+		contents = h.appendAttr(contents, nil, slog.String(slog.SourceKey, frame.File+":"+strconv.Itoa(frame.Line)))
+	}
+	contents = append(contents, h.contents...)
+	r.Attrs(func(a slog.Attr) bool {
+		contents = h.appendAttr(contents, h.groups, a)
+		return true
+	})
+
+	log := &Log{
+		Time:     proto.Uint32(uint32(t.Unix())),
+		Contents: contents,
+	}
+	return h.producer.Send(h.project, h.logstore, h.opts.Topic, h.opts.Source, log)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.contents = append([]*Log_Content(nil), h.contents...)
+	for _, a := range attrs {
+		h2.contents = h.appendAttr(h2.contents, h.groups, a)
+	}
+	return &h2
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.groups = append(append([]string(nil), h.groups...), name)
+	return &h2
+}
+
+func (h *Handler) appendAttr(contents []*Log_Content, groups []string, a slog.Attr) []*Log_Content {
+	a.Value = a.Value.Resolve()
+	if a.Value.Kind() == slog.KindGroup {
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return contents
+		}
+		if a.Key != "" {
+			groups = append(append([]string(nil), groups...), a.Key)
+		}
+		for _, ga := range attrs {
+			contents = h.appendAttr(contents, groups, ga)
+		}
+		return contents
+	}
+
+	if h.opts.ReplaceAttr != nil {
+		a = h.opts.ReplaceAttr(groups, a)
+		a.Value = a.Value.Resolve()
+	}
+	if a.Equal(slog.Attr{}) {
+		return contents
+	}
+
+	key := a.Key
+	if len(groups) > 0 {
+		key = strings.Join(groups, ".") + "." + key
+	}
+	return append(contents, &Log_Content{
+		Key:   proto.String(key),
+		Value: proto.String(formatValue(a.Value)),
+	})
+}
+
+func formatValue(v slog.Value) string {
+	switch v.Kind() {
+	case slog.KindString:
+		return v.String()
+	case slog.KindTime:
+		return v.Time().Format(time.RFC3339Nano)
+	case slog.KindAny:
+		switch x := v.Any().(type) {
+		case error:
+			return x.Error()
+		case fmt.Stringer:
+			return x.String()
+		case []byte:
+			return string(x)
+		default:
+			if data, err := json.Marshal(x); err == nil {
+				return string(data)
+			}
+			return fmt.Sprint(x)
+		}
+	default:
+		return v.String()
+	}
+}
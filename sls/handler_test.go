@@ -0,0 +1,61 @@
+//go:build go1.21
+// +build go1.21
+
+package sls
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestHandler(t *testing.T) {
+	recorder := &putLogsRecorder{t: t}
+	client := NewClientWithEndpoint("log.example.com", Region, false, "id", "secret")
+	client.SetTransport(recorder)
+	producer := NewProducer(client, &ProducerConfig{LingerTime: time.Hour})
+
+	logger := slog.New(NewHandler(producer, "project", "logstore", &HandlerOptions{
+		Topic:  "service",
+		Source: "host",
+		Level:  slog.LevelInfo,
+	}))
+	logger.Debug("dropped")
+	logger.With("request_id", "r-1").WithGroup("http").Info("served",
+		"status", 200,
+		slog.Group("client", "ip", "10.0.0.1"),
+		"err", errors.New("timeout"),
+		"tags", []string{"a", "b"})
+	producer.Close()
+
+	if len(recorder.requests) != 1 {
+		t.Fatalf("Expected 1 request, got %d", len(recorder.requests))
+	}
+	logGroup := recorder.requests[0].logGroup
+	if logGroup.GetTopic() != "service" || logGroup.GetSource() != "host" {
+		t.Errorf("Unexpected topic/source: %s/%s", logGroup.GetTopic(), logGroup.GetSource())
+	}
+	if len(logGroup.Logs) != 1 {
+		t.Fatalf("Expected 1 log, got %d", len(logGroup.Logs))
+	}
+
+	contents := make(map[string]string)
+	for _, content := range logGroup.Logs[0].Contents {
+		contents[content.GetKey()] = content.GetValue()
+	}
+	expected := map[string]string{
+		"level":          "INFO",
+		"msg":            "served",
+		"request_id":     "r-1",
+		"http.status":    "200",
+		"http.client.ip": "10.0.0.1",
+		"http.err":       "timeout",
+		"http.tags":      `["a","b"]`,
+	}
+	for k, v := range expected {
+		if contents[k] != v {
+			t.Errorf("Expected %s=%q, got %q", k, v, contents[k])
+		}
+	}
+}
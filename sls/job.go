@@ -0,0 +1,241 @@
+package sls
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+type JobType string
+
+const (
+	JobTypeETL          = JobType("ETL")
+	JobTypeScheduledSQL = JobType("ScheduledSQL")
+)
+
+type ETLSink struct {
+	Name            string `json:"name"`
+	Endpoint        string `json:"endpoint"`
+	Project         string `json:"project"`
+	Logstore        string `json:"logstore"`
+	RoleArn         string `json:"roleArn,omitempty"`
+	AccessKeyId     string `json:"accessKeyId,omitempty"`
+	AccessKeySecret string `json:"accessKeySecret,omitempty"`
+}
+
+type ETLConfiguration struct {
+	Logstore        string            `json:"logstore"`
+	Script          string            `json:"script"`
+	Version         int               `json:"version"`
+	FromTime        int64             `json:"fromTime"`
+	ToTime          int64             `json:"toTime"` // 0 to run continuously
+	RoleArn         string            `json:"roleArn,omitempty"`
+	AccessKeyId     string            `json:"accessKeyId,omitempty"`
+	AccessKeySecret string            `json:"accessKeySecret,omitempty"`
+	Parameters      map[string]string `json:"parameters,omitempty"`
+	Sinks           []ETLSink         `json:"sinks"`
+}
+
+// ETL is a data transformation job reading a logstore continuously
+type ETL struct {
+	Name             string            `json:"name"`
+	DisplayName      string            `json:"displayName,omitempty"`
+	Description      string            `json:"description,omitempty"`
+	Type             JobType           `json:"type"`
+	Status           string            `json:"status,omitempty"`
+	Schedule         *Schedule         `json:"schedule"`
+	Configuration    *ETLConfiguration `json:"configuration"`
+	CreateTime       int64             `json:"createTime,omitempty"`
+	LastModifiedTime int64             `json:"lastModifiedTime,omitempty"`
+}
+
+type ScheduledSQLConfiguration struct {
+	SourceLogstore      string            `json:"sourceLogstore"`
+	DestProject         string            `json:"destProject"`
+	DestLogstore        string            `json:"destLogstore"`
+	DestEndpoint        string            `json:"destEndpoint"`
+	DestRoleArn         string            `json:"destRoleArn"`
+	RoleArn             string            `json:"roleArn"`
+	Script              string            `json:"script"`
+	SqlType             string            `json:"sqlType"`      // "searchQuery" or "standardSQL"
+	ResourcePool        string            `json:"resourcePool"` // "enhanced"
+	DataFormat          string            `json:"dataFormat"`   // "log2log" or "log2metric"
+	FromTimeExpr        string            `json:"fromTimeExpr"` // e.g. "@m-15m"
+	ToTimeExpr          string            `json:"toTimeExpr"`   // e.g. "@m"
+	FromTime            int64             `json:"fromTime"`
+	ToTime              int64             `json:"toTime"`
+	MaxRunTimeInSeconds int               `json:"maxRunTimeInSeconds"`
+	MaxRetries          int               `json:"maxRetries"`
+	Parameters          map[string]string `json:"parameters,omitempty"`
+}
+
+// ScheduledSQL is a job running a query periodically and writing the result to a logstore
+type ScheduledSQL struct {
+	Name             string                     `json:"name"`
+	DisplayName      string                     `json:"displayName,omitempty"`
+	Description      string                     `json:"description,omitempty"`
+	Type             JobType                    `json:"type"`
+	Status           string                     `json:"status,omitempty"`
+	Schedule         *Schedule                  `json:"schedule"`
+	Configuration    *ScheduledSQLConfiguration `json:"configuration"`
+	CreateTime       int64                      `json:"createTime,omitempty"`
+	LastModifiedTime int64                      `json:"lastModifiedTime,omitempty"`
+}
+
+type ETLList struct {
+	Count int    `json:"count,omitempty"`
+	Total int    `json:"total,omitempty"`
+	ETLs  []*ETL `json:"results,omitempty"`
+}
+
+type ScheduledSQLList struct {
+	Count         int             `json:"count,omitempty"`
+	Total         int             `json:"total,omitempty"`
+	ScheduledSQLs []*ScheduledSQL `json:"results,omitempty"`
+}
+
+func (proj *Project) CreateETL(etl *ETL) error {
+	etl.Type = JobTypeETL
+	if etl.Schedule == nil {
+		etl.Schedule = &Schedule{Type: ScheduleTypeResident}
+	}
+	return proj.createJob(etl)
+}
+
+func (proj *Project) GetETL(name string) (*ETL, error) {
+	etl := &ETL{}
+	if err := proj.getJob(name, etl); err != nil {
+		return nil, err
+	}
+	return etl, nil
+}
+
+func (proj *Project) UpdateETL(etl *ETL) error {
+	etl.Type = JobTypeETL
+	return proj.updateJob(etl.Name, etl)
+}
+
+func (proj *Project) DeleteETL(name string) error {
+	return proj.deleteJob(name)
+}
+
+func (proj *Project) StartETL(name string) error {
+	return proj.jobAction(name, "START")
+}
+
+func (proj *Project) StopETL(name string) error {
+	return proj.jobAction(name, "STOP")
+}
+
+func (proj *Project) ListETL(offset, size int) (*ETLList, error) {
+	list := &ETLList{}
+	if err := proj.listJobs(JobTypeETL, offset, size, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (proj *Project) CreateScheduledSQL(scheduledSQL *ScheduledSQL) error {
+	scheduledSQL.Type = JobTypeScheduledSQL
+	return proj.createJob(scheduledSQL)
+}
+
+func (proj *Project) GetScheduledSQL(name string) (*ScheduledSQL, error) {
+	scheduledSQL := &ScheduledSQL{}
+	if err := proj.getJob(name, scheduledSQL); err != nil {
+		return nil, err
+	}
+	return scheduledSQL, nil
+}
+
+func (proj *Project) UpdateScheduledSQL(scheduledSQL *ScheduledSQL) error {
+	scheduledSQL.Type = JobTypeScheduledSQL
+	return proj.updateJob(scheduledSQL.Name, scheduledSQL)
+}
+
+func (proj *Project) DeleteScheduledSQL(name string) error {
+	return proj.deleteJob(name)
+}
+
+func (proj *Project) EnableScheduledSQL(name string) error {
+	return proj.jobAction(name, "ENABLE")
+}
+
+func (proj *Project) DisableScheduledSQL(name string) error {
+	return proj.jobAction(name, "DISABLE")
+}
+
+func (proj *Project) ListScheduledSQL(offset, size int) (*ScheduledSQLList, error) {
+	list := &ScheduledSQLList{}
+	if err := proj.listJobs(JobTypeScheduledSQL, offset, size, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (proj *Project) createJob(job interface{}) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	req := &request{
+		method:      METHOD_POST,
+		path:        "/jobs",
+		payload:     data,
+		contentType: "application/json",
+	}
+	return proj.client.requestWithClose(req)
+}
+
+func (proj *Project) getJob(name string, job interface{}) error {
+	req := &request{
+		method: METHOD_GET,
+		path:   "/jobs/" + name,
+	}
+	return proj.client.requestWithJsonResponse(req, job)
+}
+
+func (proj *Project) updateJob(name string, job interface{}) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	req := &request{
+		method:      METHOD_PUT,
+		path:        "/jobs/" + name,
+		payload:     data,
+		contentType: "application/json",
+	}
+	return proj.client.requestWithClose(req)
+}
+
+func (proj *Project) deleteJob(name string) error {
+	req := &request{
+		method: METHOD_DELETE,
+		path:   "/jobs/" + name,
+	}
+	return proj.client.requestWithClose(req)
+}
+
+func (proj *Project) jobAction(name, action string) error {
+	req := &request{
+		method: METHOD_PUT,
+		path:   "/jobs/" + name,
+		params: map[string]string{"action": action},
+	}
+	return proj.client.requestWithClose(req)
+}
+
+func (proj *Project) listJobs(jobType JobType, offset, size int, list interface{}) error {
+	req := &request{
+		method: METHOD_GET,
+		path:   "/jobs",
+		params: map[string]string{
+			"jobType": string(jobType),
+			"size":    strconv.Itoa(size),
+			"offset":  strconv.Itoa(offset),
+		},
+	}
+	return proj.client.requestWithJsonResponse(req, list)
+}
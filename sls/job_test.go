@@ -0,0 +1,53 @@
+package sls
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestCreateETL(t *testing.T) {
+	transport := &captureTransport{}
+	p := newCaptureProject(transport)
+
+	etl := &ETL{
+		Name: "etl",
+		Configuration: &ETLConfiguration{
+			Logstore: "source",
+			Script:   "e_set(\"tag\", \"value\")",
+			Version:  2,
+			RoleArn:  "acs:ram::1:role/aliyunlogetlrole",
+			Sinks: []ETLSink{
+				{Name: "target", Project: "project", Logstore: "target", Endpoint: "cn-hangzhou.log.aliyuncs.com"},
+			},
+		},
+	}
+	if err := p.CreateETL(etl); err != nil {
+		t.Fatalf("Failed to create ETL: %v", err)
+	}
+
+	got := &ETL{}
+	if err := json.Unmarshal(transport.payload, got); err != nil {
+		t.Fatalf("Invalid ETL payload: %v", err)
+	}
+	if got.Type != JobTypeETL || got.Schedule == nil || got.Schedule.Type != ScheduleTypeResident {
+		t.Errorf("Unexpected ETL payload: %s", transport.payload)
+	}
+}
+
+func TestListScheduledSQL(t *testing.T) {
+	transport := &captureTransport{
+		response: `{"count":1,"total":1,"results":[{"name":"sql","type":"ScheduledSQL","schedule":{"type":"FixedRate","interval":"15m"}}]}`,
+	}
+	p := newCaptureProject(transport)
+
+	list, err := p.ListScheduledSQL(0, 10)
+	if err != nil {
+		t.Fatalf("Failed to list scheduled SQL: %v", err)
+	}
+	if transport.req.URL.Query().Get("jobType") != "ScheduledSQL" {
+		t.Errorf("Unexpected request %s", transport.req.URL)
+	}
+	if list.Total != 1 || list.ScheduledSQLs[0].Name != "sql" || list.ScheduledSQLs[0].Schedule.Interval != "15m" {
+		t.Errorf("Unexpected list %+v", list)
+	}
+}
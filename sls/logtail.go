@@ -5,6 +5,18 @@ import (
 	"strconv"
 )
 
+const (
+	LogtailInputTypeFile   = "file"
+	LogtailInputTypePlugin = "plugin"
+
+	LogtailLogTypeRegex     = "common_reg_log"
+	LogtailLogTypeDelimiter = "delimiter_log"
+	LogtailLogTypeJSON      = "json_log"
+	LogtailLogTypeApsara    = "apsara_log"
+
+	LogtailOutputTypeLogService = "LogService"
+)
+
 type LogtailInput struct {
 	LogType       string   `json:"logType,omitempty"`
 	LogPath       string   `json:"logPath,omitempty"`
@@ -22,6 +34,28 @@ type LogtailInput struct {
 	Separator     string   `json:"separator,omitempty"`
 	Quote         string   `json:"quote,omitempty"`
 	AutoExtend    bool     `json:"autoExtend,omitempty"`
+	MaxDepth      int      `json:"maxDepth,omitempty"`
+
+	DiscardUnmatch     bool              `json:"discardUnmatch,omitempty"`
+	DockerFile         bool              `json:"dockerFile,omitempty"`
+	DockerIncludeLabel map[string]string `json:"dockerIncludeLabel,omitempty"`
+	DockerExcludeLabel map[string]string `json:"dockerExcludeLabel,omitempty"`
+	DockerIncludeEnv   map[string]string `json:"dockerIncludeEnv,omitempty"`
+	DockerExcludeEnv   map[string]string `json:"dockerExcludeEnv,omitempty"`
+
+	// Plugin is the pipeline of the "plugin" input type
+	Plugin *LogtailPlugin `json:"plugin,omitempty"`
+}
+
+type LogtailPluginItem struct {
+	Type   string                 `json:"type"`
+	Detail map[string]interface{} `json:"detail"`
+}
+
+type LogtailPlugin struct {
+	Inputs     []LogtailPluginItem `json:"inputs"`
+	Processors []LogtailPluginItem `json:"processors,omitempty"`
+	Flushers   []LogtailPluginItem `json:"flushers,omitempty"`
 }
 
 type LogtailOutput struct {
@@ -0,0 +1,328 @@
+package sls
+
+import (
+	"fmt"
+	"path"
+)
+
+const dockerStdoutPluginType = "service_docker_stdout"
+
+// LogtailConfigBuilder builds a LogtailConfig for one of the Logtail input modes
+//
+//	config, err := sls.NewRegexLogtailConfig("nginx", "access-log", `(\S+) - (\S+) \[([^\]]+)\]`, "ip", "user", "time").
+//		WithFilePath("/var/log/nginx", "access.log").
+//		WithTimeKey("time", "%d/%b/%Y:%H:%M:%S").
+//		Build()
+type LogtailConfigBuilder struct {
+	config *LogtailConfig
+}
+
+func newLogtailConfigBuilder(name, logstore, inputType, logType string) *LogtailConfigBuilder {
+	return &LogtailConfigBuilder{
+		config: &LogtailConfig{
+			Name:      name,
+			InputType: inputType,
+			InputDetail: LogtailInput{
+				LogType:      logType,
+				LocalStorage: true,
+				TopicFormat:  "none",
+			},
+			OutputType: LogtailOutputTypeLogService,
+			OutputDetail: LogtailOutput{
+				LogstoreName: logstore,
+			},
+		},
+	}
+}
+
+// NewRegexLogtailConfig collects files whose lines are parsed by regex, one key per capture group
+func NewRegexLogtailConfig(name, logstore, regex string, keys ...string) *LogtailConfigBuilder {
+	b := newLogtailConfigBuilder(name, logstore, LogtailInputTypeFile, LogtailLogTypeRegex)
+	b.config.InputDetail.Regex = regex
+	b.config.InputDetail.Key = keys
+	return b
+}
+
+// NewDelimiterLogtailConfig collects files whose lines are split by separator, one key per column
+func NewDelimiterLogtailConfig(name, logstore, separator string, keys ...string) *LogtailConfigBuilder {
+	b := newLogtailConfigBuilder(name, logstore, LogtailInputTypeFile, LogtailLogTypeDelimiter)
+	b.config.InputDetail.Separator = separator
+	b.config.InputDetail.Key = keys
+	return b
+}
+
+// NewJSONLogtailConfig collects files with one JSON object per line
+func NewJSONLogtailConfig(name, logstore string) *LogtailConfigBuilder {
+	return newLogtailConfigBuilder(name, logstore, LogtailInputTypeFile, LogtailLogTypeJSON)
+}
+
+// NewApsaraLogtailConfig collects files written in the Apsara log format
+func NewApsaraLogtailConfig(name, logstore string) *LogtailConfigBuilder {
+	return newLogtailConfigBuilder(name, logstore, LogtailInputTypeFile, LogtailLogTypeApsara)
+}
+
+// NewDockerStdoutLogtailConfig collects the stdout and stderr of the containers
+func NewDockerStdoutLogtailConfig(name, logstore string) *LogtailConfigBuilder {
+	return NewPluginLogtailConfig(name, logstore, dockerStdoutPlugin())
+}
+
+func dockerStdoutPlugin() LogtailPluginItem {
+	return LogtailPluginItem{
+		Type: dockerStdoutPluginType,
+		Detail: map[string]interface{}{
+			"Stdout": true,
+			"Stderr": true,
+		},
+	}
+}
+
+// NewPluginLogtailConfig collects logs through a plugin pipeline
+func NewPluginLogtailConfig(name, logstore string, inputs ...LogtailPluginItem) *LogtailConfigBuilder {
+	b := newLogtailConfigBuilder(name, logstore, LogtailInputTypePlugin, "")
+	b.config.InputDetail.LocalStorage = false
+	b.config.InputDetail.TopicFormat = ""
+	b.config.InputDetail.Plugin = &LogtailPlugin{Inputs: inputs}
+	return b
+}
+
+// WithFilePath sets the directory and the file name pattern of the collected files
+func (b *LogtailConfigBuilder) WithFilePath(logPath, filePattern string) *LogtailConfigBuilder {
+	b.config.InputDetail.LogPath = logPath
+	b.config.InputDetail.FilePattern = filePattern
+	return b
+}
+
+// WithMaxDepth sets how many levels of sub directories of the log path are monitored
+func (b *LogtailConfigBuilder) WithMaxDepth(maxDepth int) *LogtailConfigBuilder {
+	b.config.InputDetail.MaxDepth = maxDepth
+	return b
+}
+
+// WithTimeKey uses the value of key, parsed with the strftime format, as the log time
+func (b *LogtailConfigBuilder) WithTimeKey(key, format string) *LogtailConfigBuilder {
+	b.config.InputDetail.TimeKey = key
+	b.config.InputDetail.TimeFormat = format
+	return b
+}
+
+// WithLogBeginRegex sets the regex matching the first line of a multi-line log
+func (b *LogtailConfigBuilder) WithLogBeginRegex(regex string) *LogtailConfigBuilder {
+	b.config.InputDetail.LogBeginRegex = regex
+	if b.config.InputType == LogtailInputTypePlugin {
+		b.pluginInputDetail()["BeginLineRegex"] = regex
+	}
+	return b
+}
+
+// WithQuote sets the quote character of delimiter logs
+func (b *LogtailConfigBuilder) WithQuote(quote string) *LogtailConfigBuilder {
+	b.config.InputDetail.Quote = quote
+	return b
+}
+
+// WithFilter only keeps the logs whose key matches regex
+func (b *LogtailConfigBuilder) WithFilter(key, regex string) *LogtailConfigBuilder {
+	b.config.InputDetail.FilterKey = append(b.config.InputDetail.FilterKey, key)
+	b.config.InputDetail.FilterRegex = append(b.config.InputDetail.FilterRegex, regex)
+	return b
+}
+
+// WithTopicFormat sets how the topic is extracted from the file path, "none" by default
+func (b *LogtailConfigBuilder) WithTopicFormat(topicFormat string) *LogtailConfigBuilder {
+	b.config.InputDetail.TopicFormat = topicFormat
+	return b
+}
+
+// WithDiscardUnmatch drops the lines not matching the regex instead of uploading them raw
+func (b *LogtailConfigBuilder) WithDiscardUnmatch(discard bool) *LogtailConfigBuilder {
+	b.config.InputDetail.DiscardUnmatch = discard
+	return b
+}
+
+// WithSample sets the log sample shown in the console
+func (b *LogtailConfigBuilder) WithSample(sample string) *LogtailConfigBuilder {
+	b.config.Sample = sample
+	return b
+}
+
+// WithDockerIncludeLabel only collects the containers having the label
+func (b *LogtailConfigBuilder) WithDockerIncludeLabel(key, value string) *LogtailConfigBuilder {
+	b.setDockerFilter("IncludeLabel", &b.config.InputDetail.DockerIncludeLabel, key, value)
+	return b
+}
+
+// WithDockerExcludeLabel skips the containers having the label
+func (b *LogtailConfigBuilder) WithDockerExcludeLabel(key, value string) *LogtailConfigBuilder {
+	b.setDockerFilter("ExcludeLabel", &b.config.InputDetail.DockerExcludeLabel, key, value)
+	return b
+}
+
+// WithDockerIncludeEnv only collects the containers having the environment variable
+func (b *LogtailConfigBuilder) WithDockerIncludeEnv(key, value string) *LogtailConfigBuilder {
+	b.setDockerFilter("IncludeEnv", &b.config.InputDetail.DockerIncludeEnv, key, value)
+	return b
+}
+
+// WithDockerExcludeEnv skips the containers having the environment variable
+func (b *LogtailConfigBuilder) WithDockerExcludeEnv(key, value string) *LogtailConfigBuilder {
+	b.setDockerFilter("ExcludeEnv", &b.config.InputDetail.DockerExcludeEnv, key, value)
+	return b
+}
+
+// WithProcessor appends a processor to the plugin pipeline, e.g. processor_regex
+// or processor_json. File inputs switch to the plugin pipeline for processing.
+func (b *LogtailConfigBuilder) WithProcessor(processorType string, detail map[string]interface{}) *LogtailConfigBuilder {
+	if b.config.InputDetail.Plugin == nil {
+		b.config.InputDetail.Plugin = &LogtailPlugin{}
+	}
+	b.config.InputDetail.Plugin.Processors = append(b.config.InputDetail.Plugin.Processors, LogtailPluginItem{
+		Type:   processorType,
+		Detail: detail,
+	})
+	return b
+}
+
+// Build validates and returns the config
+func (b *LogtailConfigBuilder) Build() (*LogtailConfig, error) {
+	if err := b.config.Validate(); err != nil {
+		return nil, err
+	}
+	return b.config, nil
+}
+
+// setDockerFilter sets the container filter on the docker stdout plugin, or on
+// the file input which then collects the files inside the matching containers
+func (b *LogtailConfigBuilder) setDockerFilter(name string, field *map[string]string, key, value string) {
+	if b.config.InputType == LogtailInputTypePlugin {
+		detail := b.pluginInputDetail()
+		filter, _ := detail[name].(map[string]interface{})
+		if filter == nil {
+			filter = make(map[string]interface{})
+			detail[name] = filter
+		}
+		filter[key] = value
+		return
+	}
+
+	b.config.InputDetail.DockerFile = true
+	if *field == nil {
+		*field = make(map[string]string)
+	}
+	(*field)[key] = value
+}
+
+// pluginInputDetail returns the detail of the first plugin input. The docker
+// stdout input, which the container options apply to, is added when the
+// pipeline has no input.
+func (b *LogtailConfigBuilder) pluginInputDetail() map[string]interface{} {
+	plugin := b.config.InputDetail.Plugin
+	if plugin == nil {
+		plugin = &LogtailPlugin{}
+		b.config.InputDetail.Plugin = plugin
+	}
+	if len(plugin.Inputs) == 0 {
+		plugin.Inputs = append(plugin.Inputs, dockerStdoutPlugin())
+	}
+	if plugin.Inputs[0].Detail == nil {
+		plugin.Inputs[0].Detail = make(map[string]interface{})
+	}
+	return plugin.Inputs[0].Detail
+}
+
+// Validate checks the config against the rules enforced by the server. The
+// regexes are not checked, Logtail uses the PCRE syntax which regexp does not
+// fully support. Only the file and plugin input types and the log types of
+// the builders are known, so that CreateConfig and UpdateConfig do not
+// validate the configs, e.g. the ones read back with GetConfig.
+func (config *LogtailConfig) Validate() error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("sls: invalid logtail config %q: %s", config.Name, fmt.Sprintf(format, args...))
+	}
+
+	if config.Name == "" {
+		return invalid("name is empty")
+	}
+	if config.OutputDetail.LogstoreName == "" {
+		return invalid("logstore is empty")
+	}
+
+	input := &config.InputDetail
+	switch config.InputType {
+	case LogtailInputTypePlugin:
+		if input.Plugin == nil || len(input.Plugin.Inputs) == 0 {
+			return invalid("plugin input requires at least one plugin")
+		}
+	case LogtailInputTypeFile:
+		if !path.IsAbs(input.LogPath) {
+			return invalid("log path %q is not absolute", input.LogPath)
+		}
+		if input.FilePattern == "" {
+			return invalid("file pattern is empty")
+		}
+		if input.MaxDepth < 0 || input.MaxDepth > 1000 {
+			return invalid("max depth %d is out of [0, 1000]", input.MaxDepth)
+		}
+	default:
+		return invalid("unknown input type %q", config.InputType)
+	}
+
+	if input.Plugin != nil {
+		for _, items := range [][]LogtailPluginItem{input.Plugin.Inputs, input.Plugin.Processors, input.Plugin.Flushers} {
+			for _, item := range items {
+				if item.Type == "" {
+					return invalid("plugin type is empty")
+				}
+			}
+		}
+	}
+
+	if config.InputType == LogtailInputTypeFile {
+		switch input.LogType {
+		case LogtailLogTypeRegex:
+			if input.Regex == "" {
+				return invalid("regex is empty")
+			}
+		case LogtailLogTypeDelimiter:
+			if input.Separator == "" {
+				return invalid("separator is empty")
+			}
+			if len(input.Key) == 0 {
+				return invalid("delimiter log requires keys")
+			}
+			if len(input.Quote) > 1 {
+				return invalid("quote %q is not a single character", input.Quote)
+			}
+		case LogtailLogTypeJSON, LogtailLogTypeApsara:
+		default:
+			return invalid("unknown log type %q", input.LogType)
+		}
+	}
+
+	if input.TimeKey != "" {
+		if input.TimeFormat == "" {
+			return invalid("time key %q requires a time format", input.TimeKey)
+		}
+		if len(input.Key) > 0 && !containsString(input.Key, input.TimeKey) {
+			return invalid("time key %q is not one of the keys", input.TimeKey)
+		}
+	}
+	if len(input.FilterKey) != len(input.FilterRegex) {
+		return invalid("%d filter keys but %d filter regexes", len(input.FilterKey), len(input.FilterRegex))
+	}
+
+	hasDockerFilter := len(input.DockerIncludeLabel) > 0 || len(input.DockerExcludeLabel) > 0 ||
+		len(input.DockerIncludeEnv) > 0 || len(input.DockerExcludeEnv) > 0
+	if hasDockerFilter && !input.DockerFile {
+		return invalid("docker label and env filters require docker file collection")
+	}
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
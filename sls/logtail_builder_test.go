@@ -0,0 +1,98 @@
+package sls
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLogtailConfigBuilderRoundTrip(t *testing.T) {
+	builders := []*LogtailConfigBuilder{
+		NewRegexLogtailConfig("regex", TestLogstoreName, `(\S+) (\S+) \[([^\]]+)\]`, "ip", "method", "time").
+			WithFilePath("/var/log/nginx", "access.log").
+			WithTimeKey("time", "%d/%b/%Y:%H:%M:%S").
+			WithFilter("method", "GET|POST"),
+		NewDelimiterLogtailConfig("delimiter", TestLogstoreName, ",", "time", "level", "message").
+			WithFilePath("/var/log/app", "*.csv").
+			WithQuote(`"`).
+			WithTimeKey("time", "%Y-%m-%d %H:%M:%S"),
+		NewJSONLogtailConfig("json", TestLogstoreName).
+			WithFilePath("/var/log/app", "*.json").
+			WithMaxDepth(3).
+			WithDockerIncludeLabel("app", "web"),
+		NewApsaraLogtailConfig("apsara", TestLogstoreName).
+			WithFilePath("/apsara/log", "*.LOG"),
+		NewDockerStdoutLogtailConfig("stdout", TestLogstoreName).
+			WithDockerIncludeLabel("app", "web").
+			WithDockerExcludeEnv("DEBUG", "true").
+			WithLogBeginRegex(`\d+-\d+-\d+.*`).
+			WithProcessor("processor_json", map[string]interface{}{"SourceKey": "content"}),
+	}
+
+	transport := &captureTransport{}
+	p := newCaptureProject(transport)
+	for _, b := range builders {
+		config, err := b.Build()
+		if err != nil {
+			t.Fatalf("Failed to build logtail config: %v", err)
+		}
+		if err := p.CreateConfig(config); err != nil {
+			t.Fatalf("Failed to create logtail config: %v", err)
+		}
+
+		transport.response = string(transport.payload)
+		got, err := p.GetConfig(config.Name)
+		if err != nil {
+			t.Fatalf("Failed to get logtail config: %v", err)
+		}
+		if err := got.Validate(); err != nil {
+			t.Errorf("Config %s does not validate after round trip: %v", config.Name, err)
+		}
+		if !reflect.DeepEqual(got, config) {
+			t.Errorf("Config %s does not round trip: %+v", config.Name, got)
+		}
+	}
+}
+
+func TestLogtailConfigValidate(t *testing.T) {
+	invalid := []*LogtailConfigBuilder{
+		NewRegexLogtailConfig("regex", TestLogstoreName, "", "ip").WithFilePath("/var/log", "*.log"),
+		NewDelimiterLogtailConfig("separator", TestLogstoreName, "", "a").WithFilePath("/var/log", "*.log"),
+		NewDelimiterLogtailConfig("time", TestLogstoreName, ",", "a").WithFilePath("/var/log", "*.log").WithTimeKey("b", "%s"),
+		NewJSONLogtailConfig("path", TestLogstoreName).WithFilePath("var/log", "*.log"),
+		NewJSONLogtailConfig("pattern", TestLogstoreName).WithFilePath("/var/log", ""),
+		NewJSONLogtailConfig("", TestLogstoreName).WithFilePath("/var/log", "*.log"),
+		NewPluginLogtailConfig("plugin", TestLogstoreName),
+	}
+	for _, b := range invalid {
+		if config, err := b.Build(); err == nil {
+			t.Errorf("Expected config %q to be invalid", config.Name)
+		}
+	}
+
+	// Logtail regexes are PCRE, lookarounds and backreferences are valid
+	pcre := NewRegexLogtailConfig("pcre", TestLogstoreName, `(?<=\[)(\w+)\] (\w+) \2`, "level", "word").
+		WithFilePath("/var/log", "*.log").
+		WithLogBeginRegex(`(?!\s).*`).
+		WithFilter("level", `^(?!DEBUG)`)
+	if _, err := pcre.Build(); err != nil {
+		t.Errorf("Expected PCRE config to be valid: %v", err)
+	}
+}
+
+func TestLogtailConfigPluginWithoutInput(t *testing.T) {
+	config, err := NewPluginLogtailConfig("plugin", TestLogstoreName).
+		WithDockerIncludeLabel("app", "web").
+		WithLogBeginRegex(`\d+-\d+-\d+.*`).
+		Build()
+	if err != nil {
+		t.Fatalf("Failed to build logtail config: %v", err)
+	}
+	inputs := config.InputDetail.Plugin.Inputs
+	if len(inputs) != 1 || inputs[0].Type != dockerStdoutPluginType {
+		t.Fatalf("Unexpected plugin inputs %+v", inputs)
+	}
+	label, _ := inputs[0].Detail["IncludeLabel"].(map[string]interface{})
+	if label["app"] != "web" || inputs[0].Detail["BeginLineRegex"] != `\d+-\d+-\d+.*` {
+		t.Errorf("Unexpected plugin input detail %+v", inputs[0].Detail)
+	}
+}
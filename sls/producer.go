@@ -0,0 +1,398 @@
+package sls
+
+import (
+	"errors"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+)
+
+type OverflowPolicy int
+
+const (
+	// OverflowBlock blocks Send until buffered logs have been flushed
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDrop rejects the logs with ErrProducerBufferFull
+	OverflowDrop
+)
+
+const (
+	DefaultProducerMaxBatchSize     = 512 * 1024
+	DefaultProducerMaxBatchCount    = 4096
+	DefaultProducerLingerTime       = 2 * time.Second
+	DefaultProducerMaxBufferSize    = 64 * 1024 * 1024
+	DefaultProducerConcurrency      = 4
+	DefaultProducerRetries          = 10
+	DefaultProducerBaseRetryBackoff = 100 * time.Millisecond
+	DefaultProducerMaxRetryBackoff  = 30 * time.Second
+
+	// SLS rejects a single PutLogs request above these limits
+	maxPutLogsBatchSize  = 5 * 1024 * 1024
+	maxPutLogsBatchCount = 4096
+)
+
+var (
+	ErrProducerClosed     = errors.New("sls: producer is closed")
+	ErrProducerBufferFull = errors.New("sls: producer buffer is full")
+	ErrLogTooLarge        = errors.New("sls: logs are too large to be sent")
+)
+
+type ProducerConfig struct {
+	MaxBatchSize     int           // Flush a batch once its estimated size reaches this many bytes
+	MaxBatchCount    int           // Flush a batch once it holds this many logs
+	LingerTime       time.Duration // Flush a batch once it is this old
+	MaxBufferSize    int64         // Upper bound of bytes held by unsent batches
+	OverflowPolicy   OverflowPolicy
+	Concurrency      int // Number of batches sent in parallel
+	Retries          int // Number of retries after the first failed attempt, DefaultProducerRetries when 0 and none when negative
+	BaseRetryBackoff time.Duration
+	MaxRetryBackoff  time.Duration
+	Compressor       Compressor // LZ4Compressor by default
+
+	// OnFailure is called when a batch is given up after all retries
+	OnFailure func(failure *ProducerFailure)
+}
+
+type ProducerFailure struct {
+	Project  string
+	Logstore string
+	Topic    string
+	Source   string
+	HashKey  string
+	Logs     []*Log
+	Err      error
+}
+
+type batchKey struct {
+	project  string
+	logstore string
+	topic    string
+	source   string
+	hashKey  string
+}
+
+type producerBatch struct {
+	key     batchKey
+	logs    []*Log
+	size    int
+	created time.Time
+}
+
+// Producer buffers logs per project/logstore/topic/source and sends them
+// asynchronously in compressed batches
+type Producer struct {
+	client *Client
+	config ProducerConfig
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	batches  map[batchKey]*producerBatch
+	buffered int64
+	closed   bool
+
+	ready      chan *producerBatch
+	dispatches sync.WaitGroup
+	workers    sync.WaitGroup
+	stop       chan struct{}
+	lingerDone chan struct{}
+	closeOnce  sync.Once
+}
+
+// NewProducer creates a producer sending through the client and starts its
+// background workers. Close must be called to flush the buffered logs.
+func NewProducer(client *Client, config *ProducerConfig) *Producer {
+	p := &Producer{
+		client:     client,
+		batches:    make(map[batchKey]*producerBatch),
+		stop:       make(chan struct{}),
+		lingerDone: make(chan struct{}),
+	}
+	if config != nil {
+		p.config = *config
+	}
+	p.config.setDefaults()
+	p.cond = sync.NewCond(&p.mu)
+	p.ready = make(chan *producerBatch, p.config.Concurrency)
+
+	for i := 0; i < p.config.Concurrency; i++ {
+		p.workers.Add(1)
+		go p.sendLoop()
+	}
+	go p.lingerLoop()
+	return p
+}
+
+func (config *ProducerConfig) setDefaults() {
+	if config.MaxBatchSize <= 0 {
+		config.MaxBatchSize = DefaultProducerMaxBatchSize
+	}
+	if config.MaxBatchSize > maxPutLogsBatchSize {
+		config.MaxBatchSize = maxPutLogsBatchSize
+	}
+	if config.MaxBatchCount <= 0 || config.MaxBatchCount > maxPutLogsBatchCount {
+		config.MaxBatchCount = DefaultProducerMaxBatchCount
+	}
+	if config.LingerTime <= 0 {
+		config.LingerTime = DefaultProducerLingerTime
+	}
+	if config.MaxBufferSize <= 0 {
+		config.MaxBufferSize = DefaultProducerMaxBufferSize
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = DefaultProducerConcurrency
+	}
+	if config.Retries < 0 {
+		config.Retries = 0
+	} else if config.Retries == 0 {
+		config.Retries = DefaultProducerRetries
+	}
+	if config.BaseRetryBackoff <= 0 {
+		config.BaseRetryBackoff = DefaultProducerBaseRetryBackoff
+	}
+	if config.MaxRetryBackoff <= 0 {
+		config.MaxRetryBackoff = DefaultProducerMaxRetryBackoff
+	}
+	if config.Compressor == nil {
+		config.Compressor = LZ4Compressor
+	}
+}
+
+// Send buffers the logs to be sent to the logstore through the load balanced shard
+func (p *Producer) Send(project, logstore, topic, source string, logs ...*Log) error {
+	return p.SendWithHashKey(project, logstore, topic, source, "", logs...)
+}
+
+// SendWithHashKey buffers the logs to be sent to the shard whose range
+// contains hashKey. An empty hashKey falls back to load balancing.
+func (p *Producer) SendWithHashKey(project, logstore, topic, source, hashKey string, logs ...*Log) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	size := 0
+	for _, log := range logs {
+		n := logSize(log)
+		if n > maxPutLogsBatchSize {
+			return ErrLogTooLarge
+		}
+		size += n
+	}
+	if int64(size) > p.config.MaxBufferSize {
+		return ErrLogTooLarge
+	}
+
+	key := batchKey{
+		project:  project,
+		logstore: logstore,
+		topic:    topic,
+		source:   source,
+		hashKey:  hashKey,
+	}
+
+	p.mu.Lock()
+	for !p.closed && p.buffered+int64(size) > p.config.MaxBufferSize {
+		if p.config.OverflowPolicy == OverflowDrop {
+			p.mu.Unlock()
+			return ErrProducerBufferFull
+		}
+		p.cond.Wait()
+	}
+	if p.closed {
+		p.mu.Unlock()
+		return ErrProducerClosed
+	}
+
+	p.buffered += int64(size)
+	var full []*producerBatch
+	for _, log := range logs {
+		n := logSize(log)
+		batch := p.batches[key]
+		if batch != nil && batch.size+n > p.config.MaxBatchSize {
+			// Flush first so that the batch stays under the PutLogs limit
+			delete(p.batches, key)
+			full = append(full, batch)
+			batch = nil
+		}
+		if batch == nil {
+			batch = &producerBatch{key: key, created: time.Now()}
+			p.batches[key] = batch
+		}
+		batch.logs = append(batch.logs, log)
+		batch.size += n
+		if batch.size >= p.config.MaxBatchSize || len(batch.logs) >= p.config.MaxBatchCount {
+			delete(p.batches, key)
+			full = append(full, batch)
+		}
+	}
+	p.dispatches.Add(len(full))
+	p.mu.Unlock()
+
+	for _, batch := range full {
+		p.dispatch(batch)
+	}
+	return nil
+}
+
+// Close stops accepting logs, flushes the buffered batches and waits until
+// every in-flight batch has been sent or given up. The failed batches get a
+// last attempt without waiting for their retry backoff.
+func (p *Producer) Close() error {
+	p.closeOnce.Do(func() {
+		close(p.stop)
+		<-p.lingerDone
+
+		p.mu.Lock()
+		p.closed = true
+		var pending []*producerBatch
+		for key, batch := range p.batches {
+			delete(p.batches, key)
+			pending = append(pending, batch)
+		}
+		p.dispatches.Add(len(pending))
+		p.cond.Broadcast()
+		p.mu.Unlock()
+
+		for _, batch := range pending {
+			p.dispatch(batch)
+		}
+		p.dispatches.Wait()
+		close(p.ready)
+		p.workers.Wait()
+	})
+	return nil
+}
+
+func (p *Producer) dispatch(batch *producerBatch) {
+	p.ready <- batch
+	p.dispatches.Done()
+}
+
+func (p *Producer) lingerLoop() {
+	defer close(p.lingerDone)
+
+	interval := p.config.LingerTime / 2
+	if interval < 10*time.Millisecond {
+		interval = 10 * time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case now := <-ticker.C:
+			p.mu.Lock()
+			var expired []*producerBatch
+			for key, batch := range p.batches {
+				if now.Sub(batch.created) >= p.config.LingerTime {
+					delete(p.batches, key)
+					expired = append(expired, batch)
+				}
+			}
+			p.dispatches.Add(len(expired))
+			p.mu.Unlock()
+
+			for _, batch := range expired {
+				p.dispatch(batch)
+			}
+		}
+	}
+}
+
+func (p *Producer) sendLoop() {
+	defer p.workers.Done()
+	for batch := range p.ready {
+		p.send(batch)
+
+		p.mu.Lock()
+		p.buffered -= int64(batch.size)
+		p.cond.Broadcast()
+		p.mu.Unlock()
+	}
+}
+
+func (p *Producer) send(batch *producerBatch) {
+	logGroup := &LogGroup{Logs: batch.logs}
+	if batch.key.topic != "" {
+		logGroup.Topic = proto.String(batch.key.topic)
+	}
+	if batch.key.source != "" {
+		logGroup.Source = proto.String(batch.key.source)
+	}
+
+	var err error
+	backoff := p.config.BaseRetryBackoff
+	closing := false
+	for attempt := 0; ; attempt++ {
+		err = p.client.putLogGroup(batch.key.project, batch.key.logstore, batch.key.hashKey, logGroup, p.config.Compressor)
+		if err == nil {
+			return
+		}
+		if closing || attempt >= p.config.Retries || !isRetryablePutLogsError(err) {
+			break
+		}
+		// Close interrupts the backoff, the batch then gets a last attempt
+		closing = !p.sleep(jitter(backoff))
+		backoff *= 2
+		if backoff > p.config.MaxRetryBackoff {
+			backoff = p.config.MaxRetryBackoff
+		}
+	}
+
+	if p.config.OnFailure != nil {
+		p.config.OnFailure(&ProducerFailure{
+			Project:  batch.key.project,
+			Logstore: batch.key.logstore,
+			Topic:    batch.key.topic,
+			Source:   batch.key.source,
+			HashKey:  batch.key.hashKey,
+			Logs:     batch.logs,
+			Err:      err,
+		})
+	}
+}
+
+// sleep waits for d and returns false when the producer is closed first
+func (p *Producer) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-p.stop:
+		return false
+	}
+}
+
+// jitter returns a random duration between d/2 and d
+func jitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return d
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func isRetryablePutLogsError(err error) bool {
+	if e, ok := err.(*Error); ok {
+		switch e.Code {
+		case "WriteQuotaExceed", "ShardWriteQuotaExceed", "ServerBusy", "RequestTimeout", "InternalServerError":
+			return true
+		}
+		return e.StatusCode >= 500
+	}
+	_, ok := err.(net.Error)
+	return ok
+}
+
+// logSize estimates the serialized size of a log
+func logSize(log *Log) int {
+	size := 8
+	for _, content := range log.Contents {
+		size += len(content.GetKey()) + len(content.GetValue()) + 6
+	}
+	return size
+}
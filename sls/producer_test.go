@@ -0,0 +1,363 @@
+package sls
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+)
+
+type recordedPutLogs struct {
+	path         string
+	hashKey      string
+	compressType string
+	logGroup     *LogGroup
+}
+
+type putLogsRecorder struct {
+	t        *testing.T
+	mu       sync.Mutex
+	requests []*recordedPutLogs
+	failures int
+}
+
+func (r *putLogsRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failures > 0 {
+		r.failures--
+		return &http.Response{
+			StatusCode: 503,
+			Status:     "503 Service Unavailable",
+			Body:       ioutil.NopCloser(bytes.NewBufferString(`{"errorCode":"ServerBusy","errorMessage":"busy"}`)),
+			Request:    req,
+		}, nil
+	}
+
+	rawSize, _ := strconv.Atoi(req.Header.Get("x-log-bodyrawsize"))
+	data := body
+	if req.Header.Get("x-log-compresstype") == CompressTypeLZ4 {
+		data, err = lz4DecompressBlock(body, rawSize)
+		if err != nil {
+			r.t.Errorf("invalid lz4 payload: %v", err)
+		}
+	}
+	logGroup := &LogGroup{}
+	if err := proto.Unmarshal(data, logGroup); err != nil {
+		r.t.Errorf("invalid log group: %v", err)
+	}
+
+	r.requests = append(r.requests, &recordedPutLogs{
+		path:         req.URL.Path,
+		hashKey:      req.URL.Query().Get("key"),
+		compressType: req.Header.Get("x-log-compresstype"),
+		logGroup:     logGroup,
+	})
+	return &http.Response{
+		StatusCode: 200,
+		Status:     "200 OK",
+		Body:       ioutil.NopCloser(bytes.NewReader(nil)),
+		Request:    req,
+	}, nil
+}
+
+func newTestLog(i int) *Log {
+	return &Log{
+		Time: proto.Uint32(uint32(time.Now().Unix())),
+		Contents: []*Log_Content{
+			{Key: proto.String("message"), Value: proto.String(fmt.Sprintf("log message number %d", i))},
+		},
+	}
+}
+
+func TestProducerBatchesAndRoutes(t *testing.T) {
+	recorder := &putLogsRecorder{t: t}
+	client := NewClientWithEndpoint("log.example.com", Region, false, "id", "secret")
+	client.SetTransport(recorder)
+
+	producer := NewProducer(client, &ProducerConfig{
+		MaxBatchCount: 10,
+		LingerTime:    time.Hour,
+	})
+	for i := 0; i < 25; i++ {
+		if err := producer.Send("project", "logstore", "topic", "source", newTestLog(i)); err != nil {
+			t.Fatalf("Failed to send log: %v", err)
+		}
+	}
+	if err := producer.SendWithHashKey("project", "logstore", "", "", "0123456789abcdef0123456789abcdef", newTestLog(0)); err != nil {
+		t.Fatalf("Failed to send log with hash key: %v", err)
+	}
+	producer.Close()
+
+	if err := producer.Send("project", "logstore", "topic", "source", newTestLog(0)); err != ErrProducerClosed {
+		t.Errorf("Expected ErrProducerClosed after close, got %v", err)
+	}
+
+	total := 0
+	routed := 0
+	for _, req := range recorder.requests {
+		if req.compressType != CompressTypeLZ4 {
+			t.Errorf("Unexpected compress type %q", req.compressType)
+		}
+		if req.hashKey != "" {
+			routed++
+			if req.path != "/logstores/logstore/shards/route" {
+				t.Errorf("Unexpected path for hash key routing: %s", req.path)
+			}
+			continue
+		}
+		if req.path != "/logstores/logstore/shards/lb" {
+			t.Errorf("Unexpected path: %s", req.path)
+		}
+		if req.logGroup.GetTopic() != "topic" || req.logGroup.GetSource() != "source" {
+			t.Errorf("Unexpected topic/source: %s/%s", req.logGroup.GetTopic(), req.logGroup.GetSource())
+		}
+		if len(req.logGroup.Logs) > 10 {
+			t.Errorf("Batch exceeds max count: %d", len(req.logGroup.Logs))
+		}
+		total += len(req.logGroup.Logs)
+	}
+	if total != 25 || routed != 1 {
+		t.Errorf("Expected 25 balanced logs and 1 routed batch, got %d and %d", total, routed)
+	}
+}
+
+func TestProducerLingerAndRetry(t *testing.T) {
+	recorder := &putLogsRecorder{t: t, failures: 2}
+	client := NewClientWithEndpoint("log.example.com", Region, false, "id", "secret")
+	client.SetTransport(recorder)
+
+	producer := NewProducer(client, &ProducerConfig{
+		LingerTime:       20 * time.Millisecond,
+		BaseRetryBackoff: time.Millisecond,
+	})
+	defer producer.Close()
+
+	if err := producer.Send("project", "logstore", "", "", newTestLog(1)); err != nil {
+		t.Fatalf("Failed to send log: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		recorder.mu.Lock()
+		n := len(recorder.requests)
+		recorder.mu.Unlock()
+		if n == 1 {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("Batch was not flushed after linger time")
+}
+
+func TestProducerDropPolicy(t *testing.T) {
+	client := NewClientWithEndpoint("log.example.com", Region, false, "id", "secret")
+	client.SetTransport(&putLogsRecorder{t: t})
+
+	producer := NewProducer(client, &ProducerConfig{
+		LingerTime:     time.Hour,
+		MaxBufferSize:  int64(logSize(newTestLog(1)) * 2),
+		OverflowPolicy: OverflowDrop,
+	})
+	defer producer.Close()
+
+	for i := 0; i < 2; i++ {
+		if err := producer.Send("project", "logstore", "", "", newTestLog(i)); err != nil {
+			t.Fatalf("Failed to send log: %v", err)
+		}
+	}
+	if err := producer.Send("project", "logstore", "", "", newTestLog(3)); err != ErrProducerBufferFull {
+		t.Errorf("Expected ErrProducerBufferFull, got %v", err)
+	}
+}
+
+func TestProducerFlushesBeforeBatchSizeLimit(t *testing.T) {
+	recorder := &putLogsRecorder{t: t}
+	client := NewClientWithEndpoint("log.example.com", Region, false, "id", "secret")
+	client.SetTransport(recorder)
+
+	large := &Log{
+		Time: proto.Uint32(uint32(time.Now().Unix())),
+		Contents: []*Log_Content{
+			{Key: proto.String("message"), Value: proto.String(string(bytes.Repeat([]byte("x"), 600)))},
+		},
+	}
+	maxBatchSize := logSize(newTestLog(0))*3 + logSize(large) - 1
+	producer := NewProducer(client, &ProducerConfig{
+		MaxBatchSize: maxBatchSize,
+		LingerTime:   time.Hour,
+	})
+	for i := 0; i < 3; i++ {
+		if err := producer.Send("project", "logstore", "", "", newTestLog(i)); err != nil {
+			t.Fatalf("Failed to send log: %v", err)
+		}
+	}
+	if err := producer.Send("project", "logstore", "", "", large); err != nil {
+		t.Fatalf("Failed to send log: %v", err)
+	}
+	producer.Close()
+
+	if len(recorder.requests) != 2 {
+		t.Fatalf("Expected 2 batches, got %d", len(recorder.requests))
+	}
+	for _, req := range recorder.requests {
+		size := 0
+		for _, log := range req.logGroup.Logs {
+			size += logSize(log)
+		}
+		if size > maxBatchSize {
+			t.Errorf("Batch of %d bytes exceeds max size %d", size, maxBatchSize)
+		}
+	}
+}
+
+func TestProducerCloseInterruptsRetryBackoff(t *testing.T) {
+	recorder := &putLogsRecorder{t: t, failures: 100}
+	client := NewClientWithEndpoint("log.example.com", Region, false, "id", "secret")
+	client.SetTransport(recorder)
+
+	var failure *ProducerFailure
+	producer := NewProducer(client, &ProducerConfig{
+		LingerTime:       time.Hour,
+		BaseRetryBackoff: time.Hour,
+		MaxRetryBackoff:  time.Hour,
+		OnFailure: func(f *ProducerFailure) {
+			failure = f
+		},
+	})
+	if err := producer.Send("project", "logstore", "", "", newTestLog(1)); err != nil {
+		t.Fatalf("Failed to send log: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		producer.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Close is blocked by the retry backoff")
+	}
+	if failure == nil || len(failure.Logs) != 1 {
+		t.Errorf("Expected the batch to be reported as failed, got %+v", failure)
+	}
+	if recorder.failures != 98 {
+		t.Errorf("Expected a last attempt after close, got %d attempts", 100-recorder.failures)
+	}
+}
+
+func TestLZ4CompressBlock(t *testing.T) {
+	inputs := [][]byte{
+		nil,
+		[]byte("short"),
+		bytes.Repeat([]byte("abcdefgh"), 1000),
+		[]byte(fmt.Sprintf("%v", make([]int, 5000))),
+	}
+	random := make([]byte, 70000)
+	for i := range random {
+		random[i] = byte(i * 7919 >> 3)
+	}
+	inputs = append(inputs, random)
+
+	for _, input := range inputs {
+		compressed := lz4CompressBlock(input)
+		output, err := lz4DecompressBlock(compressed, len(input))
+		if err != nil {
+			t.Fatalf("Failed to decompress: %v", err)
+		}
+		if !bytes.Equal(input, output) {
+			t.Errorf("Round trip mismatch for input of %d bytes", len(input))
+		}
+	}
+}
+
+func TestLZ4CompressBlockExpected(t *testing.T) {
+	// Blocks checked with the reference LZ4 decoder
+	cases := []struct {
+		input    []byte
+		expected string
+	}{
+		{bytes.Repeat([]byte("abcdefgh"), 4), "8f6162636465666768080000506465666768"},
+		{append([]byte("0123456789abcdefghij"), bytes.Repeat([]byte("x"), 300)...),
+			"ff06303132333435363738396162636465666768696a780100ff14507878787878"},
+	}
+	for _, c := range cases {
+		if compressed := hex.EncodeToString(lz4CompressBlock(c.input)); compressed != c.expected {
+			t.Errorf("Unexpected block %s, expected %s", compressed, c.expected)
+		}
+	}
+}
+
+func lz4DecompressBlock(src []byte, rawSize int) ([]byte, error) {
+	dst := make([]byte, 0, rawSize)
+	readLength := func(i int, n int) (int, int, error) {
+		for {
+			if i >= len(src) {
+				return 0, 0, errors.New("truncated length")
+			}
+			b := src[i]
+			i++
+			n += int(b)
+			if b != 255 {
+				return i, n, nil
+			}
+		}
+	}
+
+	var err error
+	for i := 0; i < len(src); {
+		token := src[i]
+		i++
+		litLen := int(token >> 4)
+		if litLen == 15 {
+			if i, litLen, err = readLength(i, litLen); err != nil {
+				return nil, err
+			}
+		}
+		if i+litLen > len(src) {
+			return nil, errors.New("truncated literals")
+		}
+		dst = append(dst, src[i:i+litLen]...)
+		i += litLen
+		if i == len(src) {
+			break
+		}
+		if i+2 > len(src) {
+			return nil, errors.New("truncated offset")
+		}
+		offset := int(src[i]) | int(src[i+1])<<8
+		i += 2
+		matchLen := int(token & 15)
+		if matchLen == 15 {
+			if i, matchLen, err = readLength(i, matchLen); err != nil {
+				return nil, err
+			}
+		}
+		matchLen += lz4MinMatch
+		if offset == 0 || offset > len(dst) {
+			return nil, errors.New("invalid offset")
+		}
+		start := len(dst) - offset
+		for j := 0; j < matchLen; j++ {
+			dst = append(dst, dst[start+j])
+		}
+	}
+	if len(dst) != rawSize {
+		return nil, fmt.Errorf("decompressed %d bytes, expected %d", len(dst), rawSize)
+	}
+	return dst, nil
+}
@@ -11,12 +11,17 @@ import (
 	"net/http/httputil"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/denverdino/aliyungo/common"
 	"github.com/denverdino/aliyungo/util"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
 )
 
 type request struct {
+	scheme      string
 	endpoint    string
 	method      string
 	path        string
@@ -36,7 +41,7 @@ func (req *request) url() string {
 	}
 
 	u := url.URL{
-		Scheme:   "http",
+		Scheme:   req.scheme,
 		Host:     req.endpoint,
 		Path:     req.path,
 		RawQuery: params.Encode(),
@@ -55,6 +60,12 @@ func (client *Client) doRequest(req *request) (*http.Response, error) {
 	if req.endpoint == "" {
 		req.endpoint = client.endpoint
 	}
+	if req.scheme == "" {
+		req.scheme = client.scheme
+	}
+	if req.scheme == "" {
+		req.scheme = SLSDefaultScheme
+	}
 
 	contentLength := "0"
 
@@ -64,7 +75,9 @@ func (client *Client) doRequest(req *request) (*http.Response, error) {
 
 	req.headers["Content-Type"] = req.contentType
 	req.headers["Content-Length"] = contentLength
-	req.headers["x-log-bodyrawsize"] = contentLength
+	if _, ok := req.headers["x-log-bodyrawsize"]; !ok {
+		req.headers["x-log-bodyrawsize"] = contentLength
+	}
 	req.headers["Date"] = util.GetGMTime()
 	req.headers["Host"] = req.endpoint
 	req.headers["x-log-apiversion"] = client.version
@@ -82,30 +95,60 @@ func (client *Client) doRequest(req *request) (*http.Response, error) {
 	}
 
 	hreq, err := http.NewRequest(req.method, req.url(), reader)
+	if err != nil {
+		return nil, err
+	}
 
 	for k, v := range req.headers {
 		if v != "" {
 			hreq.Header.Set(k, v)
 		}
 	}
+	hreq.Header.Set("User-Agent", strings.TrimSpace(`AliyunGO/`+common.Version+" "+client.userAgent))
 
-	if err != nil {
-		return nil, err
+	// Set tracer
+	var span opentracing.Span
+	if ok := opentracing.IsGlobalTracerRegistered(); ok && !client.disableTrace {
+		tracer := opentracing.GlobalTracer()
+		var rootCtx opentracing.SpanContext
+
+		if client.span != nil {
+			rootCtx = client.span.Context()
+		}
+
+		span = tracer.StartSpan(
+			"AliyunGO-SLS-"+req.method+" "+req.path,
+			opentracing.ChildOf(rootCtx),
+			opentracing.Tag{Key: string(ext.Component), Value: "AliyunGO"})
+		ext.HTTPMethod.Set(span, req.method)
+		ext.HTTPUrl.Set(span, req.url())
+
+		defer span.Finish()
+		tracer.Inject(
+			span.Context(),
+			opentracing.HTTPHeaders,
+			opentracing.HTTPHeadersCarrier(hreq.Header))
 	}
+
 	if client.debug {
-		reqDump, _ := httputil.DumpRequest(hreq, true)
-		log.Printf("---------------REQUEST---------------\n%s\n\n", string(reqDump))
+		client.logf("---------------REQUEST---------------\n%s\n\n", dumpRequest(hreq, req))
 	}
 	t0 := time.Now()
 	resp, err := client.httpClient.Do(hreq)
 	t1 := time.Now()
 	if err != nil {
+		if span != nil {
+			ext.LogError(span, err)
+		}
 		return nil, err
 	}
+	if span != nil {
+		ext.HTTPStatusCode.Set(span, uint16(resp.StatusCode))
+	}
 	if client.debug {
 		resDump, _ := httputil.DumpResponse(resp, true)
-		log.Printf("---------------RESPONSE---------------\n%s\n\n", string(resDump))
-		log.Printf("Invoke %s %s %d (%v)", req.method, req.url(), resp.StatusCode, t1.Sub(t0))
+		client.logf("---------------RESPONSE---------------\n%s\n\n", string(resDump))
+		client.logf("Invoke %s %s %d (%v)", req.method, req.url(), resp.StatusCode, t1.Sub(t0))
 	}
 
 	if resp.StatusCode != 200 && resp.StatusCode != 204 && resp.StatusCode != 206 {
@@ -114,6 +157,34 @@ func (client *Client) doRequest(req *request) (*http.Response, error) {
 	return resp, nil
 }
 
+// redactedHeaders carry the credentials and are masked in the debug messages
+var redactedHeaders = []string{"Authorization", "x-acs-security-token"}
+
+// dumpRequest dumps the request without the signature and the security token,
+// and without the body unless it is JSON
+func dumpRequest(hreq *http.Request, req *request) string {
+	dump := hreq.Clone(hreq.Context())
+	dump.Body = nil
+	for _, k := range redactedHeaders {
+		if dump.Header.Get(k) != "" {
+			dump.Header.Set(k, "******")
+		}
+	}
+	data, _ := httputil.DumpRequest(dump, false)
+	if strings.HasPrefix(req.contentType, "application/json") {
+		data = append(data, req.payload...)
+	}
+	return string(data)
+}
+
+func (client *Client) logf(format string, v ...interface{}) {
+	if client.logger != nil {
+		client.logger.Printf(format, v...)
+	} else {
+		log.Printf(format, v...)
+	}
+}
+
 func (client *Client) requestWithJsonResponse(req *request, v interface{}) error {
 	resp, err := client.doRequest(req)
 
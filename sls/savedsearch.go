@@ -0,0 +1,88 @@
+package sls
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+type SavedSearch struct {
+	Name        string `json:"savedsearchName,omitempty"`
+	SearchQuery string `json:"searchQuery,omitempty"`
+	Logstore    string `json:"logstore,omitempty"`
+	Topic       string `json:"topic,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
+}
+
+func (proj *Project) CreateSavedSearch(savedSearch *SavedSearch) error {
+	data, err := json.Marshal(savedSearch)
+	if err != nil {
+		return err
+	}
+
+	req := &request{
+		method:      METHOD_POST,
+		path:        "/savedsearches",
+		payload:     data,
+		contentType: "application/json",
+	}
+	return proj.client.requestWithClose(req)
+}
+
+func (proj *Project) GetSavedSearch(name string) (*SavedSearch, error) {
+	req := &request{
+		method: METHOD_GET,
+		path:   "/savedsearches/" + name,
+	}
+
+	savedSearch := &SavedSearch{}
+	if err := proj.client.requestWithJsonResponse(req, savedSearch); err != nil {
+		return nil, err
+	}
+	return savedSearch, nil
+}
+
+func (proj *Project) UpdateSavedSearch(savedSearch *SavedSearch) error {
+	data, err := json.Marshal(savedSearch)
+	if err != nil {
+		return err
+	}
+
+	req := &request{
+		method:      METHOD_PUT,
+		path:        "/savedsearches/" + savedSearch.Name,
+		payload:     data,
+		contentType: "application/json",
+	}
+	return proj.client.requestWithClose(req)
+}
+
+func (proj *Project) DeleteSavedSearch(name string) error {
+	req := &request{
+		method: METHOD_DELETE,
+		path:   "/savedsearches/" + name,
+	}
+	return proj.client.requestWithClose(req)
+}
+
+type SavedSearchList struct {
+	Count         int      `json:"count,omitempty"`
+	Total         int      `json:"total,omitempty"`
+	SavedSearches []string `json:"savedsearches,omitempty"`
+}
+
+func (proj *Project) ListSavedSearch(offset, size int) (*SavedSearchList, error) {
+	req := &request{
+		method: METHOD_GET,
+		path:   "/savedsearches",
+		params: map[string]string{
+			"size":   strconv.Itoa(size),
+			"offset": strconv.Itoa(offset),
+		},
+	}
+
+	list := &SavedSearchList{}
+	if err := proj.client.requestWithJsonResponse(req, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
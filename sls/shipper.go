@@ -0,0 +1,103 @@
+package sls
+
+import (
+	"encoding/json"
+)
+
+type ShipperTargetType string
+
+const (
+	ShipperTargetOSS = ShipperTargetType("oss")
+)
+
+type OSSShipperStorage struct {
+	Format string            `json:"format"` // "json", "csv" or "parquet"
+	Detail map[string]string `json:"detail,omitempty"`
+}
+
+type OSSShipperConfig struct {
+	OssBucket      string            `json:"ossBucket"`
+	OssPrefix      string            `json:"ossPrefix"`
+	RoleArn        string            `json:"roleArn"`
+	BufferInterval int               `json:"bufferInterval"` // Seconds
+	BufferSize     int               `json:"bufferSize"`     // MB
+	CompressType   string            `json:"compressType"`   // "none" or "snappy"
+	PathFormat     string            `json:"pathFormat"`     // e.g. "%Y/%m/%d/%H/%M"
+	Storage        OSSShipperStorage `json:"storage"`
+}
+
+type Shipper struct {
+	Name                string            `json:"shipperName"`
+	TargetType          ShipperTargetType `json:"targetType"`
+	TargetConfiguration *OSSShipperConfig `json:"targetConfiguration"`
+}
+
+func (proj *Project) CreateShipper(logstore string, shipper *Shipper) error {
+	data, err := json.Marshal(shipper)
+	if err != nil {
+		return err
+	}
+
+	req := &request{
+		method:      METHOD_POST,
+		path:        "/logstores/" + logstore + "/shipper",
+		payload:     data,
+		contentType: "application/json",
+	}
+	return proj.client.requestWithClose(req)
+}
+
+func (proj *Project) GetShipper(logstore, name string) (*Shipper, error) {
+	req := &request{
+		method: METHOD_GET,
+		path:   "/logstores/" + logstore + "/shipper/" + name,
+	}
+
+	shipper := &Shipper{}
+	if err := proj.client.requestWithJsonResponse(req, shipper); err != nil {
+		return nil, err
+	}
+	return shipper, nil
+}
+
+func (proj *Project) UpdateShipper(logstore string, shipper *Shipper) error {
+	data, err := json.Marshal(shipper)
+	if err != nil {
+		return err
+	}
+
+	req := &request{
+		method:      METHOD_PUT,
+		path:        "/logstores/" + logstore + "/shipper/" + shipper.Name,
+		payload:     data,
+		contentType: "application/json",
+	}
+	return proj.client.requestWithClose(req)
+}
+
+func (proj *Project) DeleteShipper(logstore, name string) error {
+	req := &request{
+		method: METHOD_DELETE,
+		path:   "/logstores/" + logstore + "/shipper/" + name,
+	}
+	return proj.client.requestWithClose(req)
+}
+
+type ShipperList struct {
+	Count    int      `json:"count,omitempty"`
+	Total    int      `json:"total,omitempty"`
+	Shippers []string `json:"shipper,omitempty"`
+}
+
+func (proj *Project) ListShipper(logstore string) (*ShipperList, error) {
+	req := &request{
+		method: METHOD_GET,
+		path:   "/logstores/" + logstore + "/shipper",
+	}
+
+	list := &ShipperList{}
+	if err := proj.client.requestWithJsonResponse(req, list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
@@ -0,0 +1,128 @@
+package sls
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// ErrWriterLinesDropped is returned when the lines kept for the producer
+// exceed its buffer size, the oldest ones are then dropped
+var ErrWriterLinesDropped = errors.New("sls: writer dropped lines rejected by the producer")
+
+// Writer is an io.Writer shipping each written line as a log with a single
+// "content" key, so that a log.Logger can write to SLS:
+//
+//	logger := log.New(sls.NewWriter(producer, "project", "logstore", "service", ""), "", log.LstdFlags)
+type Writer struct {
+	producer *Producer
+	project  string
+	logstore string
+	topic    string
+	source   string
+
+	mu      sync.Mutex
+	buf     []byte
+	pending []*Log // Lines the producer rejected with ErrProducerBufferFull
+}
+
+// NewWriter creates a Writer sending the lines through the producer. The
+// host name is used as source when source is empty.
+func NewWriter(producer *Producer, project, logstore, topic, source string) *Writer {
+	if source == "" {
+		source = hostSource()
+	}
+	return &Writer{
+		producer: producer,
+		project:  project,
+		logstore: logstore,
+		topic:    topic,
+		source:   source,
+	}
+}
+
+// Write sends every complete line of p and keeps a trailing partial line
+// until the next Write or Flush. p is always consumed: when the producer
+// buffer is full, the error is returned and the lines are sent again with the
+// next Write or Flush, up to the size of the producer buffer. The lines are
+// dropped on the other errors.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.pending = append(w.pending, newContentLog(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+	}
+	if err := w.sendPending(); err != nil {
+		return len(p), err
+	}
+	return len(p), nil
+}
+
+// Flush sends the buffered partial line, if any, and the lines rejected by
+// previous sends
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) > 0 {
+		w.pending = append(w.pending, newContentLog(w.buf))
+		w.buf = nil
+	}
+	return w.sendPending()
+}
+
+func (w *Writer) sendPending() error {
+	if len(w.pending) == 0 {
+		return nil
+	}
+
+	// Keep the newest lines the producer can accept at once
+	dropped := false
+	size := int64(0)
+	for i := len(w.pending) - 1; i >= 0; i-- {
+		size += int64(logSize(w.pending[i]))
+		if size > w.producer.config.MaxBufferSize {
+			w.pending = append([]*Log(nil), w.pending[i+1:]...)
+			dropped = true
+			break
+		}
+	}
+
+	err := w.producer.Send(w.project, w.logstore, w.topic, w.source, w.pending...)
+	if err != ErrProducerBufferFull {
+		w.pending = nil
+	}
+	if dropped && (err == nil || err == ErrProducerBufferFull) {
+		return ErrWriterLinesDropped
+	}
+	return err
+}
+
+func newContentLog(line []byte) *Log {
+	line = bytes.TrimSuffix(line, []byte{'\r'})
+	return &Log{
+		Time: proto.Uint32(uint32(time.Now().Unix())),
+		Contents: []*Log_Content{
+			{Key: proto.String("content"), Value: proto.String(string(line))},
+		},
+	}
+}
+
+func hostSource() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return ""
+	}
+	return hostname
+}
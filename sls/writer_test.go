@@ -0,0 +1,114 @@
+package sls
+
+import (
+	"log"
+	"testing"
+	"time"
+)
+
+func TestWriter(t *testing.T) {
+	recorder := &putLogsRecorder{t: t}
+	client := NewClientWithEndpoint("log.example.com", Region, false, "id", "secret")
+	client.SetTransport(recorder)
+	producer := NewProducer(client, &ProducerConfig{LingerTime: time.Hour})
+
+	writer := NewWriter(producer, "project", "logstore", "service", "host")
+	logger := log.New(writer, "", 0)
+	logger.Println("first line")
+	logger.Print("second line\nthird line")
+	writer.Write([]byte("partial"))
+	writer.Flush()
+	producer.Close()
+
+	var lines []string
+	for _, req := range recorder.requests {
+		for _, l := range req.logGroup.Logs {
+			lines = append(lines, l.Contents[0].GetValue())
+		}
+	}
+	expected := []string{"first line", "second line", "third line", "partial"}
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, lines)
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("Expected %q, got %q", expected[i], lines[i])
+		}
+	}
+}
+
+func TestWriterKeepsRejectedLines(t *testing.T) {
+	recorder := &putLogsRecorder{t: t}
+	client := NewClientWithEndpoint("log.example.com", Region, false, "id", "secret")
+	client.SetTransport(recorder)
+	producer := NewProducer(client, &ProducerConfig{
+		LingerTime:     20 * time.Millisecond,
+		MaxBufferSize:  int64(logSize(newContentLog([]byte("line 1")))),
+		OverflowPolicy: OverflowDrop,
+	})
+
+	writer := NewWriter(producer, "project", "logstore", "service", "host")
+	if _, err := writer.Write([]byte("line 1\n")); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	if _, err := writer.Write([]byte("line 2\n")); err != ErrProducerBufferFull {
+		t.Fatalf("Expected ErrProducerBufferFull, got %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for writer.Flush() != nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("Rejected line was not sent")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	producer.Close()
+
+	var lines []string
+	for _, req := range recorder.requests {
+		for _, l := range req.logGroup.Logs {
+			lines = append(lines, l.Contents[0].GetValue())
+		}
+	}
+	if len(lines) != 2 || lines[0] != "line 1" || lines[1] != "line 2" {
+		t.Errorf("Unexpected lines %v", lines)
+	}
+}
+
+func TestWriterDropsLines(t *testing.T) {
+	client := NewClientWithEndpoint("log.example.com", Region, false, "id", "secret")
+	client.SetTransport(&putLogsRecorder{t: t})
+	line := newContentLog([]byte("line 1"))
+	producer := NewProducer(client, &ProducerConfig{
+		LingerTime:     time.Hour,
+		MaxBufferSize:  int64(logSize(line) * 2),
+		OverflowPolicy: OverflowDrop,
+	})
+
+	writer := NewWriter(producer, "project", "logstore", "service", "host")
+	if _, err := writer.Write([]byte("line 1\nline 2\n")); err != nil {
+		t.Fatalf("Failed to write: %v", err)
+	}
+	if _, err := writer.Write([]byte("line 3\n")); err != ErrProducerBufferFull {
+		t.Fatalf("Expected ErrProducerBufferFull, got %v", err)
+	}
+	// The pending lines are capped to the producer buffer size
+	if _, err := writer.Write([]byte("line 4\nline 5\n")); err != ErrWriterLinesDropped {
+		t.Fatalf("Expected ErrWriterLinesDropped, got %v", err)
+	}
+	if len(writer.pending) != 2 || writer.pending[0].Contents[0].GetValue() != "line 4" {
+		t.Errorf("Unexpected pending lines %v", writer.pending)
+	}
+
+	// The lines are dropped on the other errors
+	producer.Close()
+	if _, err := writer.Write([]byte("line 6\n")); err != ErrProducerClosed {
+		t.Fatalf("Expected ErrProducerClosed, got %v", err)
+	}
+	if len(writer.pending) != 0 {
+		t.Errorf("Unexpected pending lines %v", writer.pending)
+	}
+	if err := writer.Flush(); err != nil {
+		t.Errorf("Unexpected flush error %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+package util
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// Codec encodes the values carried by message payloads. ID identifies the
+// codec in the messages so that consumers can decode them with GetCodec.
+type Codec interface {
+	ID() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+var (
+	JSONCodec     Codec = jsonCodec{}
+	ProtobufCodec Codec = protobufCodec{}
+)
+
+const gzipCodecPrefix = "gzip+"
+
+type jsonCodec struct{}
+
+func (jsonCodec) ID() string {
+	return "json"
+}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// protobufCodec encodes proto.Message values, Unmarshal also accepts a
+// pointer to a nil message pointer and allocates the message
+type protobufCodec struct{}
+
+func (protobufCodec) ID() string {
+	return "protobuf"
+}
+
+func (protobufCodec) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("protobuf codec: %T is not a proto.Message", v)
+	}
+	return proto.Marshal(m)
+}
+
+func (protobufCodec) Unmarshal(data []byte, v interface{}) error {
+	if m, ok := v.(proto.Message); ok {
+		return proto.Unmarshal(data, m)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() && rv.Elem().Kind() == reflect.Ptr {
+		elem := reflect.New(rv.Elem().Type().Elem())
+		if m, ok := elem.Interface().(proto.Message); ok {
+			if err := proto.Unmarshal(data, m); err != nil {
+				return err
+			}
+			rv.Elem().Set(elem)
+			return nil
+		}
+	}
+	return fmt.Errorf("protobuf codec: %T is not a proto.Message", v)
+}
+
+type gzipCodec struct {
+	codec Codec
+}
+
+// GzipCodec compresses the payloads of codec, its ID is "gzip+" followed by the ID of codec
+func GzipCodec(codec Codec) Codec {
+	return gzipCodec{codec: codec}
+}
+
+func (c gzipCodec) ID() string {
+	return gzipCodecPrefix + c.codec.ID()
+}
+
+func (c gzipCodec) Marshal(v interface{}) ([]byte, error) {
+	data, err := c.codec.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c gzipCodec) Unmarshal(data []byte, v interface{}) error {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	data, err = ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return c.codec.Unmarshal(data, v)
+}
+
+var codecs = struct {
+	sync.RWMutex
+	m map[string]Codec
+}{m: map[string]Codec{
+	JSONCodec.ID():     JSONCodec,
+	ProtobufCodec.ID(): ProtobufCodec,
+}}
+
+// RegisterCodec makes codec available to GetCodec, the JSON and protobuf codecs are registered by default
+func RegisterCodec(codec Codec) {
+	codecs.Lock()
+	codecs.m[codec.ID()] = codec
+	codecs.Unlock()
+}
+
+// GetCodec returns the registered codec identified by id. The "gzip+" IDs
+// resolve to the registered codec wrapped with GzipCodec.
+func GetCodec(id string) (Codec, bool) {
+	codecs.RLock()
+	codec, ok := codecs.m[id]
+	codecs.RUnlock()
+	if ok {
+		return codec, true
+	}
+	if strings.HasPrefix(id, gzipCodecPrefix) {
+		if codec, ok := GetCodec(strings.TrimPrefix(id, gzipCodecPrefix)); ok {
+			return GzipCodec(codec), true
+		}
+	}
+	return nil, false
+}
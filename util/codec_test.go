@@ -0,0 +1,44 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/wrappers"
+)
+
+func TestCodecs(t *testing.T) {
+	type order struct {
+		Id    string
+		Count int
+	}
+
+	codec, ok := GetCodec("gzip+json")
+	if !ok || codec.ID() != "gzip+json" {
+		t.Fatalf("Expected the gzip JSON codec, got %v", codec)
+	}
+	data, err := codec.Marshal(order{Id: "order-1", Count: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var o order
+	if err := codec.Unmarshal(data, &o); err != nil || o.Id != "order-1" || o.Count != 2 {
+		t.Errorf("Unexpected decoded value: %+v %v", o, err)
+	}
+
+	data, err = ProtobufCodec.Marshal(&wrappers.StringValue{Value: "hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m *wrappers.StringValue
+	if err := ProtobufCodec.Unmarshal(data, &m); err != nil || !proto.Equal(m, &wrappers.StringValue{Value: "hello"}) {
+		t.Errorf("Unexpected decoded message: %v %v", m, err)
+	}
+	if _, err := ProtobufCodec.Marshal(o); err == nil {
+		t.Errorf("Expected an error for a non protobuf value")
+	}
+
+	if _, ok := GetCodec("gzip+xml"); ok {
+		t.Errorf("Expected no codec for an unregistered ID")
+	}
+}